@@ -1,6 +1,12 @@
 package app
 
 import (
+	attachmentController "github.com/merdernoty/job-hunter/internal/attachments/controller"
+	broadcastController "github.com/merdernoty/job-hunter/internal/broadcasts/controller"
+	channelController "github.com/merdernoty/job-hunter/internal/channels/controller"
+	deepLinkController "github.com/merdernoty/job-hunter/internal/deeplinks/controller"
+	paymentController "github.com/merdernoty/job-hunter/internal/payments/controller"
+	uploadController "github.com/merdernoty/job-hunter/internal/uploads/controller"
 	"github.com/merdernoty/job-hunter/internal/users/controller"
 	"go.uber.org/fx"
 )
@@ -8,5 +14,11 @@ import (
 var Module = fx.Options(
 	fx.Provide(NewServer),
 	fx.Provide(controller.NewUserController),
+	fx.Provide(uploadController.NewUploadController),
+	fx.Provide(attachmentController.NewAttachmentController),
+	fx.Provide(broadcastController.NewBroadcastController),
+	fx.Provide(deepLinkController.NewDeepLinkController),
+	fx.Provide(channelController.NewChannelController),
+	fx.Provide(paymentController.NewPaymentController),
 	fx.Invoke(RegisterRoutes),
 )
\ No newline at end of file
@@ -0,0 +1,291 @@
+package app
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	attachmentDomain "github.com/merdernoty/job-hunter/internal/attachments/domain"
+	broadcastDomain "github.com/merdernoty/job-hunter/internal/broadcasts/domain"
+	channelDomain "github.com/merdernoty/job-hunter/internal/channels/domain"
+	deepLinkDomain "github.com/merdernoty/job-hunter/internal/deeplinks/domain"
+	paymentController "github.com/merdernoty/job-hunter/internal/payments/controller"
+	paymentDomain "github.com/merdernoty/job-hunter/internal/payments/domain"
+	uploadDomain "github.com/merdernoty/job-hunter/internal/uploads/domain"
+	userDomain "github.com/merdernoty/job-hunter/internal/users/domain"
+	"github.com/merdernoty/job-hunter/pkg/deeplink"
+	"github.com/merdernoty/job-hunter/pkg/openapi"
+)
+
+const (
+	openAPIPath = "/api/openapi.json"
+	docsPath    = "/api/docs"
+)
+
+// apiSpec documents every route registered by RegisterRoutes. TestAPISpec
+// fails when a route is added without being described here.
+func apiSpec(version string) *openapi.Document {
+	doc := openapi.New("Job Hunter API", version)
+	doc.Info.Description = "REST API of the Job Hunter Telegram mini app. Every response uses the Response envelope; " +
+		"errors carry a stable code in error.code. Routes under /api/v1 are rate limited per user, or per IP address " +
+		"without a token, and report their limit in the X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset " +
+		"headers; over the limit they answer 429 RATE_LIMITED with Retry-After."
+	doc.Tags = []openapi.Tag{
+		{Name: "system"},
+		{Name: "auth"},
+		{Name: "users"},
+		{Name: "attachments"},
+		{Name: "uploads"},
+		{Name: "links"},
+		{Name: "channels"},
+		{Name: "payments"},
+		{Name: "admin", Description: "Requires a Telegram ID listed in admin.telegramids"},
+	}
+
+	auth := openapi.BearerAuth
+
+	// System
+	doc.Add(http.MethodGet, "/api/health", openapi.Operation{
+		Tags:    []string{"system"},
+		Summary: "Health check",
+		Responses: doc.OK(struct {
+			Status  string `json:"status"`
+			Service string `json:"service"`
+			Version string `json:"version"`
+		}{}),
+	})
+	doc.Add(http.MethodGet, openAPIPath, openapi.Operation{
+		Tags:      []string{"system"},
+		Summary:   "This document",
+		Responses: map[string]*openapi.Response{"200": {Description: "OpenAPI document"}},
+	})
+	doc.Add(http.MethodGet, docsPath, openapi.Operation{
+		Tags:      []string{"system"},
+		Summary:   "Interactive API docs",
+		Responses: map[string]*openapi.Response{"200": {Description: "HTML page"}},
+	})
+
+	// Auth
+	doc.Add(http.MethodPost, "/api/v1/auth/telegram", openapi.Operation{
+		Tags:        []string{"auth"},
+		Summary:     "Log in with Telegram mini app initData",
+		RequestBody: doc.JSON(userDomain.TelegramAuthRequest{}),
+		Responses: doc.OK(struct {
+			User  userDomain.User `json:"user"`
+			Token string          `json:"token"`
+			// Navigate is where a deep link asks the app to open.
+			Navigate *deeplink.Target `json:"navigate,omitempty"`
+		}{}),
+	})
+
+	// Users
+	doc.Add(http.MethodGet, "/api/v1/users", openapi.Operation{
+		Tags: []string{"users"}, Summary: "List users", Security: auth,
+		Description: "Pages through users with an opaque cursor. Pass the next_cursor of a response " +
+			"as cursor, with the same sort, to get the following page; it is omitted on the last page.",
+		Parameters: []openapi.Parameter{
+			openapi.Query("limit", "integer", "At most 100, 20 by default"),
+			openapi.Query("cursor", "string", "next_cursor of the previous page"),
+			openapi.Query("sort", "string", "created_at, last_seen_at or username, prefixed with - for descending order; -created_at by default"),
+			{Name: "created_after", In: "query", Description: "Only users who signed up after this time", Schema: &openapi.Schema{Type: "string", Format: "date-time"}},
+			openapi.Query("has_avatar", "boolean", "Only users with or without an avatar"),
+			openapi.Query("handle_prefix", "string", "Only users whose Telegram handle starts with this, case insensitively"),
+		},
+		Responses: doc.OK([]userDomain.UserSummary{}),
+	})
+	doc.Add(http.MethodGet, "/api/v1/users/:id", openapi.Operation{
+		Tags: []string{"users"}, Summary: "Get a user and record the profile view", Security: auth,
+		Responses: doc.OK(userDomain.User{}),
+	})
+	doc.Add(http.MethodPut, "/api/v1/users/:id", openapi.Operation{
+		Tags: []string{"users"}, Summary: "Update a user", Security: auth,
+		RequestBody: doc.JSON(userDomain.UpdateUserRequest{}),
+		Responses:   doc.OK(userDomain.User{}),
+	})
+	doc.Add(http.MethodPost, "/api/v1/users/:id/swipe", openapi.Operation{
+		Tags: []string{"users"}, Summary: "Like or skip a profile", Security: auth,
+		RequestBody: doc.JSON(userDomain.SwipeRequest{}),
+		Responses:   doc.OK(userDomain.SwipeResult{}),
+	})
+	doc.Add(http.MethodGet, "/api/v1/users/me", openapi.Operation{
+		Tags: []string{"users"}, Summary: "Get the current user", Security: auth,
+		Responses: doc.OK(userDomain.User{}),
+	})
+	doc.Add(http.MethodPut, "/api/v1/users/me", openapi.Operation{
+		Tags: []string{"users"}, Summary: "Update the current user", Security: auth,
+		RequestBody: doc.JSON(userDomain.UpdateUserRequest{}),
+		Responses:   doc.OK(userDomain.User{}),
+	})
+	doc.Add(http.MethodPatch, "/api/v1/users/me", openapi.Operation{
+		Tags: []string{"users"}, Summary: "Patch the current user", Security: auth,
+		Description: "Applies a JSON Merge Patch: members left out stay unchanged and null clears " +
+			"bio, role, seniority, skills, language or avatar_url. Unknown members are rejected.",
+		RequestBody: doc.MergePatch(userDomain.ProfilePatch{}),
+		Responses:   doc.OK(userDomain.User{}),
+	})
+	doc.Add(http.MethodPut, "/api/v1/users/me/avatar", openapi.Operation{
+		Tags: []string{"users"}, Summary: "Upload an avatar", Security: auth,
+		RequestBody: openapi.Multipart("avatar"),
+		Responses: doc.OK(struct {
+			AvatarURL string `json:"avatar_url"`
+		}{}),
+	})
+	doc.Add(http.MethodDelete, "/api/v1/users/me/avatar", openapi.Operation{
+		Tags: []string{"users"}, Summary: "Delete the avatar", Security: auth,
+		Responses: doc.OK(nil),
+	})
+	doc.Add(http.MethodGet, "/api/v1/users/me/viewers", openapi.Operation{
+		Tags: []string{"users"}, Summary: "List who viewed the current user's profile", Security: auth,
+		Description: "Requires the profile viewers premium feature.",
+		Parameters:  []openapi.Parameter{openapi.Query("limit", "integer", "At most 100, 50 by default")},
+		Responses:   doc.OK([]userDomain.ProfileViewer{}),
+	})
+	doc.Add(http.MethodGet, "/api/v1/users/random", openapi.Operation{
+		Tags: []string{"users"}, Summary: "Get a random profile not shown today", Security: auth,
+		Description: "data is null, with an explanation in message, when there is nobody left to show today.",
+		Responses:   doc.OK(userDomain.User{}),
+	})
+
+	// Attachments
+	doc.Add(http.MethodGet, "/api/v1/users/me/attachments", openapi.Operation{
+		Tags: []string{"attachments"}, Summary: "List the current user's attachments and storage usage", Security: auth,
+		Responses: doc.OK(struct {
+			Attachments []attachmentDomain.Attachment `json:"attachments"`
+			Usage       attachmentDomain.StorageUsage `json:"usage"`
+		}{}),
+	})
+	doc.Add(http.MethodGet, "/api/v1/users/:id/attachments", openapi.Operation{
+		Tags: []string{"attachments"}, Summary: "List a user's attachments", Security: auth,
+		Description: "Other users only see portfolio attachments; CVs and certificates are listed to their owner alone.",
+		Responses:   doc.OK([]attachmentDomain.Attachment{}),
+	})
+	doc.Add(http.MethodGet, "/api/v1/attachments/:id/download", openapi.Operation{
+		Tags: []string{"attachments"}, Summary: "Download an attachment", Security: auth,
+		Description: "Attachments the caller may not see are reported as not found.",
+		Responses:   openapi.Redirect("Redirect to a short-lived download URL"),
+	})
+	doc.Add(http.MethodDelete, "/api/v1/attachments/:id", openapi.Operation{
+		Tags: []string{"attachments"}, Summary: "Delete an own attachment", Security: auth,
+		Responses: doc.OK(nil),
+	})
+
+	// Uploads
+	doc.Add(http.MethodPost, "/api/v1/uploads", openapi.Operation{
+		Tags: []string{"uploads"}, Summary: "Get a presigned URL to upload a file to", Security: auth,
+		RequestBody: doc.JSON(uploadDomain.CreateUploadRequest{}),
+		Responses:   doc.Created(uploadDomain.PresignedUpload{}),
+	})
+	doc.Add(http.MethodPost, "/api/v1/uploads/:id/complete", openapi.Operation{
+		Tags: []string{"uploads"}, Summary: "Confirm an upload and attach the file", Security: auth,
+		Responses: doc.OK(uploadDomain.CompletedUpload{}),
+	})
+
+	// Deep links
+	doc.Add(http.MethodPost, "/api/v1/links", openapi.Operation{
+		Tags: []string{"links"}, Summary: "Create a signed deep link", Security: auth,
+		RequestBody: doc.JSON(deepLinkDomain.CreateLinkRequest{}),
+		Responses:   doc.Created(deepLinkDomain.Links{}),
+	})
+
+	// Channels
+	doc.Add(http.MethodGet, "/api/v1/channels", openapi.Operation{
+		Tags: []string{"channels"}, Summary: "List linked channels", Security: auth,
+		Responses: doc.OK([]channelDomain.Channel{}),
+	})
+	doc.Add(http.MethodPost, "/api/v1/channels", openapi.Operation{
+		Tags: []string{"channels"}, Summary: "Link a channel or group the bot administers", Security: auth,
+		RequestBody: doc.JSON(channelDomain.LinkChannelRequest{}),
+		Responses:   doc.Created(channelDomain.Channel{}),
+	})
+	doc.Add(http.MethodDelete, "/api/v1/channels/:id", openapi.Operation{
+		Tags: []string{"channels"}, Summary: "Unlink a channel", Security: auth,
+		Responses: doc.OK(nil),
+	})
+
+	// Payments
+	doc.Add(http.MethodGet, "/api/v1/payments", openapi.Operation{
+		Tags: []string{"payments"}, Summary: "List the current user's payments", Security: auth,
+		Responses: doc.OK([]paymentDomain.Payment{}),
+	})
+	doc.Add(http.MethodGet, "/api/v1/payments/products", openapi.Operation{
+		Tags: []string{"payments"}, Summary: "List premium products", Security: auth,
+		Responses: doc.OK([]paymentController.ProductResponse{}),
+	})
+	doc.Add(http.MethodPost, "/api/v1/payments/invoice", openapi.Operation{
+		Tags: []string{"payments"}, Summary: "Create a Telegram Stars invoice link", Security: auth,
+		Description: "Open the link with Telegram.WebApp.openInvoice.",
+		RequestBody: doc.JSON(paymentDomain.CreateInvoiceRequest{}),
+		Responses: doc.Created(struct {
+			InvoiceLink string `json:"invoice_link"`
+		}{}),
+	})
+	doc.Add(http.MethodGet, "/api/v1/payments/entitlements", openapi.Operation{
+		Tags: []string{"payments"}, Summary: "List active premium features", Security: auth,
+		Responses: doc.OK([]paymentDomain.Entitlement{}),
+	})
+
+	// Admin
+	doc.Add(http.MethodPost, "/api/v1/admin/payments/:id/refund", openapi.Operation{
+		Tags: []string{"admin"}, Summary: "Refund a payment and revoke its feature", Security: auth,
+		Responses: doc.OK(paymentDomain.Payment{}),
+	})
+	doc.Add(http.MethodPost, "/api/v1/admin/broadcasts", openapi.Operation{
+		Tags: []string{"admin"}, Summary: "Create a broadcast draft", Security: auth,
+		RequestBody: doc.JSON(broadcastDomain.CreateBroadcastRequest{}),
+		Responses:   doc.Created(broadcastDomain.Broadcast{}),
+	})
+	doc.Add(http.MethodGet, "/api/v1/admin/broadcasts", openapi.Operation{
+		Tags: []string{"admin"}, Summary: "List broadcasts", Security: auth,
+		Responses: doc.OK([]broadcastDomain.Broadcast{}),
+	})
+	doc.Add(http.MethodGet, "/api/v1/admin/broadcasts/:id", openapi.Operation{
+		Tags: []string{"admin"}, Summary: "Get a broadcast with delivery stats", Security: auth,
+		Responses: doc.OK(broadcastDomain.BroadcastReport{}),
+	})
+	doc.Add(http.MethodGet, "/api/v1/admin/broadcasts/:id/recipients", openapi.Operation{
+		Tags: []string{"admin"}, Summary: "List a broadcast's recipients", Security: auth,
+		Parameters: []openapi.Parameter{
+			openapi.Query("limit", "integer", ""),
+			openapi.Query("offset", "integer", ""),
+		},
+		Responses: doc.OK([]broadcastDomain.Recipient{}),
+	})
+	doc.Add(http.MethodPost, "/api/v1/admin/broadcasts/:id/confirm", openapi.Operation{
+		Tags: []string{"admin"}, Summary: "Start sending a draft", Security: auth,
+		Responses: doc.OK(broadcastDomain.Broadcast{}),
+	})
+	doc.Add(http.MethodPost, "/api/v1/admin/broadcasts/:id/cancel", openapi.Operation{
+		Tags: []string{"admin"}, Summary: "Cancel a draft", Security: auth,
+		Responses: doc.OK(nil),
+	})
+
+	return doc
+}
+
+func openAPIHandler(doc *openapi.Document) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK, doc)
+	}
+}
+
+// docsPage renders the document with Swagger UI.
+const docsPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Job Hunter API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = () => {
+      window.ui = SwaggerUIBundle({ url: "` + openAPIPath + `", dom_id: "#swagger-ui" });
+    };
+  </script>
+</body>
+</html>`
+
+func docsHandler(c echo.Context) error {
+	return c.HTML(http.StatusOK, docsPage)
+}
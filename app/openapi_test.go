@@ -0,0 +1,70 @@
+package app
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/merdernoty/job-hunter/config"
+	attachmentController "github.com/merdernoty/job-hunter/internal/attachments/controller"
+	broadcastController "github.com/merdernoty/job-hunter/internal/broadcasts/controller"
+	channelController "github.com/merdernoty/job-hunter/internal/channels/controller"
+	deepLinkController "github.com/merdernoty/job-hunter/internal/deeplinks/controller"
+	paymentController "github.com/merdernoty/job-hunter/internal/payments/controller"
+	uploadController "github.com/merdernoty/job-hunter/internal/uploads/controller"
+	"github.com/merdernoty/job-hunter/internal/users/controller"
+	"github.com/merdernoty/job-hunter/pkg/openapi"
+	"github.com/merdernoty/job-hunter/pkg/ratelimit"
+)
+
+// documentedMethods are the methods routes are registered with. Echo adds
+// catch-all routes of its own for groups with middleware.
+var documentedMethods = map[string]bool{
+	http.MethodGet:    true,
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+func TestAPISpec(t *testing.T) {
+	s := &Server{engine: echo.New(), config: &config.Config{}}
+
+	// Routes only keep references to the handlers, so controllers without
+	// dependencies are enough to register them.
+	RegisterRoutes(s,
+		&controller.UserController{},
+		&uploadController.UploadController{},
+		&attachmentController.AttachmentController{},
+		&broadcastController.BroadcastController{},
+		&deepLinkController.DeepLinkController{},
+		&channelController.ChannelController{},
+		&paymentController.PaymentController{},
+		nil,
+		nil,
+		ratelimit.NewLimiter(s.config, ratelimit.NewMemoryStore(), nil),
+	)
+
+	doc := apiSpec("test")
+	registered := make(map[string]bool)
+
+	for _, route := range s.Echo().Routes() {
+		if !documentedMethods[route.Method] {
+			continue
+		}
+		registered[route.Method+" "+openapi.PathKey(route.Path)] = true
+
+		if !doc.Has(route.Method, route.Path) {
+			t.Errorf("%s %s is registered but missing from the OpenAPI document", route.Method, route.Path)
+		}
+	}
+
+	for path, item := range doc.Paths {
+		for method := range *item {
+			if !registered[strings.ToUpper(method)+" "+path] {
+				t.Errorf("%s %s is documented but not registered", strings.ToUpper(method), path)
+			}
+		}
+	}
+}
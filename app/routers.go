@@ -1,23 +1,64 @@
 package app
 
 import (
+	"net/http"
+
 	"github.com/labstack/echo/v4"
+	"github.com/merdernoty/job-hunter/config"
+	attachmentController "github.com/merdernoty/job-hunter/internal/attachments/controller"
+	broadcastController "github.com/merdernoty/job-hunter/internal/broadcasts/controller"
+	channelController "github.com/merdernoty/job-hunter/internal/channels/controller"
+	deepLinkController "github.com/merdernoty/job-hunter/internal/deeplinks/controller"
+	paymentController "github.com/merdernoty/job-hunter/internal/payments/controller"
+	uploadController "github.com/merdernoty/job-hunter/internal/uploads/controller"
 	"github.com/merdernoty/job-hunter/internal/users/controller"
+	"github.com/merdernoty/job-hunter/internal/users/domain"
 	"github.com/merdernoty/job-hunter/internal/users/middleware"
 	httpResponse "github.com/merdernoty/job-hunter/pkg/http"
 	"github.com/merdernoty/job-hunter/pkg/jwt"
+	"github.com/merdernoty/job-hunter/pkg/ratelimit"
 )
 
 func RegisterRoutes(
 	s *Server,
 	userCtrl *controller.UserController,
+	uploadCtrl *uploadController.UploadController,
+	attachmentCtrl *attachmentController.AttachmentController,
+	broadcastCtrl *broadcastController.BroadcastController,
+	deepLinkCtrl *deepLinkController.DeepLinkController,
+	channelCtrl *channelController.ChannelController,
+	paymentCtrl *paymentController.PaymentController,
+	userService domain.UserService,
 	jwtService *jwt.JWTService,
+	limiter *ratelimit.Limiter,
 ) {
 	s.Echo().GET("/api/health", healthCheck(s))
+	s.Echo().GET(openAPIPath, openAPIHandler(apiSpec(s.config.Server.AppVersion)))
+	s.Echo().GET(docsPath, docsHandler)
 	// API v1
-	api := s.Echo().Group("/api/v1")
+	api := s.Echo().Group("/api/v1", limiter.Middleware(middleware.RateLimitKey(jwtService)))
+	limitRoutes(limiter, s.config.RateLimit)
 	jwtMiddleware := middleware.JWTAuth(jwtService)
 	userCtrl.RegisterRoutes(api, jwtMiddleware)
+	uploadCtrl.RegisterRoutes(api, jwtMiddleware)
+	attachmentCtrl.RegisterRoutes(api, jwtMiddleware)
+	deepLinkCtrl.RegisterRoutes(api, jwtMiddleware)
+	channelCtrl.RegisterRoutes(api, jwtMiddleware)
+
+	adminMiddleware := middleware.AdminOnly(userService, s.config.Admin.TelegramIDs)
+	broadcastCtrl.RegisterRoutes(api, jwtMiddleware, adminMiddleware)
+	paymentCtrl.RegisterRoutes(api, jwtMiddleware, adminMiddleware)
+}
+
+// limitRoutes applies the stricter policies: logging in creates users, and
+// uploads are expensive for storage.
+func limitRoutes(limiter *ratelimit.Limiter, cfg config.RateLimitConfig) {
+	limiter.Route(http.MethodPost, "/api/v1/auth/telegram", ratelimit.NewPolicy("auth", cfg.Auth))
+
+	uploads := ratelimit.NewPolicy("uploads", cfg.Uploads)
+	limiter.Route(http.MethodPost, "/api/v1/uploads", uploads)
+	limiter.Route(http.MethodPost, "/api/v1/uploads/:id/complete", uploads)
+	limiter.Route(http.MethodPut, "/api/v1/users/me/avatar", uploads)
 }
 
 func healthCheck(s *Server) echo.HandlerFunc {
@@ -7,7 +7,9 @@ import (
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/merdernoty/job-hunter/config"
 	httpPkg "github.com/merdernoty/job-hunter/pkg/http"
+	"github.com/merdernoty/job-hunter/pkg/i18n"
 	"github.com/merdernoty/job-hunter/pkg/logger"
+	"github.com/merdernoty/job-hunter/pkg/ratelimit"
 	"go.uber.org/fx"
 )
 
@@ -17,38 +19,47 @@ type Server struct {
 	logger logger.Logger
 }
 
-func NewServer(lc fx.Lifecycle, cfg *config.Config, log logger.Logger, validator *httpPkg.CustomValidator) *Server {
+func NewServer(lc fx.Lifecycle, cfg *config.Config, log logger.Logger, validator *httpPkg.CustomValidator, translator *i18n.Translator) (*Server, error) {
 	engine := echo.New()
 
 	engine.HideBanner = true
 	engine.HidePort = true
 
+	ipExtractor, err := httpPkg.IPExtractor(cfg.Server.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	engine.IPExtractor = ipExtractor
+
+	engine.Use(httpPkg.RequestID())
 	engine.Use(middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
 		LogURI:     true,
 		LogStatus:  true,
 		LogMethod:  true,
 		LogLatency: true,
 		LogValuesFunc: func(c echo.Context, values middleware.RequestLoggerValues) error {
-			log.Infof("REQUEST: %s %s - Status: %d - Latency: %v",
+			log.FromContext(c.Request().Context()).Infof("REQUEST: %s %s - Status: %d - Latency: %v",
 				values.Method, values.URI, values.Status, values.Latency)
 			return nil
 		},
 	}))
 
 	engine.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: []string{"*"},
-		AllowMethods: []string{echo.GET, echo.POST, echo.PUT, echo.PATCH, echo.DELETE, echo.HEAD, echo.OPTIONS},
-		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
+		AllowOrigins:  []string{"*"},
+		AllowMethods:  []string{echo.GET, echo.POST, echo.PUT, echo.PATCH, echo.DELETE, echo.HEAD, echo.OPTIONS},
+		AllowHeaders:  []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, echo.HeaderXRequestID},
+		ExposeHeaders: []string{echo.HeaderXRequestID, echo.HeaderRetryAfter, ratelimit.HeaderLimit, ratelimit.HeaderRemaining, ratelimit.HeaderReset},
 	}))
 
-	engine.Use(middleware.RateLimiter(middleware.NewRateLimiterMemoryStore(20)))
-
 	engine.Use(middleware.Secure())
 	engine.Use(middleware.RemoveTrailingSlash())
 
 	engine.Use(middleware.Recover())
 
+	engine.Use(translator.Middleware())
+
 	engine.Validator = validator
+	engine.HTTPErrorHandler = httpPkg.ErrorHandler(log)
 
 	s := &Server{
 		engine: engine,
@@ -80,7 +91,7 @@ func NewServer(lc fx.Lifecycle, cfg *config.Config, log logger.Logger, validator
 		},
 	})
 
-	return s
+	return s, nil
 }
 
 func (s *Server) Echo() *echo.Echo {
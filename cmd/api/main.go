@@ -3,13 +3,24 @@ package main
 import (
 	"github.com/merdernoty/job-hunter/app"
 	"github.com/merdernoty/job-hunter/config"
+	"github.com/merdernoty/job-hunter/internal/attachments"
 	"github.com/merdernoty/job-hunter/internal/bot"
+	"github.com/merdernoty/job-hunter/internal/broadcasts"
+	"github.com/merdernoty/job-hunter/internal/channels"
+	"github.com/merdernoty/job-hunter/internal/deeplinks"
+	"github.com/merdernoty/job-hunter/internal/notifications"
+	"github.com/merdernoty/job-hunter/internal/onboarding"
+	"github.com/merdernoty/job-hunter/internal/payments"
+	"github.com/merdernoty/job-hunter/internal/uploads"
 	user "github.com/merdernoty/job-hunter/internal/users"
 	"github.com/merdernoty/job-hunter/pkg/db/postgres"
+	"github.com/merdernoty/job-hunter/pkg/deeplink"
 	"github.com/merdernoty/job-hunter/pkg/env"
 	httpPkg "github.com/merdernoty/job-hunter/pkg/http"
+	"github.com/merdernoty/job-hunter/pkg/i18n"
 	"github.com/merdernoty/job-hunter/pkg/jwt"
 	"github.com/merdernoty/job-hunter/pkg/logger"
+	"github.com/merdernoty/job-hunter/pkg/ratelimit"
 	"github.com/merdernoty/job-hunter/pkg/storage"
 	"github.com/merdernoty/job-hunter/pkg/telegram"
 	"go.uber.org/fx"
@@ -25,11 +36,22 @@ func main() {
 		postgres.Module,
 		app.Module,
 		httpPkg.Module,
+		i18n.Module,
+		deeplink.Module,
 		jwt.Module,
 		logger.Module,
 		bot.Module,
 		storage.Module,
 		telegram.Module,
 		user.Module,
+		uploads.Module,
+		attachments.Module,
+		onboarding.Module,
+		notifications.Module,
+		broadcasts.Module,
+		deeplinks.Module,
+		channels.Module,
+		payments.Module,
+		ratelimit.Module,
 	).Run()
 }
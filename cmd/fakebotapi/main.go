@@ -0,0 +1,34 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/merdernoty/job-hunter/pkg/telegram/fakeapi"
+)
+
+// fakebotapi serves a fake Telegram Bot API for local development. Point the
+// bot at it with BOT_APIENDPOINT=http://localhost:8081/bot%s/%s and drive it
+// through the /_fake/ control endpoints.
+func main() {
+	addr := flag.String("addr", ":8081", "address to listen on")
+	token := flag.String("token", os.Getenv("BOT_TOKEN"), "bot token the server accepts")
+	flag.Parse()
+
+	if *token == "" {
+		log.Fatal("a bot token is required: pass -token or set BOT_TOKEN")
+	}
+
+	server := fakeapi.NewServer(*token)
+
+	mux := http.NewServeMux()
+	mux.Handle(fakeapi.ControlPrefix, server.ControlHandler())
+	mux.Handle("/", server)
+
+	log.Printf("Fake Bot API for @%s listening on %s", server.Me().UserName, *addr)
+	if err := http.ListenAndServe(*addr, mux); err != nil {
+		log.Fatal(err)
+	}
+}
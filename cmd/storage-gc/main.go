@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/merdernoty/job-hunter/config"
+	"github.com/merdernoty/job-hunter/internal/attachments"
+	"github.com/merdernoty/job-hunter/internal/uploads"
+	user "github.com/merdernoty/job-hunter/internal/users"
+	"github.com/merdernoty/job-hunter/pkg/db/postgres"
+	"github.com/merdernoty/job-hunter/pkg/env"
+	"github.com/merdernoty/job-hunter/pkg/jwt"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+	"github.com/merdernoty/job-hunter/pkg/storage"
+	"github.com/merdernoty/job-hunter/pkg/telegram"
+	"go.uber.org/fx"
+)
+
+func init() {
+	env.Load()
+}
+
+// storage-gc runs a single reconciliation pass over the bucket and prints the
+// report as JSON. It defaults to a dry run; pass -dry-run=false to delete.
+func main() {
+	dryRun := flag.Bool("dry-run", true, "only report orphaned objects, do not delete them")
+	grace := flag.Duration("grace", 24*time.Hour, "minimum age of an object before it can be deleted")
+	flag.Parse()
+
+	var gc *storage.GarbageCollector
+
+	app := fx.New(
+		config.Module,
+		logger.Module,
+		postgres.Module,
+		storage.Module,
+		jwt.Module,
+		telegram.Module,
+		user.Module,
+		uploads.Module,
+		attachments.Module,
+		fx.Populate(&gc),
+		fx.NopLogger,
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := app.Start(ctx); err != nil {
+		log.Fatalf("Failed to start: %v", err)
+	}
+	defer app.Stop(context.Background())
+
+	report, err := gc.Run(context.Background(), storage.GCOptions{
+		DryRun:      *dryRun,
+		GracePeriod: *grace,
+	})
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if encErr := encoder.Encode(report); encErr != nil {
+		log.Printf("Failed to write report: %v", encErr)
+	}
+
+	if err != nil {
+		log.Fatalf("Garbage collection failed: %v", err)
+	}
+}
@@ -9,12 +9,90 @@ import (
 )
 
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Postgres PostgresConfig `mapstructure:"postgres"`
-	Logger   Logger         `mapstructure:"logger"`
-	Bot      BotConfig      `mapstructure:"bot"`
-	MiniO    MiniOConfig    `mapstructure:"minio"`
-	Jwt      JWTConfig      `mapstructure:"jwt"`
+	Server        ServerConfig        `mapstructure:"server"`
+	Postgres      PostgresConfig      `mapstructure:"postgres"`
+	Logger        Logger              `mapstructure:"logger"`
+	Bot           BotConfig           `mapstructure:"bot"`
+	MiniO         MiniOConfig         `mapstructure:"minio"`
+	Jwt           JWTConfig           `mapstructure:"jwt"`
+	Uploads       UploadsConfig       `mapstructure:"uploads"`
+	StorageGC     StorageGCConfig     `mapstructure:"storagegc"`
+	Attachments   AttachmentsConfig   `mapstructure:"attachments"`
+	Notifications NotificationsConfig `mapstructure:"notifications"`
+	Admin         AdminConfig         `mapstructure:"admin"`
+	DeepLinks     DeepLinksConfig     `mapstructure:"deeplinks"`
+	Premium       PremiumConfig       `mapstructure:"premium"`
+	RateLimit     RateLimitConfig     `mapstructure:"ratelimit"`
+}
+
+type RateLimitConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Store is "memory", which counts per replica, or "postgres", which
+	// shares the counters between replicas.
+	Store string `mapstructure:"store"`
+	// Default applies to every API route without an override; Auth and
+	// Uploads to the Telegram login and to the upload routes.
+	Default RateLimitPolicy `mapstructure:"default"`
+	Auth    RateLimitPolicy `mapstructure:"auth"`
+	Uploads RateLimitPolicy `mapstructure:"uploads"`
+}
+
+// RateLimitPolicy allows Requests per Window for each user, or for each IP
+// address when the request is not authenticated. Zero requests means no
+// limit.
+type RateLimitPolicy struct {
+	Requests int           `mapstructure:"requests"`
+	Window   time.Duration `mapstructure:"window"`
+}
+
+type PremiumConfig struct {
+	// FreeDailyProfiles caps how many random profiles a user is shown per
+	// day; the extra profiles feature raises it to PremiumDailyProfiles.
+	// Zero, the default, means no limit for anyone.
+	FreeDailyProfiles    int `mapstructure:"freedailyprofiles"`
+	PremiumDailyProfiles int `mapstructure:"premiumdailyprofiles"`
+}
+
+type DeepLinksConfig struct {
+	// Secret signs deep links. When empty the JWT secret is used.
+	Secret string `mapstructure:"secret"`
+}
+
+type AdminConfig struct {
+	// TelegramIDs lists the Telegram users allowed to use admin features.
+	TelegramIDs []int64 `mapstructure:"telegramids"`
+}
+
+type NotificationsConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	PollInterval time.Duration `mapstructure:"pollinterval"`
+	BatchSize    int           `mapstructure:"batchsize"`
+	// GlobalRate and PerChatRate are messages per second, kept below
+	// Telegram's limits of about 30/s overall and 1/s per chat.
+	GlobalRate  float64 `mapstructure:"globalrate"`
+	PerChatRate float64 `mapstructure:"perchatrate"`
+	// BroadcastRate caps broadcasts within GlobalRate so that they leave
+	// room for personal notifications.
+	BroadcastRate float64       `mapstructure:"broadcastrate"`
+	MaxAttempts   int           `mapstructure:"maxattempts"`
+	RetryBase     time.Duration `mapstructure:"retrybase"`
+	RetryMax      time.Duration `mapstructure:"retrymax"`
+}
+
+type AttachmentsConfig struct {
+	UserQuota int64 `mapstructure:"userquota"`
+}
+
+type StorageGCConfig struct {
+	Enabled     bool          `mapstructure:"enabled"`
+	DryRun      bool          `mapstructure:"dryrun"`
+	Interval    time.Duration `mapstructure:"interval"`
+	GracePeriod time.Duration `mapstructure:"graceperiod"`
+}
+
+type UploadsConfig struct {
+	PresignTTL      time.Duration `mapstructure:"presignttl"`
+	CleanupInterval time.Duration `mapstructure:"cleanupinterval"`
 }
 
 type JWTConfig struct {
@@ -23,17 +101,37 @@ type JWTConfig struct {
 }
 
 type MiniOConfig struct {
-	Endpoint        string `mapstructure:"endpoint"`
-	AccessKeyID     string `mapstructure:"accesskeyid"`
-	SecretAccessKey string `mapstructure:"secretaccesskey"`
-	BucketName      string `mapstructure:"bucketname"`
-	UseSSL          bool   `mapstructure:"usessl"`
-	Region          string `mapstructure:"region"`
+	Endpoint        string        `mapstructure:"endpoint"`
+	AccessKeyID     string        `mapstructure:"accesskeyid"`
+	SecretAccessKey string        `mapstructure:"secretaccesskey"`
+	BucketName      string        `mapstructure:"bucketname"`
+	UseSSL          bool          `mapstructure:"usessl"`
+	Region          string        `mapstructure:"region"`
+	PublicEndpoint  string        `mapstructure:"publicendpoint"`
+	PublicScheme    string        `mapstructure:"publicscheme"`
+	SignedURLTTL    time.Duration `mapstructure:"signedurlttl"`
 }
 
 type BotConfig struct {
 	Token     string `mapstructure:"token"`
 	WebAppURL string `mapstructure:"webappurl"`
+	// APIEndpoint is the Bot API URL format with placeholders for the token
+	// and the method, e.g. "http://localhost:8081/bot%s/%s" for the fake
+	// server in cmd/fakebotapi. Empty means api.telegram.org.
+	APIEndpoint string `mapstructure:"apiendpoint"`
+	// Mode is either "polling" or "webhook".
+	Mode          string `mapstructure:"mode"`
+	WebhookURL    string `mapstructure:"webhookurl"`
+	WebhookPath   string `mapstructure:"webhookpath"`
+	WebhookSecret string `mapstructure:"webhooksecret"`
+	// WebhookDeleteOnStop removes the webhook on shutdown. It suits a single
+	// instance only: with several replicas, or during a rolling deploy, the
+	// instance that stops would unregister the webhook for the others.
+	WebhookDeleteOnStop bool `mapstructure:"webhookdeleteonstop"`
+	// RateLimit is the sustained number of updates per second a single chat
+	// may send; RateBurst is how many it may send at once.
+	RateLimit float64 `mapstructure:"ratelimit"`
+	RateBurst int     `mapstructure:"rateburst"`
 }
 
 type ServerConfig struct {
@@ -41,6 +139,11 @@ type ServerConfig struct {
 	Debug      bool   `mapstructure:"debug"`
 	Mode       string `mapstructure:"mode"`
 	AppVersion string `mapstructure:"appversion"`
+	// TrustedProxies are the CIDR ranges or addresses of the reverse proxies
+	// in front of the server, comma separated in SERVER_TRUSTEDPROXIES.
+	// Client addresses are read from X-Forwarded-For only when the request
+	// comes through them.
+	TrustedProxies []string `mapstructure:"trustedproxies"`
 }
 
 type PostgresConfig struct {
@@ -93,6 +196,7 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("server.mode", "development")
 	v.SetDefault("server.debug", false)
 	v.SetDefault("server.appversion", "1.0.0")
+	v.SetDefault("server.trustedproxies", []string{})
 
 	// Postgres defaults
 	v.SetDefault("postgres.postgresqlhost", "localhost")
@@ -113,6 +217,14 @@ func setDefaults(v *viper.Viper) {
 	// Bot defaults
 	v.SetDefault("bot.token", "")
 	v.SetDefault("bot.webappurl", "")
+	v.SetDefault("bot.apiendpoint", "")
+	v.SetDefault("bot.mode", "polling")
+	v.SetDefault("bot.webhookurl", "")
+	v.SetDefault("bot.webhookpath", "/api/telegram/webhook")
+	v.SetDefault("bot.webhooksecret", "")
+	v.SetDefault("bot.webhookdeleteonstop", false)
+	v.SetDefault("bot.ratelimit", 1.0)
+	v.SetDefault("bot.rateburst", 5)
 
 	// MiniO defaults
 	v.SetDefault("minio.endpoint", "localhost:9000")
@@ -121,8 +233,55 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("minio.bucketname", "job-hunter-files")
 	v.SetDefault("minio.usessl", false)
 	v.SetDefault("minio.region", "us-east-1")
+	v.SetDefault("minio.publicendpoint", "")
+	v.SetDefault("minio.publicscheme", "")
+	v.SetDefault("minio.signedurlttl", time.Hour)
 
 	// JWT defaults
 	v.SetDefault("jwt.jwt_secret", "sadasdasd123sd")
 	v.SetDefault("jwt.jwt_ttl", 24*time.Hour)
+
+	// Uploads defaults
+	v.SetDefault("uploads.presignttl", 15*time.Minute)
+	v.SetDefault("uploads.cleanupinterval", 5*time.Minute)
+
+	// Storage GC defaults
+	v.SetDefault("storagegc.enabled", false)
+	v.SetDefault("storagegc.dryrun", true)
+	v.SetDefault("storagegc.interval", 24*time.Hour)
+	v.SetDefault("storagegc.graceperiod", 24*time.Hour)
+
+	// Attachments defaults
+	v.SetDefault("attachments.userquota", 50*1024*1024)
+
+	// Admin defaults
+	v.SetDefault("admin.telegramids", []int64{})
+
+	// Deep links defaults
+	v.SetDefault("deeplinks.secret", "")
+
+	// Premium defaults
+	v.SetDefault("premium.freedailyprofiles", 0)
+	v.SetDefault("premium.premiumdailyprofiles", 100)
+
+	// Rate limit defaults
+	v.SetDefault("ratelimit.enabled", true)
+	v.SetDefault("ratelimit.store", "memory")
+	v.SetDefault("ratelimit.default.requests", 300)
+	v.SetDefault("ratelimit.default.window", time.Minute)
+	v.SetDefault("ratelimit.auth.requests", 10)
+	v.SetDefault("ratelimit.auth.window", time.Minute)
+	v.SetDefault("ratelimit.uploads.requests", 30)
+	v.SetDefault("ratelimit.uploads.window", time.Minute)
+
+	// Notifications defaults
+	v.SetDefault("notifications.enabled", true)
+	v.SetDefault("notifications.pollinterval", 2*time.Second)
+	v.SetDefault("notifications.batchsize", 50)
+	v.SetDefault("notifications.globalrate", 25.0)
+	v.SetDefault("notifications.perchatrate", 1.0)
+	v.SetDefault("notifications.broadcastrate", 10.0)
+	v.SetDefault("notifications.maxattempts", 8)
+	v.SetDefault("notifications.retrybase", 5*time.Second)
+	v.SetDefault("notifications.retrymax", time.Hour)
 }
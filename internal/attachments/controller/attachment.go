@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/merdernoty/job-hunter/internal/attachments/domain"
+	"github.com/merdernoty/job-hunter/internal/users/middleware"
+	httpResponse "github.com/merdernoty/job-hunter/pkg/http"
+	"github.com/merdernoty/job-hunter/pkg/i18n"
+	"github.com/merdernoty/job-hunter/pkg/storage"
+)
+
+type AttachmentController struct {
+	attachmentService domain.AttachmentService
+	urlResolver       storage.URLResolver
+}
+
+func NewAttachmentController(attachmentService domain.AttachmentService, urlResolver storage.URLResolver) *AttachmentController {
+	return &AttachmentController{
+		attachmentService: attachmentService,
+		urlResolver:       urlResolver,
+	}
+}
+
+func (ctrl *AttachmentController) RegisterRoutes(rg *echo.Group, jwtMiddleware echo.MiddlewareFunc) {
+	users := rg.Group("/users", jwtMiddleware)
+	users.GET("/me/attachments", ctrl.listMine)
+	users.GET("/:id/attachments", ctrl.listByUser)
+
+	attachments := rg.Group("/attachments", jwtMiddleware)
+	attachments.GET("/:id/download", ctrl.download)
+	attachments.DELETE("/:id", ctrl.delete)
+}
+
+func (ctrl *AttachmentController) listMine(c echo.Context) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return httpResponse.UnauthorizedResponse(c, i18n.Message(c, "api.auth.required"))
+	}
+
+	attachments, err := ctrl.attachmentService.ListAttachments(c.Request().Context(), userID, userID)
+	if err != nil {
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.attachments.list_failed"))
+	}
+
+	usage, err := ctrl.attachmentService.GetUsage(c.Request().Context(), userID)
+	if err != nil {
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.attachments.usage_failed"))
+	}
+
+	return httpResponse.SuccessResponse(c, map[string]interface{}{
+		"attachments": ctrl.withURLs(attachments),
+		"usage":       usage,
+	})
+}
+
+func (ctrl *AttachmentController) listByUser(c echo.Context) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return httpResponse.UnauthorizedResponse(c, i18n.Message(c, "api.auth.required"))
+	}
+
+	ownerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return httpResponse.BadRequestResponse(c, i18n.Message(c, "api.users.invalid_id"))
+	}
+
+	attachments, err := ctrl.attachmentService.ListAttachments(c.Request().Context(), userID, ownerID)
+	if err != nil {
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.attachments.list_failed"))
+	}
+
+	return httpResponse.SuccessResponse(c, ctrl.withURLs(attachments))
+}
+
+func (ctrl *AttachmentController) download(c echo.Context) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return httpResponse.UnauthorizedResponse(c, i18n.Message(c, "api.auth.required"))
+	}
+
+	attachmentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return httpResponse.BadRequestResponse(c, i18n.Message(c, "api.attachments.invalid_id"))
+	}
+
+	attachment, err := ctrl.attachmentService.GetAttachment(c.Request().Context(), userID, attachmentID)
+	if err != nil {
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.attachments.get_failed"))
+	}
+
+	url, err := ctrl.urlResolver.ResolveURL(attachment.ObjectKey)
+	if err != nil {
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.attachments.resolve_failed"))
+	}
+
+	return c.Redirect(http.StatusFound, url)
+}
+
+func (ctrl *AttachmentController) delete(c echo.Context) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return httpResponse.UnauthorizedResponse(c, i18n.Message(c, "api.auth.required"))
+	}
+
+	attachmentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return httpResponse.BadRequestResponse(c, i18n.Message(c, "api.attachments.invalid_id"))
+	}
+
+	if err := ctrl.attachmentService.DeleteAttachment(c.Request().Context(), userID, attachmentID); err != nil {
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.attachments.delete_failed"))
+	}
+
+	return httpResponse.SuccessResponse(c, nil, i18n.Message(c, "api.attachments.deleted"))
+}
+
+func (ctrl *AttachmentController) withURLs(attachments []domain.Attachment) []domain.Attachment {
+	for i := range attachments {
+		if url, err := ctrl.urlResolver.ResolveURL(attachments[i].ObjectKey); err == nil {
+			attachments[i].URL = url
+		}
+	}
+	return attachments
+}
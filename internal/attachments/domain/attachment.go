@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	uploadDomain "github.com/merdernoty/job-hunter/internal/uploads/domain"
+	"github.com/merdernoty/job-hunter/pkg/apperr"
+)
+
+type AttachmentKind string
+
+const (
+	KindCV          AttachmentKind = "cv"
+	KindCertificate AttachmentKind = "certificate"
+	KindPortfolio   AttachmentKind = "portfolio"
+)
+
+var (
+	ErrAttachmentNotFound = apperr.NotFound("ATTACHMENT_NOT_FOUND", "attachment not found")
+	// ErrQuotaExceeded is shared with the uploads module so that upload
+	// endpoints can report it without knowing about attachments.
+	ErrQuotaExceeded = uploadDomain.ErrUploadQuotaExceeded
+)
+
+// KindRules are the per-kind constraints applied to uploads.
+type KindRules struct {
+	MaxSize      int64
+	AllowedTypes []string
+	// Public attachments can be listed and downloaded by any user. Others
+	// are only visible to their owner.
+	Public bool
+}
+
+var Kinds = map[AttachmentKind]KindRules{
+	KindCV: {
+		MaxSize:      10 * 1024 * 1024,
+		AllowedTypes: []string{"application/pdf"},
+	},
+	KindCertificate: {
+		MaxSize:      5 * 1024 * 1024,
+		AllowedTypes: []string{"application/pdf", "image/jpeg", "image/png"},
+	},
+	KindPortfolio: {
+		MaxSize:      10 * 1024 * 1024,
+		AllowedTypes: []string{"image/jpeg", "image/png", "image/gif", "image/webp", "application/pdf"},
+		Public:       true,
+	},
+}
+
+type Attachment struct {
+	ID          uuid.UUID      `json:"id" db:"id"`
+	OwnerID     uuid.UUID      `json:"owner_id" db:"owner_id"`
+	Kind        AttachmentKind `json:"kind" db:"kind"`
+	FileName    *string        `json:"file_name" db:"file_name"`
+	ObjectKey   string         `json:"-" db:"object_key"`
+	Size        int64          `json:"size" db:"size"`
+	ContentType string         `json:"content_type" db:"content_type"`
+	Checksum    string         `json:"checksum" db:"checksum"`
+	CreatedAt   time.Time      `json:"created_at" db:"created_at"`
+	URL         string         `json:"url,omitempty" db:"-"`
+}
+
+// VisibleTo reports whether viewerID may see the attachment.
+func (a *Attachment) VisibleTo(viewerID uuid.UUID) bool {
+	return a.OwnerID == viewerID || Kinds[a.Kind].Public
+}
+
+type StorageUsage struct {
+	Used  int64 `json:"used"`
+	Quota int64 `json:"quota"`
+}
+
+type AttachmentRepository interface {
+	// CreateWithinQuota inserts the attachment only if the owner's total
+	// stays within quota, returning ErrQuotaExceeded otherwise.
+	CreateWithinQuota(ctx context.Context, attachment *Attachment, quota int64) error
+	GetByID(ctx context.Context, id uuid.UUID) (*Attachment, error)
+	GetByOwner(ctx context.Context, ownerID uuid.UUID) ([]Attachment, error)
+	GetTotalSize(ctx context.Context, ownerID uuid.UUID) (int64, error)
+	Delete(ctx context.Context, id, ownerID uuid.UUID) (*Attachment, error)
+	GetReferencedKeys(ctx context.Context, keys []string) ([]string, error)
+}
+
+type AttachmentService interface {
+	CreateFromUpload(ctx context.Context, kind AttachmentKind, upload *uploadDomain.Upload) (*Attachment, error)
+	CheckQuota(ctx context.Context, ownerID uuid.UUID, size int64) error
+	// ListAttachments returns the attachments of ownerID that viewerID may
+	// see.
+	ListAttachments(ctx context.Context, viewerID, ownerID uuid.UUID) ([]Attachment, error)
+	// GetAttachment returns ErrAttachmentNotFound for attachments viewerID
+	// may not see, so that their existence is not revealed.
+	GetAttachment(ctx context.Context, viewerID, id uuid.UUID) (*Attachment, error)
+	GetUsage(ctx context.Context, ownerID uuid.UUID) (*StorageUsage, error)
+	DeleteAttachment(ctx context.Context, ownerID, id uuid.UUID) error
+}
@@ -0,0 +1,31 @@
+package attachments
+
+import (
+	"github.com/merdernoty/job-hunter/internal/attachments/domain"
+	"github.com/merdernoty/job-hunter/internal/attachments/repository"
+	"github.com/merdernoty/job-hunter/internal/attachments/service"
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module("attachments",
+	fx.Provide(
+		fx.Annotate(
+			repository.NewAttachmentRepository,
+			fx.As(new(domain.AttachmentRepository)),
+		),
+	),
+	fx.Provide(
+		fx.Annotate(
+			service.NewAttachmentService,
+			fx.As(new(domain.AttachmentService)),
+		),
+		fx.Annotate(
+			service.NewUploadAttachers,
+			fx.ResultTags(`group:"upload_attachers,flatten"`),
+		),
+		fx.Annotate(
+			service.NewAttachmentReferenceSource,
+			fx.ResultTags(`group:"storage_references"`),
+		),
+	),
+)
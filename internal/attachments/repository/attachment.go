@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/merdernoty/job-hunter/internal/attachments/domain"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+)
+
+type attachmentRepository struct {
+	db     *sqlx.DB
+	logger logger.Logger
+}
+
+func NewAttachmentRepository(db *sqlx.DB, logger logger.Logger) domain.AttachmentRepository {
+	return &attachmentRepository{db: db, logger: logger}
+}
+
+func (r *attachmentRepository) CreateWithinQuota(ctx context.Context, attachment *domain.Attachment, quota int64) error {
+	if attachment.ID == uuid.Nil {
+		attachment.ID = uuid.New()
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to begin attachment transaction: %v", err)
+		return fmt.Errorf("failed to create attachment")
+	}
+	defer tx.Rollback()
+
+	// Uploads of the same owner are serialized so that concurrent ones cannot
+	// each pass the quota check on its own.
+	if _, err := tx.ExecContext(ctx, `SELECT 1 FROM users WHERE id = $1 FOR UPDATE`, attachment.OwnerID); err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to lock user %s for attachment: %v", attachment.OwnerID, err)
+		return fmt.Errorf("failed to create attachment")
+	}
+
+	query := `
+		INSERT INTO attachments (id, owner_id, kind, file_name, object_key, size, content_type, checksum)
+		SELECT $1, $2, $3, $4, $5, $6, $7, $8
+		WHERE (SELECT COALESCE(SUM(size), 0) FROM attachments WHERE owner_id = $2) + $6 <= $9
+		RETURNING created_at`
+
+	err = tx.QueryRowContext(
+		ctx, query,
+		attachment.ID, attachment.OwnerID, attachment.Kind, attachment.FileName, attachment.ObjectKey,
+		attachment.Size, attachment.ContentType, attachment.Checksum, quota,
+	).Scan(&attachment.CreatedAt)
+	if err == sql.ErrNoRows {
+		return domain.ErrQuotaExceeded
+	}
+	if err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to create attachment: %v", err)
+		return fmt.Errorf("failed to create attachment")
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to commit attachment: %v", err)
+		return fmt.Errorf("failed to create attachment")
+	}
+
+	r.logger.FromContext(ctx).Infof("Created %s attachment %s for user %s", attachment.Kind, attachment.ID, attachment.OwnerID)
+	return nil
+}
+
+func (r *attachmentRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Attachment, error) {
+	var attachment domain.Attachment
+	query := `
+		SELECT id, owner_id, kind, file_name, object_key, size, content_type, checksum, created_at
+		FROM attachments
+		WHERE id = $1`
+
+	err := r.db.GetContext(ctx, &attachment, query, id)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrAttachmentNotFound
+	}
+	if err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to get attachment %s: %v", id, err)
+		return nil, fmt.Errorf("database error")
+	}
+
+	return &attachment, nil
+}
+
+func (r *attachmentRepository) GetByOwner(ctx context.Context, ownerID uuid.UUID) ([]domain.Attachment, error) {
+	attachments := []domain.Attachment{}
+	query := `
+		SELECT id, owner_id, kind, file_name, object_key, size, content_type, checksum, created_at
+		FROM attachments
+		WHERE owner_id = $1
+		ORDER BY created_at DESC`
+
+	err := r.db.SelectContext(ctx, &attachments, query, ownerID)
+	if err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to get attachments for user %s: %v", ownerID, err)
+		return nil, fmt.Errorf("database error")
+	}
+
+	return attachments, nil
+}
+
+func (r *attachmentRepository) GetTotalSize(ctx context.Context, ownerID uuid.UUID) (int64, error) {
+	var total int64
+	query := `SELECT COALESCE(SUM(size), 0) FROM attachments WHERE owner_id = $1`
+
+	if err := r.db.GetContext(ctx, &total, query, ownerID); err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to get attachment usage for user %s: %v", ownerID, err)
+		return 0, fmt.Errorf("database error")
+	}
+
+	return total, nil
+}
+
+func (r *attachmentRepository) Delete(ctx context.Context, id, ownerID uuid.UUID) (*domain.Attachment, error) {
+	var attachment domain.Attachment
+	query := `
+		DELETE FROM attachments
+		WHERE id = $1 AND owner_id = $2
+		RETURNING id, owner_id, kind, file_name, object_key, size, content_type, checksum, created_at`
+
+	err := r.db.GetContext(ctx, &attachment, query, id, ownerID)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrAttachmentNotFound
+	}
+	if err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to delete attachment %s: %v", id, err)
+		return nil, fmt.Errorf("database error")
+	}
+
+	return &attachment, nil
+}
+
+func (r *attachmentRepository) GetReferencedKeys(ctx context.Context, keys []string) ([]string, error) {
+	var referenced []string
+	query := `
+		SELECT object_key
+		FROM attachments
+		WHERE object_key = ANY($1)`
+
+	err := r.db.SelectContext(ctx, &referenced, query, pq.Array(keys))
+	if err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to get referenced attachment keys: %v", err)
+		return nil, fmt.Errorf("database error")
+	}
+
+	return referenced, nil
+}
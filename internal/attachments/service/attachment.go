@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/merdernoty/job-hunter/config"
+	"github.com/merdernoty/job-hunter/internal/attachments/domain"
+	uploadDomain "github.com/merdernoty/job-hunter/internal/uploads/domain"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+	"github.com/merdernoty/job-hunter/pkg/storage"
+)
+
+type attachmentService struct {
+	attachmentRepo domain.AttachmentRepository
+	minioClient    *storage.MinIOClient
+	userQuota      int64
+	logger         logger.Logger
+}
+
+func NewAttachmentService(
+	attachmentRepo domain.AttachmentRepository,
+	minioClient *storage.MinIOClient,
+	cfg *config.Config,
+	logger logger.Logger,
+) domain.AttachmentService {
+	return &attachmentService{
+		attachmentRepo: attachmentRepo,
+		minioClient:    minioClient,
+		userQuota:      cfg.Attachments.UserQuota,
+		logger:         logger,
+	}
+}
+
+func (s *attachmentService) CreateFromUpload(ctx context.Context, kind domain.AttachmentKind, upload *uploadDomain.Upload) (*domain.Attachment, error) {
+	if upload.Size == nil || upload.Checksum == nil {
+		return nil, fmt.Errorf("upload %s is not verified", upload.ID)
+	}
+
+	attachment := &domain.Attachment{
+		ID:          uuid.New(),
+		OwnerID:     upload.OwnerID,
+		Kind:        kind,
+		FileName:    upload.FileName,
+		ObjectKey:   upload.ObjectKey,
+		Size:        *upload.Size,
+		ContentType: upload.ContentType,
+		Checksum:    *upload.Checksum,
+	}
+
+	if err := s.attachmentRepo.CreateWithinQuota(ctx, attachment, s.userQuota); err != nil {
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+func (s *attachmentService) CheckQuota(ctx context.Context, ownerID uuid.UUID, size int64) error {
+	used, err := s.attachmentRepo.GetTotalSize(ctx, ownerID)
+	if err != nil {
+		return err
+	}
+
+	if used+size > s.userQuota {
+		s.logger.FromContext(ctx).Infof("User %s is over attachment quota: used %d + %d > %d", ownerID, used, size, s.userQuota)
+		return domain.ErrQuotaExceeded
+	}
+
+	return nil
+}
+
+func (s *attachmentService) ListAttachments(ctx context.Context, viewerID, ownerID uuid.UUID) ([]domain.Attachment, error) {
+	attachments, err := s.attachmentRepo.GetByOwner(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := attachments[:0]
+	for _, attachment := range attachments {
+		if attachment.VisibleTo(viewerID) {
+			visible = append(visible, attachment)
+		}
+	}
+	return visible, nil
+}
+
+func (s *attachmentService) GetAttachment(ctx context.Context, viewerID, id uuid.UUID) (*domain.Attachment, error) {
+	attachment, err := s.attachmentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !attachment.VisibleTo(viewerID) {
+		return nil, domain.ErrAttachmentNotFound
+	}
+	return attachment, nil
+}
+
+func (s *attachmentService) GetUsage(ctx context.Context, ownerID uuid.UUID) (*domain.StorageUsage, error) {
+	used, err := s.attachmentRepo.GetTotalSize(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.StorageUsage{
+		Used:  used,
+		Quota: s.userQuota,
+	}, nil
+}
+
+func (s *attachmentService) DeleteAttachment(ctx context.Context, ownerID, id uuid.UUID) error {
+	attachment, err := s.attachmentRepo.Delete(ctx, id, ownerID)
+	if err != nil {
+		return err
+	}
+
+	// Best effort: an object left behind is picked up by the storage garbage collector.
+	if err := s.minioClient.RemoveObject(attachment.ObjectKey); err != nil && !storage.IsNotFound(err) {
+		s.logger.FromContext(ctx).Warnf("Failed to delete attachment object %s: %v", attachment.ObjectKey, err)
+	}
+
+	s.logger.FromContext(ctx).Infof("Deleted attachment %s for user %s", id, ownerID)
+	return nil
+}
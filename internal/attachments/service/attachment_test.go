@@ -0,0 +1,95 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/merdernoty/job-hunter/config"
+	"github.com/merdernoty/job-hunter/internal/attachments/domain"
+	"github.com/merdernoty/job-hunter/internal/attachments/service"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+)
+
+var (
+	owner    = uuid.New()
+	stranger = uuid.New()
+)
+
+func TestOthersOnlySeePublicAttachments(t *testing.T) {
+	repo := newMemoryAttachments(
+		domain.Attachment{ID: uuid.New(), OwnerID: owner, Kind: domain.KindCV},
+		domain.Attachment{ID: uuid.New(), OwnerID: owner, Kind: domain.KindCertificate},
+		domain.Attachment{ID: uuid.New(), OwnerID: owner, Kind: domain.KindPortfolio},
+	)
+	attachments := newService(repo)
+
+	own, err := attachments.ListAttachments(context.Background(), owner, owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(own) != 3 {
+		t.Errorf("owner sees %d attachments, want 3", len(own))
+	}
+
+	listed, err := attachments.ListAttachments(context.Background(), stranger, owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != 1 || listed[0].Kind != domain.KindPortfolio {
+		t.Errorf("stranger sees %v, want the portfolio only", listed)
+	}
+}
+
+func TestOthersCannotGetPrivateAttachments(t *testing.T) {
+	cv := domain.Attachment{ID: uuid.New(), OwnerID: owner, Kind: domain.KindCV}
+	portfolio := domain.Attachment{ID: uuid.New(), OwnerID: owner, Kind: domain.KindPortfolio}
+	attachments := newService(newMemoryAttachments(cv, portfolio))
+
+	if _, err := attachments.GetAttachment(context.Background(), owner, cv.ID); err != nil {
+		t.Errorf("owner cannot get their CV: %v", err)
+	}
+	if _, err := attachments.GetAttachment(context.Background(), stranger, cv.ID); !errors.Is(err, domain.ErrAttachmentNotFound) {
+		t.Errorf("stranger got the CV with error %v, want %v", err, domain.ErrAttachmentNotFound)
+	}
+	if _, err := attachments.GetAttachment(context.Background(), stranger, portfolio.ID); err != nil {
+		t.Errorf("stranger cannot get the portfolio: %v", err)
+	}
+}
+
+func newService(repo domain.AttachmentRepository) domain.AttachmentService {
+	cfg := &config.Config{Logger: config.Logger{Level: "error"}}
+	return service.NewAttachmentService(repo, nil, cfg, logger.NewLogger(cfg))
+}
+
+// memoryAttachments serves reads from a fixed set of attachments. Other
+// methods of the repository are not used by these tests.
+type memoryAttachments struct {
+	domain.AttachmentRepository
+
+	attachments []domain.Attachment
+}
+
+func newMemoryAttachments(attachments ...domain.Attachment) *memoryAttachments {
+	return &memoryAttachments{attachments: attachments}
+}
+
+func (m *memoryAttachments) GetByID(ctx context.Context, id uuid.UUID) (*domain.Attachment, error) {
+	for _, attachment := range m.attachments {
+		if attachment.ID == id {
+			return &attachment, nil
+		}
+	}
+	return nil, domain.ErrAttachmentNotFound
+}
+
+func (m *memoryAttachments) GetByOwner(ctx context.Context, ownerID uuid.UUID) ([]domain.Attachment, error) {
+	var attachments []domain.Attachment
+	for _, attachment := range m.attachments {
+		if attachment.OwnerID == ownerID {
+			attachments = append(attachments, attachment)
+		}
+	}
+	return attachments, nil
+}
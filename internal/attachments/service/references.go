@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"github.com/merdernoty/job-hunter/internal/attachments/domain"
+	"github.com/merdernoty/job-hunter/pkg/storage"
+)
+
+type attachmentReferenceSource struct {
+	attachmentRepo domain.AttachmentRepository
+}
+
+func NewAttachmentReferenceSource(attachmentRepo domain.AttachmentRepository) storage.ReferenceSource {
+	return &attachmentReferenceSource{attachmentRepo: attachmentRepo}
+}
+
+func (s *attachmentReferenceSource) Name() string {
+	return "attachments.object_key"
+}
+
+func (s *attachmentReferenceSource) Prefixes() []string {
+	return []string{keyPrefix}
+}
+
+func (s *attachmentReferenceSource) ReferencedKeys(ctx context.Context, keys []string) (map[string]bool, error) {
+	referenced, err := s.attachmentRepo.GetReferencedKeys(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]bool, len(referenced))
+	for _, key := range referenced {
+		result[key] = true
+	}
+	return result, nil
+}
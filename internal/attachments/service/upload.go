@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/merdernoty/job-hunter/internal/attachments/domain"
+	uploadDomain "github.com/merdernoty/job-hunter/internal/uploads/domain"
+)
+
+const keyPrefix = "attachments"
+
+type kindUploadAttacher struct {
+	kind              domain.AttachmentKind
+	rules             domain.KindRules
+	attachmentService domain.AttachmentService
+}
+
+// NewUploadAttachers registers one upload purpose per attachment kind.
+func NewUploadAttachers(attachmentService domain.AttachmentService) []uploadDomain.UploadAttacher {
+	attachers := make([]uploadDomain.UploadAttacher, 0, len(domain.Kinds))
+	for kind, rules := range domain.Kinds {
+		attachers = append(attachers, &kindUploadAttacher{
+			kind:              kind,
+			rules:             rules,
+			attachmentService: attachmentService,
+		})
+	}
+	return attachers
+}
+
+func (a *kindUploadAttacher) Purpose() string {
+	return string(a.kind)
+}
+
+func (a *kindUploadAttacher) Rules() uploadDomain.UploadRules {
+	return uploadDomain.UploadRules{
+		KeyPrefix:    keyPrefix + "/" + string(a.kind),
+		MaxSize:      a.rules.MaxSize,
+		AllowedTypes: a.rules.AllowedTypes,
+	}
+}
+
+func (a *kindUploadAttacher) Precheck(ctx context.Context, ownerID uuid.UUID, size int64) error {
+	return a.attachmentService.CheckQuota(ctx, ownerID, size)
+}
+
+func (a *kindUploadAttacher) Attach(ctx context.Context, upload *uploadDomain.Upload) (interface{}, error) {
+	return a.attachmentService.CreateFromUpload(ctx, a.kind, upload)
+}
@@ -2,35 +2,110 @@ package bot
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/merdernoty/job-hunter/config"
 	"github.com/merdernoty/job-hunter/pkg/logger"
 )
 
+const (
+	ModePolling = "polling"
+	ModeWebhook = "webhook"
+)
+
+const (
+	// pollTimeout is how long, in seconds, getUpdates waits for updates.
+	pollTimeout    = 60
+	pollRetryDelay = 3 * time.Second
+)
+
 type Bot struct {
-	api        *tgbotapi.BotAPI
+	api *tgbotapi.BotAPI
+	// endpoint is the Bot API URL format the api was created with, for
+	// requests made without the library.
+	endpoint   string
 	logger     logger.Logger
 	webAppURL  string
+	mode       string
+	webhook    webhookSettings
+	router     *Router
 	cancelFunc context.CancelFunc
+	// offset is the update_id after the last update received by polling.
+	offset int
 }
 
-func NewBot(token, webAppURL string, logger logger.Logger) (*Bot, error) {
-	api, err := tgbotapi.NewBotAPI(token)
+func NewBot(cfg config.BotConfig, router *Router, logger logger.Logger) (*Bot, error) {
+	mode := cfg.Mode
+	if mode == "" {
+		mode = ModePolling
+	}
+	if mode != ModePolling && mode != ModeWebhook {
+		return nil, fmt.Errorf("unknown bot mode %q", mode)
+	}
+
+	webhook, err := newWebhookSettings(cfg)
+	if mode == ModeWebhook && err != nil {
+		return nil, err
+	}
+
+	endpoint := cfg.APIEndpoint
+	if endpoint == "" {
+		endpoint = tgbotapi.APIEndpoint
+	}
+
+	api, err := tgbotapi.NewBotAPIWithAPIEndpoint(cfg.Token, endpoint)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create bot: %w", err)
 	}
 
 	api.Debug = false
-	logger.Infof("Bot authorized on account %s", api.Self.UserName)
+	logger.Infof("Bot authorized on account %s (mode: %s)", api.Self.UserName, mode)
+	if endpoint != tgbotapi.APIEndpoint {
+		logger.Warnf("Bot API endpoint overridden: %s", endpoint)
+	}
 
 	return &Bot{
 		api:       api,
+		endpoint:  endpoint,
 		logger:    logger,
-		webAppURL: webAppURL,
+		webAppURL: cfg.WebAppURL,
+		mode:      mode,
+		webhook:   webhook,
+		router:    router,
 	}, nil
 }
 
+func (b *Bot) Mode() string {
+	return b.mode
+}
+
+func (b *Bot) Username() string {
+	return b.api.Self.UserName
+}
+
+// MiniAppLink returns a t.me link that opens the bot's main mini app with the
+// given start parameter, which the web app reads as start_param.
+func (b *Bot) MiniAppLink(startParam string) string {
+	link := fmt.Sprintf("https://t.me/%s", b.Username())
+	if startParam == "" {
+		return link + "?startapp"
+	}
+	return link + "?startapp=" + url.QueryEscape(startParam)
+}
+
+// StartLink returns a t.me link that opens the chat with the bot and sends
+// /start with the given payload.
+func (b *Bot) StartLink(payload string) string {
+	return fmt.Sprintf("https://t.me/%s?start=%s", b.Username(), url.QueryEscape(payload))
+}
+
 func (b *Bot) Start(ctx context.Context) error {
 	b.logger.Info("Starting Telegram bot...")
 
@@ -45,6 +120,9 @@ func (b *Bot) Start(ctx context.Context) error {
 	}
 }
 
+// runUpdatesLoop long-polls getUpdates itself rather than through the
+// library, so that every update keeps its raw JSON for the fields the library
+// does not decode, such as refunded_payment.
 func (b *Bot) runUpdatesLoop(ctx context.Context) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -52,129 +130,133 @@ func (b *Bot) runUpdatesLoop(ctx context.Context) {
 		}
 	}()
 
-	u := tgbotapi.NewUpdate(0)
-	u.Timeout = 60
-
-	updates := b.api.GetUpdatesChan(u)
-
 	for {
-		select {
-		case <-ctx.Done():
+		updates, err := b.getUpdates(ctx)
+		if ctx.Err() != nil {
 			b.logger.Info("Stopping Telegram bot updates loop...")
-			b.api.StopReceivingUpdates()
 			return
+		}
+		if err != nil {
+			b.logger.Warnf("Failed to get updates, retrying in %v: %v", pollRetryDelay, err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(pollRetryDelay):
+			}
+			continue
+		}
 
-		case update := <-updates:
-			if update.UpdateID == 0 {
+		for _, raw := range updates {
+			var update tgbotapi.Update
+			if err := json.Unmarshal(raw, &update); err != nil {
+				b.logger.Warnf("Failed to decode update: %v", err)
 				continue
 			}
-			b.handleUpdate(update)
+			if update.UpdateID >= b.offset {
+				b.offset = update.UpdateID + 1
+			}
+			b.handleUpdate(update, raw)
 		}
 	}
 }
 
-func (b *Bot) handleUpdate(update tgbotapi.Update) {
-	if update.Message == nil {
-		return
+// getUpdates returns the next updates as raw JSON, confirming the ones
+// handled so far. The request is bound to ctx, so cancelling ctx ends the
+// long poll at once.
+func (b *Bot) getUpdates(ctx context.Context) ([]json.RawMessage, error) {
+	params := tgbotapi.Params{}
+	params.AddNonZero("offset", b.offset)
+	params.AddNonZero("timeout", pollTimeout)
+
+	form := url.Values{}
+	for key, value := range params {
+		form.Set(key, value)
 	}
 
-	msg := update.Message
-	b.logger.Infof("Received message from %s: %s", msg.From.UserName, msg.Text)
-
-	switch msg.Command() {
-	case "start":
-		b.handleStart(msg)
-	case "app":
-		b.handleApp(msg)
-	case "help":
-		b.handleHelp(msg)
-	default:
-		if msg.Text != "" {
-			b.handleDefault(msg)
-		}
+	method := fmt.Sprintf(b.endpoint, b.api.Token, "getUpdates")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, method, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
 	}
-}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-func (b *Bot) handleStart(msg *tgbotapi.Message) {
-	firstName := msg.From.FirstName
-	if firstName == "" {
-		firstName = "пользователь"
+	resp, err := b.api.Client.Do(req)
+	if err != nil {
+		return nil, err
 	}
+	defer resp.Body.Close()
 
-	text := fmt.Sprintf(
-		"👋 Привет, <b>%s</b>!\n\n"+
-			"Добро пожаловать в <b>Job Hunter</b>!\n\n"+
-			"🎯 Здесь ты можешь:\n"+
-			"• Найти работу своей мечты\n"+
-			"• Разместить вакансию\n"+
-			"• Создать резюме\n"+
-			"• Откликнуться на вакансии\n\n"+
-			"🔗 Приложение: %s\n\n"+
-			"Используй команду /app для получения ссылки",
-		firstName,
-		b.webAppURL,
-	)
-
-	reply := tgbotapi.NewMessage(msg.Chat.ID, text)
-	reply.ParseMode = tgbotapi.ModeHTML
+	var apiResp tgbotapi.APIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, err
+	}
+	if !apiResp.Ok {
+		apiErr := &tgbotapi.Error{Code: apiResp.ErrorCode, Message: apiResp.Description}
+		if apiResp.Parameters != nil {
+			apiErr.ResponseParameters = *apiResp.Parameters
+		}
+		return nil, apiErr
+	}
 
-	if _, err := b.api.Send(reply); err != nil {
-		b.logger.Errorf("Failed to send start message: %v", err)
+	var updates []json.RawMessage
+	if err := json.Unmarshal(apiResp.Result, &updates); err != nil {
+		return nil, err
 	}
+	return updates, nil
 }
 
-func (b *Bot) handleApp(msg *tgbotapi.Message) {
-	text := fmt.Sprintf("🔥 <b>Job Hunter Web App</b>\n\nПриложение: %s", b.webAppURL)
+func (b *Bot) handleUpdate(update tgbotapi.Update, raw json.RawMessage) {
+	if b.router == nil {
+		return
+	}
 
-	reply := tgbotapi.NewMessage(msg.Chat.ID, text)
-	reply.ParseMode = tgbotapi.ModeHTML
+	c := newContext(context.Background(), b, update, raw)
+	c.WithFields("update_id", update.UpdateID)
+	if chatID := c.ChatID(); chatID != 0 {
+		c.WithFields("chat_id", chatID)
+	}
 
-	if _, err := b.api.Send(reply); err != nil {
-		b.logger.Errorf("Failed to send app message: %v", err)
+	if err := b.router.Dispatch(c); err != nil {
+		b.logger.FromContext(c).Errorf("Failed to handle update %d: %v", update.UpdateID, err)
 	}
 }
 
-func (b *Bot) handleHelp(msg *tgbotapi.Message) {
-	text := `<b>🤖 Job Hunter Bot</b>
-
-<b>Доступные команды:</b>
-/start - Приветствие и ссылка на приложение
-/app - Получить ссылку на приложение
-/help - Показать эту справку
-
-<b>О боте:</b>
-Этот бот предоставляет доступ к платформе Job Hunter,
-где вы можете найти работу или разместить вакансию.
+// Router returns the router updates are dispatched through.
+func (b *Bot) Router() *Router {
+	return b.router
+}
 
-<b>Приложение:</b>
-` + b.webAppURL
+// Send delivers a message outside of an update, e.g. a notification. API
+// errors are returned as *tgbotapi.Error so callers can inspect retry_after.
+func (b *Bot) Send(chattable tgbotapi.Chattable) (tgbotapi.Message, error) {
+	return b.api.Send(chattable)
+}
 
-	reply := tgbotapi.NewMessage(msg.Chat.ID, text)
-	reply.ParseMode = tgbotapi.ModeHTML
+// Request calls a method that does not return a message, such as an edit of
+// a channel post.
+func (b *Bot) Request(chattable tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	return b.api.Request(chattable)
+}
 
-	if _, err := b.api.Send(reply); err != nil {
-		b.logger.Errorf("Failed to send help message: %v", err)
+// GetChat looks up a group or channel by numeric ID or @username.
+func (b *Bot) GetChat(ref string) (tgbotapi.Chat, error) {
+	config := tgbotapi.ChatInfoConfig{}
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		config.ChatID = id
+	} else {
+		config.SuperGroupUsername = "@" + strings.TrimPrefix(ref, "@")
 	}
+	return b.api.GetChat(config)
 }
 
-func (b *Bot) handleDefault(msg *tgbotapi.Message) {
-	text := fmt.Sprintf("Для работы с Job Hunter перейди по ссылке:\n%s", b.webAppURL)
-
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonURL(
-				"📱 Открыть приложение",
-				b.webAppURL,
-			),
-		),
-	)
-
-	reply := tgbotapi.NewMessage(msg.Chat.ID, text)
-	reply.ReplyMarkup = keyboard
-
-	if _, err := b.api.Send(reply); err != nil {
-		b.logger.Errorf("Failed to send default message: %v", err)
+// GetChatMember returns the membership of userID in chatID, or of the bot
+// itself when userID is 0.
+func (b *Bot) GetChatMember(chatID, userID int64) (tgbotapi.ChatMember, error) {
+	if userID == 0 {
+		userID = b.api.Self.ID
 	}
+	return b.api.GetChatMember(tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{ChatID: chatID, UserID: userID},
+	})
 }
 
 func (b *Bot) Stop() {
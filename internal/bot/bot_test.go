@@ -0,0 +1,187 @@
+package bot
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/merdernoty/job-hunter/config"
+	"github.com/merdernoty/job-hunter/internal/users/domain"
+	"github.com/merdernoty/job-hunter/pkg/deeplink"
+	"github.com/merdernoty/job-hunter/pkg/i18n"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+	"github.com/merdernoty/job-hunter/pkg/telegram/fakeapi"
+)
+
+func TestStartRoundTrip(t *testing.T) {
+	api := fakeapi.NewServer("123:test")
+	server := httptest.NewServer(api)
+	t.Cleanup(func() {
+		// A getUpdates call may still be waiting for updates.
+		server.CloseClientConnections()
+		server.Close()
+	})
+
+	cfg := &config.Config{
+		Logger: config.Logger{Level: "error"},
+		Bot:    config.BotConfig{Token: "123:test", APIEndpoint: fakeapi.Endpoint(server.URL), RateLimit: 1, RateBurst: 5},
+	}
+	log := logger.NewLogger(cfg)
+	translator, err := i18n.NewTranslator()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := NewRouter()
+	users := &newUsers{}
+	RegisterHandlers(router, cfg, users, translator, deeplink.NewCodec(cfg), log, nil)
+
+	bot, err := NewBot(cfg.Bot, router, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = bot.Start(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	api.InjectMessage(42, "/start")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(api.CallsTo("sendMessage")) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("/start was not answered")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	reply := api.CallsTo("sendMessage")[0]
+	if reply.Params["chat_id"] != "42" {
+		t.Errorf("reply went to chat %s, want 42", reply.Params["chat_id"])
+	}
+	if !strings.Contains(reply.Params["text"], "User") {
+		t.Errorf("reply does not greet the sender: %q", reply.Params["text"])
+	}
+	if chatID := users.linkedChat(); chatID != 42 {
+		t.Errorf("linked chat %d, want 42", chatID)
+	}
+}
+
+func TestUnmatchedCallbackIsAnswered(t *testing.T) {
+	api := fakeapi.NewServer("123:test")
+	server := httptest.NewServer(api)
+	t.Cleanup(func() {
+		server.CloseClientConnections()
+		server.Close()
+	})
+
+	log := logger.NewLogger(&config.Config{Logger: config.Logger{Level: "error"}})
+	router := NewRouter()
+	router.Callback("known", func(c *Context) error { return nil })
+
+	bot, err := NewBot(config.BotConfig{Token: "123:test", APIEndpoint: fakeapi.Endpoint(server.URL)}, router, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = bot.Start(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	api.InjectCallback(42, 1, "removed:action")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(api.CallsTo("answerCallbackQuery")) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("callback without a handler was not answered")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestStopEndsLongPoll(t *testing.T) {
+	api := fakeapi.NewServer("123:test")
+	server := httptest.NewServer(api)
+
+	log := logger.NewLogger(&config.Config{Logger: config.Logger{Level: "error"}})
+	bot, err := NewBot(config.BotConfig{Token: "123:test", APIEndpoint: fakeapi.Endpoint(server.URL)}, NewRouter(), log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = bot.Start(ctx)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(api.CallsTo("getUpdates")) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("bot did not poll for updates")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	<-done
+
+	// Close waits for requests in flight, so it only returns once the
+	// cancelled poll has been abandoned.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		server.Close()
+	}()
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		server.CloseClientConnections()
+		t.Fatal("getUpdates was still waiting after the bot stopped")
+	}
+}
+
+// newUsers knows no users yet and records the chat /start links. Other
+// methods of the user service are not used by /start.
+type newUsers struct {
+	domain.UserService
+
+	mu     sync.Mutex
+	chatID int64
+}
+
+func (u *newUsers) GetUserByTelegramID(ctx context.Context, telegramID int64) (*domain.User, error) {
+	return nil, domain.ErrUserNotFound
+}
+
+func (u *newUsers) LinkBotChat(ctx context.Context, telegramID int64, username string, chatID int64) (*domain.User, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.chatID = chatID
+	return &domain.User{ID: uuid.New(), TelegramID: telegramID, Username: username}, nil
+}
+
+func (u *newUsers) linkedChat() int64 {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.chatID
+}
@@ -0,0 +1,204 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/merdernoty/job-hunter/internal/users/domain"
+	"github.com/merdernoty/job-hunter/pkg/deeplink"
+	"github.com/merdernoty/job-hunter/pkg/i18n"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+)
+
+// coreHandlers are the commands every deployment of the bot answers.
+type coreHandlers struct {
+	router      *Router
+	translator  *i18n.Translator
+	userService domain.UserService
+	deepLinks   *deeplink.Codec
+	webAppURL   string
+	logger      logger.Logger
+}
+
+func (h *coreHandlers) Register(r *Router) {
+	r.Command("start", "command.start", h.start)
+	r.Command("app", "command.app", h.app)
+	r.Command("help", "command.help", h.help)
+	r.Command("language", "command.language", h.language)
+	r.Callback("language", h.setLanguage)
+	r.On(UpdateMyChatMember, h.chatMember)
+	r.Fallback(h.fallback)
+}
+
+func (h *coreHandlers) start(c *Context) error {
+	// Known users had their chat linked by UserLookup already.
+	isNew := c.CurrentUser() == nil
+	if sender := c.Sender(); isNew && sender != nil && isPrivate(c) {
+		if _, err := h.userService.LinkBotChat(c, sender.ID, sender.UserName, c.ChatID()); err != nil {
+			return err
+		}
+	}
+
+	if payload := c.Args(); payload != "" {
+		link, err := h.deepLinks.Decode(payload)
+		if err == nil {
+			return h.startLink(c, payload, link, isNew)
+		}
+		h.logger.FromContext(c).Warnf("Ignoring invalid /start payload %q", payload)
+	}
+
+	firstName := ""
+	if sender := c.Sender(); sender != nil {
+		firstName = sender.FirstName
+	}
+	if firstName == "" {
+		firstName = c.T("start.default_name")
+	}
+
+	return h.replyWithApp(c, c.T("start.text", firstName), "")
+}
+
+// startLink answers /start with a deep link payload: it offers to open the
+// linked page in the mini app and credits referrals to new users.
+func (h *coreHandlers) startLink(c *Context, payload string, link deeplink.Link, isNew bool) error {
+	if link.Type == deeplink.Referral && isNew {
+		if sender := c.Sender(); sender != nil {
+			user, err := h.userService.EnsureTelegramUser(c, sender.ID, sender.UserName)
+			if err != nil {
+				return err
+			}
+			if err := h.userService.ApplyReferral(c, user.ID, link.ID); err != nil {
+				h.logger.FromContext(c).Warnf("Failed to apply referral for user %s: %v", user.ID, err)
+			}
+		}
+	}
+
+	return h.replyWithApp(c, c.T("start.link."+string(link.Type)), payload)
+}
+
+// app pins a reply keyboard button that opens the mini app.
+func (h *coreHandlers) app(c *Context) error {
+	if h.webAppURL == "" || !isPrivate(c) {
+		return h.replyWithApp(c, c.T("app.text"), "")
+	}
+
+	reply := tgbotapi.NewMessage(c.ChatID(), c.T("app.text"))
+	reply.ParseMode = tgbotapi.ModeHTML
+	reply.ReplyMarkup = NewWebAppKeyboard(c.T("default.open_app"), h.webAppURL)
+
+	_, err := c.Send(reply)
+	return err
+}
+
+// help lists the visible commands; Command descriptions are catalog keys.
+func (h *coreHandlers) help(c *Context) error {
+	var commands strings.Builder
+	for _, cmd := range h.router.Commands() {
+		if cmd.Hidden {
+			continue
+		}
+		fmt.Fprintf(&commands, "/%s - %s\n", cmd.Name, c.T(cmd.Description))
+	}
+
+	text := c.T("help.title") + "\n\n" +
+		c.T("help.commands") + "\n" +
+		commands.String() + "\n" +
+		c.T("help.about") + "\n\n" +
+		c.T("help.app")
+
+	return h.replyWithApp(c, text, "")
+}
+
+func (h *coreHandlers) language(c *Context) error {
+	var row []tgbotapi.InlineKeyboardButton
+	for _, lang := range h.translator.Languages() {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(h.translator.Name(lang), "language:"+lang))
+	}
+
+	reply := tgbotapi.NewMessage(c.ChatID(), c.T("language.choose"))
+	reply.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(row)
+
+	_, err := c.Send(reply)
+	return err
+}
+
+func (h *coreHandlers) setLanguage(c *Context) error {
+	lang := c.CallbackData()
+	sender := c.Sender()
+	if sender == nil || !h.translator.Supports(lang) {
+		return c.AnswerCallback("")
+	}
+
+	user := c.CurrentUser()
+	if user == nil {
+		var err error
+		if user, err = h.userService.EnsureTelegramUser(c, sender.ID, sender.UserName); err != nil {
+			return err
+		}
+	}
+
+	if _, err := h.userService.UpdateUser(c, user.ID, domain.UpdateUserRequest{Language: &lang}); err != nil {
+		return err
+	}
+
+	c.lang = lang
+	if err := c.AnswerCallback(""); err != nil {
+		return err
+	}
+	return c.ReplyHTML(c.T("language.changed", h.translator.Name(lang)))
+}
+
+// chatMember tracks whether the bot can message the user. Blocking the bot
+// arrives as the bot being kicked from the private chat, unblocking or
+// restarting it as the bot becoming a member again.
+func (h *coreHandlers) chatMember(c *Context) error {
+	update := c.Update.MyChatMember
+	if !update.Chat.IsPrivate() {
+		return nil
+	}
+
+	var status string
+	switch update.NewChatMember.Status {
+	case "member":
+		status = domain.BotStatusStarted
+	case "kicked", "left":
+		status = domain.BotStatusBlocked
+	default:
+		return nil
+	}
+
+	return h.userService.UpdateBotChat(c, update.From.ID, update.Chat.ID, status)
+}
+
+func (h *coreHandlers) fallback(c *Context) error {
+	return h.replyWithApp(c, c.T("default.text"), "")
+}
+
+// replyWithApp sends an HTML message with a button that opens the mini app,
+// optionally with a deep link. web_app buttons only work in private chats, so
+// groups get a t.me link to the bot's main mini app instead.
+func (h *coreHandlers) replyWithApp(c *Context, text, startParam string) error {
+	reply := tgbotapi.NewMessage(c.ChatID(), text)
+	reply.ParseMode = tgbotapi.ModeHTML
+
+	switch {
+	case h.webAppURL == "":
+	case isPrivate(c):
+		reply.ReplyMarkup = InlineKeyboardMarkup{
+			InlineKeyboard: [][]InlineButton{{NewWebAppButton(c.T("default.open_app"), deeplink.WebAppURL(h.webAppURL, startParam))}},
+		}
+	default:
+		reply.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonURL(c.T("default.open_app"), c.Bot.MiniAppLink(startParam)),
+		))
+	}
+
+	_, err := c.Send(reply)
+	return err
+}
+
+func isPrivate(c *Context) bool {
+	msg := c.Message()
+	return msg != nil && msg.Chat != nil && msg.Chat.IsPrivate()
+}
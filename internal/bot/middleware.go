@@ -0,0 +1,183 @@
+package bot
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/merdernoty/job-hunter/internal/users/domain"
+	"github.com/merdernoty/job-hunter/pkg/i18n"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+	"golang.org/x/time/rate"
+)
+
+const userContextKey = "user"
+
+// Logging logs every update together with the time it took to handle.
+func Logging(logger logger.Logger) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			start := time.Now()
+			err := next(c)
+
+			username := ""
+			if sender := c.Sender(); sender != nil {
+				username = sender.UserName
+			}
+
+			text := ""
+			if msg := c.Update.Message; msg != nil {
+				text = msg.Text
+			} else if cb := c.Update.CallbackQuery; cb != nil {
+				text = cb.Data
+			}
+
+			if err != nil {
+				logger.FromContext(c).Errorf("Failed to handle %s from %s (%q) in %v: %v", c.UpdateType(), username, text, time.Since(start), err)
+				return err
+			}
+
+			logger.FromContext(c).Infof("Handled %s from %s (%q) in %v", c.UpdateType(), username, text, time.Since(start))
+			return nil
+		}
+	}
+}
+
+// Recover turns a panicking handler into an error so one bad update does not
+// take down the updates loop.
+func Recover(logger logger.Logger) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.FromContext(c).Errorf("Bot handler panic: %v\n%s", r, debug.Stack())
+					err = fmt.Errorf("bot handler panic: %v", r)
+				}
+			}()
+			return next(c)
+		}
+	}
+}
+
+// RateLimit allows each chat at most burst updates at once, refilled at
+// perSecond. Updates over the limit are dropped silently.
+func RateLimit(perSecond float64, burst int, logger logger.Logger) MiddlewareFunc {
+	var (
+		mu       sync.Mutex
+		limiters = make(map[int64]*chatLimiter)
+		lastGC   = time.Now()
+	)
+
+	allow := func(chatID int64) bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		now := time.Now()
+		if now.Sub(lastGC) > time.Minute {
+			for id, l := range limiters {
+				if now.Sub(l.lastSeen) > 10*time.Minute {
+					delete(limiters, id)
+				}
+			}
+			lastGC = now
+		}
+
+		l, ok := limiters[chatID]
+		if !ok {
+			l = &chatLimiter{limiter: rate.NewLimiter(rate.Limit(perSecond), burst)}
+			limiters[chatID] = l
+		}
+		l.lastSeen = now
+		return l.limiter.Allow()
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			chatID := c.ChatID()
+			if chatID != 0 && !allow(chatID) {
+				logger.FromContext(c).Warnf("Rate limit exceeded for chat %d, dropping %s", chatID, c.UpdateType())
+				return c.AnswerCallback("")
+			}
+			return next(c)
+		}
+	}
+}
+
+type chatLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// lastSeenResolution limits how often bot activity is written to last_seen_at.
+const lastSeenResolution = time.Hour
+
+// UserLookup loads the Job Hunter user behind the sender, if there is one,
+// so handlers can read it with CurrentUser. It also records the activity and
+// links the private chat the user writes from.
+func UserLookup(userService domain.UserService, logger logger.Logger) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if sender := c.Sender(); sender != nil {
+				if user, err := userService.GetUserByTelegramID(c, sender.ID); err == nil {
+					c.Set(userContextKey, user)
+					c.WithFields("user_id", user.ID.String())
+
+					// Membership changes include the user blocking the bot,
+					// which is neither activity nor proof the chat is open.
+					if c.UpdateType() != UpdateMyChatMember {
+						recordActivity(c, userService, user, logger)
+					}
+				}
+			}
+			return next(c)
+		}
+	}
+}
+
+func recordActivity(c *Context, userService domain.UserService, user *domain.User, logger logger.Logger) {
+	if time.Since(user.LastSeenAt) > lastSeenResolution {
+		if err := userService.TouchLastSeen(c, user.ID); err != nil {
+			logger.FromContext(c).Warnf("Failed to record activity for user %s: %v", user.ID, err)
+		}
+	}
+
+	chatID := c.ChatID()
+	if !isPrivate(c) || (user.CanMessage() && *user.BotChatID == chatID) {
+		return
+	}
+
+	if err := userService.UpdateBotChat(c, user.TelegramID, chatID, domain.BotStatusStarted); err != nil {
+		logger.FromContext(c).Warnf("Failed to link chat %d to user %s: %v", chatID, user.ID, err)
+		return
+	}
+	user.BotChatID = &chatID
+	user.BotStatus = domain.BotStatusStarted
+}
+
+// CurrentUser returns the user loaded by UserLookup, or nil when the sender
+// has neither started the bot nor opened the web app yet.
+func (c *Context) CurrentUser() *domain.User {
+	user, _ := c.Get(userContextKey).(*domain.User)
+	return user
+}
+
+// Localize picks the language for the update: the user's saved preference,
+// then the Telegram client's language_code. Must run after UserLookup.
+func Localize(translator *i18n.Translator) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			var saved, client string
+			if user := c.CurrentUser(); user != nil && user.Language != nil {
+				saved = *user.Language
+			}
+			if sender := c.Sender(); sender != nil {
+				client = sender.LanguageCode
+			}
+
+			c.translator = translator
+			c.lang = translator.Resolve(saved, client)
+			return next(c)
+		}
+	}
+}
@@ -3,12 +3,16 @@ package bot
 import (
 	"context"
 
+	"github.com/merdernoty/job-hunter/app"
 	"github.com/merdernoty/job-hunter/config"
+	"github.com/merdernoty/job-hunter/internal/users/domain"
+	"github.com/merdernoty/job-hunter/pkg/deeplink"
+	"github.com/merdernoty/job-hunter/pkg/i18n"
 	"github.com/merdernoty/job-hunter/pkg/logger"
 	"go.uber.org/fx"
 )
 
-func NewBotFromConfig(cfg *config.Config, logger logger.Logger) (*Bot, error) {
+func NewBotFromConfig(cfg *config.Config, router *Router, logger logger.Logger) (*Bot, error) {
 	if cfg.Bot.Token == "" {
 		logger.Warn("Bot token is not configured, skipping bot initialization")
 		return nil, nil
@@ -18,11 +22,89 @@ func NewBotFromConfig(cfg *config.Config, logger logger.Logger) (*Bot, error) {
 		logger.Warn("WebApp URL is not configured")
 	}
 
-	return NewBot(cfg.Bot.Token, cfg.Bot.WebAppURL, logger)
+	return NewBot(cfg.Bot, router, logger)
+}
+
+// RegisterHandlers installs the middleware chain and the core commands, then
+// the handlers other modules contribute to the "bot_handlers" group.
+func RegisterHandlers(
+	router *Router,
+	cfg *config.Config,
+	userService domain.UserService,
+	translator *i18n.Translator,
+	deepLinks *deeplink.Codec,
+	logger logger.Logger,
+	handlers []Handlers,
+) {
+	router.Use(
+		Recover(logger),
+		Logging(logger),
+		RateLimit(cfg.Bot.RateLimit, cfg.Bot.RateBurst, logger),
+		UserLookup(userService, logger),
+		Localize(translator),
+	)
+
+	core := &coreHandlers{
+		router:      router,
+		translator:  translator,
+		userService: userService,
+		deepLinks:   deepLinks,
+		webAppURL:   cfg.Bot.WebAppURL,
+		logger:      logger,
+	}
+	core.Register(router)
+
+	for _, h := range handlers {
+		h.Register(router)
+	}
+}
+
+// RegisterWebhookRoute mounts the update endpoint on the REST server when the
+// bot runs in webhook mode.
+func RegisterWebhookRoute(s *app.Server, bot *Bot, logger logger.Logger) {
+	if bot == nil || bot.Mode() != ModeWebhook {
+		return
+	}
+
+	s.Echo().POST(bot.WebhookPath(), bot.WebhookHandler())
+	logger.Infof("Telegram webhook endpoint mounted at %s", bot.WebhookPath())
+}
+
+// PublishBotUI sets the chat menu button and the localized command list on
+// startup. Failures are logged: the bot still works with stale settings.
+func PublishBotUI(lc fx.Lifecycle, bot *Bot, translator *i18n.Translator, logger logger.Logger) {
+	if bot == nil {
+		return
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			if bot.WebAppURL() != "" {
+				if err := bot.SetMenuButton(translator.T(i18n.DefaultLanguage, "menu.button")); err != nil {
+					logger.Errorf("Failed to set bot menu button: %v", err)
+				}
+			}
+
+			if err := bot.PublishCommands(translator); err != nil {
+				logger.Errorf("Failed to publish bot commands: %v", err)
+			} else {
+				logger.Infof("Bot commands published for languages: %v", translator.Languages())
+			}
+			return nil
+		},
+	})
 }
 
 var Module = fx.Module("bot",
-	fx.Provide(NewBotFromConfig),
+	fx.Provide(NewRouter, NewBotFromConfig),
+	fx.Invoke(
+		fx.Annotate(
+			RegisterHandlers,
+			fx.ParamTags(``, ``, ``, ``, ``, ``, `group:"bot_handlers"`),
+		),
+	),
+	fx.Invoke(RegisterWebhookRoute),
+	fx.Invoke(PublishBotUI),
 	fx.Invoke(func(lc fx.Lifecycle, bot *Bot, logger logger.Logger) {
 		if bot == nil {
 			logger.Info("Bot is not configured, skipping startup")
@@ -31,6 +113,22 @@ var Module = fx.Module("bot",
 
 		logger.Info("Bot configured successfully, setting up lifecycle hooks")
 
+		if bot.Mode() == ModeWebhook {
+			lc.Append(fx.Hook{
+				OnStart: func(ctx context.Context) error {
+					logger.Info("Registering Telegram webhook...")
+					return bot.SetWebhook()
+				},
+				OnStop: func(ctx context.Context) error {
+					if err := bot.DeleteWebhook(); err != nil {
+						logger.Errorf("Failed to delete Telegram webhook: %v", err)
+					}
+					return nil
+				},
+			})
+			return
+		}
+
 		lc.Append(fx.Hook{
 			OnStart: func(ctx context.Context) error {
 				logger.Info("Starting Telegram bot lifecycle hook...")
@@ -0,0 +1,75 @@
+package bot
+
+import (
+	"encoding/json"
+	"strconv"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// The Bot API library predates Telegram Stars, so createInvoiceLink and
+// refundStarPayment are called directly.
+
+// CreateInvoiceLink returns a link to an invoice that the mini app can open
+// with Telegram.WebApp.openInvoice. An empty provider token means Stars.
+func (b *Bot) CreateInvoiceLink(invoice tgbotapi.InvoiceConfig) (string, error) {
+	prices, err := json.Marshal(invoice.Prices)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := b.api.MakeRequest("createInvoiceLink", tgbotapi.Params{
+		"title":          invoice.Title,
+		"description":    invoice.Description,
+		"payload":        invoice.Payload,
+		"provider_token": invoice.ProviderToken,
+		"currency":       invoice.Currency,
+		"prices":         string(prices),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var link string
+	if err := json.Unmarshal(resp.Result, &link); err != nil {
+		return "", err
+	}
+	return link, nil
+}
+
+// RefundStarPayment returns the Stars of a successful payment to the user.
+func (b *Bot) RefundStarPayment(userID int64, telegramChargeID string) error {
+	_, err := b.api.MakeRequest("refundStarPayment", tgbotapi.Params{
+		"user_id":                    strconv.FormatInt(userID, 10),
+		"telegram_payment_charge_id": telegramChargeID,
+	})
+	return err
+}
+
+// RefundedPayment is the refunded_payment service message Telegram sends when
+// a Stars payment is refunded, including by Telegram support.
+type RefundedPayment struct {
+	Currency                string `json:"currency"`
+	TotalAmount             int    `json:"total_amount"`
+	InvoicePayload          string `json:"invoice_payload"`
+	TelegramPaymentChargeID string `json:"telegram_payment_charge_id"`
+	ProviderPaymentChargeID string `json:"provider_payment_charge_id,omitempty"`
+}
+
+// RefundedPayment decodes the refunded_payment of the update's message, nil
+// when it has none.
+func (c *Context) RefundedPayment() *RefundedPayment {
+	if c.Update.Message == nil || len(c.Raw) == 0 {
+		return nil
+	}
+
+	var update struct {
+		Message struct {
+			RefundedPayment *RefundedPayment `json:"refunded_payment"`
+		} `json:"message"`
+	}
+	if err := json.Unmarshal(c.Raw, &update); err != nil {
+		return nil
+	}
+	return update.Message.RefundedPayment
+}
@@ -0,0 +1,370 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/merdernoty/job-hunter/pkg/i18n"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+)
+
+// ErrSkip is returned by message and update handlers that do not apply to
+// the update, so that the router tries the next one.
+var ErrSkip = errors.New("bot: handler skipped")
+
+const (
+	UpdateMessage          = "message"
+	UpdateCallbackQuery    = "callback_query"
+	UpdateInlineQuery      = "inline_query"
+	UpdateMyChatMember     = "my_chat_member"
+	UpdatePreCheckoutQuery = "pre_checkout_query"
+	UpdateOther            = "other"
+)
+
+type HandlerFunc func(c *Context) error
+
+type MiddlewareFunc func(next HandlerFunc) HandlerFunc
+
+type Command struct {
+	Name        string
+	Description string
+	Hidden      bool
+	Handler     HandlerFunc
+}
+
+type callbackRoute struct {
+	prefix  string
+	handler HandlerFunc
+}
+
+// Handlers is implemented by fx modules that contribute bot handlers. They
+// are collected from the "bot_handlers" group and registered on startup.
+type Handlers interface {
+	Register(r *Router)
+}
+
+// Router dispatches updates to command, callback-query, message and generic
+// update handlers, running every handler through the middleware chain.
+type Router struct {
+	mu         sync.RWMutex
+	commands   map[string]Command
+	order      []string
+	callbacks  []callbackRoute
+	messages   []HandlerFunc
+	updates    map[string][]HandlerFunc
+	middleware []MiddlewareFunc
+	fallback   HandlerFunc
+}
+
+func NewRouter() *Router {
+	return &Router{
+		commands: make(map[string]Command),
+		updates:  make(map[string][]HandlerFunc),
+	}
+}
+
+func (r *Router) Use(middleware ...MiddlewareFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.middleware = append(r.middleware, middleware...)
+}
+
+// Command registers a /command. The description is a message catalog key
+// shown in /help.
+func (r *Router) Command(name, description string, handler HandlerFunc) {
+	r.addCommand(Command{Name: name, Description: description, Handler: handler})
+}
+
+// HiddenCommand registers a /command that is not listed in /help.
+func (r *Router) HiddenCommand(name string, handler HandlerFunc) {
+	r.addCommand(Command{Name: name, Hidden: true, Handler: handler})
+}
+
+func (r *Router) addCommand(cmd Command) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.commands[cmd.Name]; !exists {
+		r.order = append(r.order, cmd.Name)
+	}
+	r.commands[cmd.Name] = cmd
+}
+
+// Callback registers a handler for callback queries whose data starts with
+// prefix followed by ":" (or equals prefix).
+func (r *Router) Callback(prefix string, handler HandlerFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.callbacks = append(r.callbacks, callbackRoute{prefix: prefix, handler: handler})
+}
+
+// Message registers a handler for non-command messages. Handlers are tried in
+// registration order until one does not return ErrSkip.
+func (r *Router) Message(handler HandlerFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, handler)
+}
+
+// On registers a handler for another update type, e.g. UpdateInlineQuery.
+func (r *Router) On(updateType string, handler HandlerFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.updates[updateType] = append(r.updates[updateType], handler)
+}
+
+// Fallback handles text messages nothing else claimed and unknown commands.
+func (r *Router) Fallback(handler HandlerFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fallback = handler
+}
+
+// Commands returns registered commands in registration order.
+func (r *Router) Commands() []Command {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	commands := make([]Command, 0, len(r.order))
+	for _, name := range r.order {
+		commands = append(commands, r.commands[name])
+	}
+	return commands
+}
+
+func (r *Router) Dispatch(c *Context) error {
+	r.mu.RLock()
+	middleware := r.middleware
+	r.mu.RUnlock()
+
+	handler := r.route
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+
+	return handler(c)
+}
+
+func (r *Router) route(c *Context) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	switch c.UpdateType() {
+	case UpdateMessage:
+		msg := c.Update.Message
+		if msg.IsCommand() {
+			if cmd, ok := r.commands[msg.Command()]; ok {
+				return cmd.Handler(c)
+			}
+			return r.runFallback(c)
+		}
+
+		if err := runChain(c, r.messages); !errors.Is(err, ErrSkip) {
+			return err
+		}
+		if msg.Text != "" {
+			return r.runFallback(c)
+		}
+		return nil
+
+	case UpdateCallbackQuery:
+		data := c.Update.CallbackQuery.Data
+		for _, route := range r.callbacks {
+			if data == route.prefix || strings.HasPrefix(data, route.prefix+":") {
+				return route.handler(c)
+			}
+		}
+		// Buttons outlive the handlers that sent them. Answer anyway, or the
+		// client keeps showing a spinner on the button.
+		return c.AnswerCallback("")
+
+	default:
+		err := runChain(c, r.updates[c.UpdateType()])
+		if errors.Is(err, ErrSkip) {
+			return nil
+		}
+		return err
+	}
+}
+
+func (r *Router) runFallback(c *Context) error {
+	if r.fallback == nil {
+		return nil
+	}
+	return r.fallback(c)
+}
+
+func runChain(c *Context, handlers []HandlerFunc) error {
+	for _, handler := range handlers {
+		if err := handler(c); !errors.Is(err, ErrSkip) {
+			return err
+		}
+	}
+	return ErrSkip
+}
+
+// Context carries a single update through middleware and handlers.
+type Context struct {
+	context.Context
+	Bot    *Bot
+	Update tgbotapi.Update
+	// Raw is the update as Telegram sent it, for fields the library does not
+	// decode.
+	Raw json.RawMessage
+
+	mu     sync.RWMutex
+	values map[string]interface{}
+
+	lang       string
+	translator *i18n.Translator
+}
+
+func newContext(ctx context.Context, bot *Bot, update tgbotapi.Update, raw json.RawMessage) *Context {
+	return &Context{
+		Context: ctx,
+		Bot:     bot,
+		Update:  update,
+		Raw:     raw,
+		values:  make(map[string]interface{}),
+	}
+}
+
+// WithFields attaches key-value pairs to the update's context. Loggers
+// obtained through FromContext(c), here and in the services the handlers
+// call, include them in every entry.
+func (c *Context) WithFields(fields ...interface{}) {
+	c.Context = logger.ContextWith(c.Context, fields...)
+}
+
+// Lang returns the language the update is answered in, as chosen by the
+// Localize middleware.
+func (c *Context) Lang() string {
+	if c.lang == "" {
+		return i18n.DefaultLanguage
+	}
+	return c.lang
+}
+
+// T localizes key into the update's language.
+func (c *Context) T(key string, args ...interface{}) string {
+	if c.translator == nil {
+		return key
+	}
+	return c.translator.T(c.Lang(), key, args...)
+}
+
+func (c *Context) UpdateType() string {
+	switch {
+	case c.Update.Message != nil:
+		return UpdateMessage
+	case c.Update.CallbackQuery != nil:
+		return UpdateCallbackQuery
+	case c.Update.InlineQuery != nil:
+		return UpdateInlineQuery
+	case c.Update.MyChatMember != nil:
+		return UpdateMyChatMember
+	case c.Update.PreCheckoutQuery != nil:
+		return UpdatePreCheckoutQuery
+	default:
+		return UpdateOther
+	}
+}
+
+func (c *Context) Set(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[key] = value
+}
+
+func (c *Context) Get(key string) interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.values[key]
+}
+
+// Message returns the message of the update, or the message a callback
+// button was attached to.
+func (c *Context) Message() *tgbotapi.Message {
+	if c.Update.Message != nil {
+		return c.Update.Message
+	}
+	if c.Update.CallbackQuery != nil {
+		return c.Update.CallbackQuery.Message
+	}
+	return nil
+}
+
+func (c *Context) Sender() *tgbotapi.User {
+	if c.Update.MyChatMember != nil {
+		return &c.Update.MyChatMember.From
+	}
+	return c.Update.SentFrom()
+}
+
+// ChatID returns the chat the update came from, or 0 for chat-less updates
+// such as inline queries.
+func (c *Context) ChatID() int64 {
+	switch {
+	case c.Update.CallbackQuery != nil:
+		if c.Update.CallbackQuery.Message != nil {
+			return c.Update.CallbackQuery.Message.Chat.ID
+		}
+		return 0
+	case c.Update.MyChatMember != nil:
+		return c.Update.MyChatMember.Chat.ID
+	}
+
+	if chat := c.Update.FromChat(); chat != nil {
+		return chat.ID
+	}
+	return 0
+}
+
+// Args returns the command arguments of a command message.
+func (c *Context) Args() string {
+	if c.Update.Message == nil {
+		return ""
+	}
+	return strings.TrimSpace(c.Update.Message.CommandArguments())
+}
+
+// CallbackData returns the callback data without its route prefix.
+func (c *Context) CallbackData() string {
+	if c.Update.CallbackQuery == nil {
+		return ""
+	}
+	data := c.Update.CallbackQuery.Data
+	if i := strings.Index(data, ":"); i >= 0 {
+		return data[i+1:]
+	}
+	return ""
+}
+
+func (c *Context) Send(chattable tgbotapi.Chattable) (tgbotapi.Message, error) {
+	return c.Bot.api.Send(chattable)
+}
+
+func (c *Context) Request(chattable tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	return c.Bot.api.Request(chattable)
+}
+
+// ReplyHTML sends an HTML formatted message to the chat of the update.
+func (c *Context) ReplyHTML(text string) error {
+	reply := tgbotapi.NewMessage(c.ChatID(), text)
+	reply.ParseMode = tgbotapi.ModeHTML
+	_, err := c.Send(reply)
+	return err
+}
+
+// AnswerCallback acknowledges the callback query, optionally with a toast.
+func (c *Context) AnswerCallback(text string) error {
+	if c.Update.CallbackQuery == nil {
+		return nil
+	}
+	_, err := c.Request(tgbotapi.NewCallback(c.Update.CallbackQuery.ID, text))
+	return err
+}
@@ -0,0 +1,130 @@
+package bot
+
+import (
+	"encoding/json"
+	"fmt"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/merdernoty/job-hunter/pkg/i18n"
+)
+
+// The Bot API library predates mini apps, so the web_app button fields and
+// setChatMenuButton are declared here.
+
+type WebAppInfo struct {
+	URL string `json:"url"`
+}
+
+// InlineButton is an inline keyboard button that may open a mini app.
+type InlineButton struct {
+	tgbotapi.InlineKeyboardButton
+	WebApp *WebAppInfo `json:"web_app,omitempty"`
+}
+
+type InlineKeyboardMarkup struct {
+	InlineKeyboard [][]InlineButton `json:"inline_keyboard"`
+}
+
+// KeyboardButton is a reply keyboard button that may open a mini app.
+type KeyboardButton struct {
+	tgbotapi.KeyboardButton
+	WebApp *WebAppInfo `json:"web_app,omitempty"`
+}
+
+type ReplyKeyboardMarkup struct {
+	Keyboard       [][]KeyboardButton `json:"keyboard"`
+	ResizeKeyboard bool               `json:"resize_keyboard,omitempty"`
+	IsPersistent   bool               `json:"is_persistent,omitempty"`
+}
+
+type menuButton struct {
+	Type   string      `json:"type"`
+	Text   string      `json:"text,omitempty"`
+	WebApp *WebAppInfo `json:"web_app,omitempty"`
+}
+
+// NewWebAppButton returns an inline button that opens url as a mini app, so
+// the web app receives initData.
+func NewWebAppButton(text, url string) InlineButton {
+	return InlineButton{
+		InlineKeyboardButton: tgbotapi.InlineKeyboardButton{Text: text},
+		WebApp:               &WebAppInfo{URL: url},
+	}
+}
+
+// NewWebAppKeyboard returns a persistent reply keyboard with a single button
+// that opens url as a mini app.
+func NewWebAppKeyboard(text, url string) ReplyKeyboardMarkup {
+	return ReplyKeyboardMarkup{
+		Keyboard: [][]KeyboardButton{{{
+			KeyboardButton: tgbotapi.KeyboardButton{Text: text},
+			WebApp:         &WebAppInfo{URL: url},
+		}}},
+		ResizeKeyboard: true,
+		IsPersistent:   true,
+	}
+}
+
+// WebAppURL returns the configured mini app URL.
+func (b *Bot) WebAppURL() string {
+	return b.webAppURL
+}
+
+// SetMenuButton makes the chat menu button open the mini app in every private
+// chat with the bot.
+func (b *Bot) SetMenuButton(text string) error {
+	if b.webAppURL == "" {
+		return fmt.Errorf("web app URL is not configured")
+	}
+
+	button, err := json.Marshal(menuButton{
+		Type:   "web_app",
+		Text:   text,
+		WebApp: &WebAppInfo{URL: b.webAppURL},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = b.api.MakeRequest("setChatMenuButton", tgbotapi.Params{"menu_button": string(button)})
+	return err
+}
+
+// PublishCommands registers the visible router commands with Telegram once
+// per catalog language, plus a default list for all other languages.
+func (b *Bot) PublishCommands(translator *i18n.Translator) error {
+	if b.router == nil {
+		return nil
+	}
+
+	if err := b.setCommands(translator, i18n.DefaultLanguage, ""); err != nil {
+		return err
+	}
+
+	for _, lang := range translator.Languages() {
+		if err := b.setCommands(translator, lang, lang); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *Bot) setCommands(translator *i18n.Translator, lang, languageCode string) error {
+	var commands []tgbotapi.BotCommand
+	for _, cmd := range b.router.Commands() {
+		if cmd.Hidden {
+			continue
+		}
+		commands = append(commands, tgbotapi.BotCommand{
+			Command:     cmd.Name,
+			Description: translator.T(lang, cmd.Description),
+		})
+	}
+
+	config := tgbotapi.NewSetMyCommands(commands...)
+	config.LanguageCode = languageCode
+	if _, err := b.api.Request(config); err != nil {
+		return fmt.Errorf("failed to set commands for %q: %w", languageCode, err)
+	}
+	return nil
+}
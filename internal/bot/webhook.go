@@ -0,0 +1,130 @@
+package bot
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/merdernoty/job-hunter/config"
+)
+
+const secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// Telegram only accepts 1-256 characters from this set as a secret token.
+var secretTokenPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
+type webhookSettings struct {
+	url          string
+	path         string
+	secret       string
+	deleteOnStop bool
+}
+
+func newWebhookSettings(cfg config.BotConfig) (webhookSettings, error) {
+	settings := webhookSettings{
+		path:         cfg.WebhookPath,
+		secret:       cfg.WebhookSecret,
+		deleteOnStop: cfg.WebhookDeleteOnStop,
+	}
+	if settings.path == "" {
+		settings.path = "/api/telegram/webhook"
+	}
+
+	if cfg.WebhookURL == "" {
+		return settings, fmt.Errorf("webhook mode requires bot.webhookurl")
+	}
+	if !secretTokenPattern.MatchString(settings.secret) {
+		return settings, fmt.Errorf("webhook mode requires bot.webhooksecret of 1-256 characters [A-Za-z0-9_-]")
+	}
+
+	base, err := url.Parse(cfg.WebhookURL)
+	if err != nil || base.Scheme != "https" {
+		return settings, fmt.Errorf("bot.webhookurl must be an https URL")
+	}
+	base.Path = strings.TrimSuffix(base.Path, "/") + settings.path
+	settings.url = base.String()
+
+	return settings, nil
+}
+
+func (b *Bot) WebhookPath() string {
+	return b.webhook.path
+}
+
+// SetWebhook registers the update endpoint together with the secret token
+// Telegram must echo back in every request.
+func (b *Bot) SetWebhook() error {
+	params := tgbotapi.Params{}
+	params["url"] = b.webhook.url
+	params["secret_token"] = b.webhook.secret
+
+	resp, err := b.api.MakeRequest("setWebhook", params)
+	if err != nil {
+		return fmt.Errorf("failed to set webhook: %w", err)
+	}
+	if !resp.Ok {
+		return fmt.Errorf("failed to set webhook: %s", resp.Description)
+	}
+
+	b.logger.Infof("Telegram webhook registered at %s", b.webhook.url)
+	return nil
+}
+
+// DeleteWebhook unregisters the webhook when bot.webhookdeleteonstop is set,
+// which only a single instance deployment should do.
+func (b *Bot) DeleteWebhook() error {
+	if !b.webhook.deleteOnStop {
+		return nil
+	}
+
+	if _, err := b.api.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	b.logger.Info("Telegram webhook deleted")
+	return nil
+}
+
+// WebhookHandler receives updates pushed by Telegram and feeds them into the
+// same handling path as long polling.
+func (b *Bot) WebhookHandler() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		token := c.Request().Header.Get(secretTokenHeader)
+		if subtle.ConstantTimeCompare([]byte(token), []byte(b.webhook.secret)) != 1 {
+			b.logger.FromContext(c.Request().Context()).Warnf("Rejected webhook request with invalid secret token from %s", c.RealIP())
+			return c.NoContent(http.StatusUnauthorized)
+		}
+
+		raw, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			b.logger.FromContext(c.Request().Context()).Warnf("Failed to read webhook update: %v", err)
+			return c.NoContent(http.StatusBadRequest)
+		}
+
+		var update tgbotapi.Update
+		if err := json.Unmarshal(raw, &update); err != nil {
+			b.logger.FromContext(c.Request().Context()).Warnf("Failed to decode webhook update: %v", err)
+			return c.NoContent(http.StatusBadRequest)
+		}
+
+		b.processUpdate(update, raw)
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+func (b *Bot) processUpdate(update tgbotapi.Update, raw json.RawMessage) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.logger.Errorf("Bot update %d handler panic: %v", update.UpdateID, r)
+		}
+	}()
+
+	b.handleUpdate(update, raw)
+}
@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/merdernoty/job-hunter/internal/broadcasts/domain"
+	"github.com/merdernoty/job-hunter/internal/users/middleware"
+	httpResponse "github.com/merdernoty/job-hunter/pkg/http"
+	"github.com/merdernoty/job-hunter/pkg/i18n"
+)
+
+const defaultListLimit = 50
+
+type BroadcastController struct {
+	broadcastService domain.BroadcastService
+}
+
+func NewBroadcastController(broadcastService domain.BroadcastService) *BroadcastController {
+	return &BroadcastController{broadcastService: broadcastService}
+}
+
+func (ctrl *BroadcastController) RegisterRoutes(rg *echo.Group, jwtMiddleware, adminMiddleware echo.MiddlewareFunc) {
+	broadcasts := rg.Group("/admin/broadcasts", jwtMiddleware, adminMiddleware)
+	broadcasts.POST("", ctrl.preview)
+	broadcasts.GET("", ctrl.list)
+	broadcasts.GET("/:id", ctrl.report)
+	broadcasts.GET("/:id/recipients", ctrl.recipients)
+	broadcasts.POST("/:id/confirm", ctrl.confirm)
+	broadcasts.POST("/:id/cancel", ctrl.cancel)
+}
+
+func (ctrl *BroadcastController) preview(c echo.Context) error {
+	var req domain.CreateBroadcastRequest
+	if err := httpResponse.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	var createdBy *uuid.UUID
+	if userID, ok := middleware.GetUserID(c); ok {
+		createdBy = &userID
+	}
+
+	broadcast, err := ctrl.broadcastService.Preview(c.Request().Context(), createdBy, req)
+	if err != nil {
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.broadcasts.create_failed"))
+	}
+
+	return httpResponse.CreatedResponse(c, broadcast, i18n.Message(c, "api.broadcasts.created"))
+}
+
+func (ctrl *BroadcastController) list(c echo.Context) error {
+	broadcasts, err := ctrl.broadcastService.List(c.Request().Context(), defaultListLimit)
+	if err != nil {
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.broadcasts.list_failed"))
+	}
+
+	return httpResponse.SuccessResponse(c, broadcasts)
+}
+
+func (ctrl *BroadcastController) report(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return httpResponse.BadRequestResponse(c, i18n.Message(c, "api.broadcasts.invalid_id"))
+	}
+
+	report, err := ctrl.broadcastService.GetReport(c.Request().Context(), id)
+	if err != nil {
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.broadcasts.process_failed"))
+	}
+
+	return httpResponse.SuccessResponse(c, report)
+}
+
+func (ctrl *BroadcastController) recipients(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return httpResponse.BadRequestResponse(c, i18n.Message(c, "api.broadcasts.invalid_id"))
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+
+	recipients, err := ctrl.broadcastService.Recipients(c.Request().Context(), id, limit, offset)
+	if err != nil {
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.broadcasts.process_failed"))
+	}
+
+	return httpResponse.SuccessResponse(c, recipients)
+}
+
+func (ctrl *BroadcastController) confirm(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return httpResponse.BadRequestResponse(c, i18n.Message(c, "api.broadcasts.invalid_id"))
+	}
+
+	broadcast, err := ctrl.broadcastService.Confirm(c.Request().Context(), id)
+	if errors.Is(err, domain.ErrEmptyAudience) {
+		return httpResponse.SuccessResponse(c, broadcast, i18n.Message(c, "api.broadcasts.empty"))
+	}
+	if err != nil {
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.broadcasts.process_failed"))
+	}
+
+	return httpResponse.SuccessResponse(c, broadcast, i18n.Message(c, "api.broadcasts.queued"))
+}
+
+func (ctrl *BroadcastController) cancel(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return httpResponse.BadRequestResponse(c, i18n.Message(c, "api.broadcasts.invalid_id"))
+	}
+
+	if err := ctrl.broadcastService.Cancel(c.Request().Context(), id); err != nil {
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.broadcasts.process_failed"))
+	}
+
+	return httpResponse.SuccessResponse(c, nil, i18n.Message(c, "api.broadcasts.cancelled"))
+}
@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	notificationDomain "github.com/merdernoty/job-hunter/internal/notifications/domain"
+	"github.com/merdernoty/job-hunter/pkg/apperr"
+)
+
+type BroadcastStatus string
+
+const (
+	BroadcastStatusDraft     BroadcastStatus = "draft"
+	BroadcastStatusSending   BroadcastStatus = "sending"
+	BroadcastStatusCancelled BroadcastStatus = "cancelled"
+)
+
+const (
+	SegmentAll      = "all"
+	SegmentInactive = "inactive"
+	SegmentSkill    = "skill"
+	SegmentLanguage = "language"
+)
+
+var (
+	ErrBroadcastNotFound = apperr.NotFound("BROADCAST_NOT_FOUND", "broadcast not found")
+	ErrBroadcastNotDraft = apperr.Conflict("BROADCAST_NOT_DRAFT", "broadcast is no longer a draft")
+	ErrInvalidSegment    = apperr.Validation("INVALID_SEGMENT", "invalid audience segment")
+	ErrEmptyAudience     = apperr.Conflict("EMPTY_AUDIENCE", "audience is empty")
+)
+
+// Segment selects the users a broadcast goes to. Users who blocked the bot
+// are never included.
+type Segment struct {
+	Type string `json:"type" validate:"required,oneof=all inactive skill language"`
+	// Days is the inactivity threshold for the "inactive" segment.
+	Days     int    `json:"days,omitempty" validate:"omitempty,min=1,max=3650"`
+	Skill    string `json:"skill,omitempty" validate:"omitempty,max=30"`
+	Language string `json:"language,omitempty" validate:"omitempty,oneof=ru en"`
+}
+
+func (s Segment) Validate() error {
+	switch s.Type {
+	case SegmentAll:
+		return nil
+	case SegmentInactive:
+		if s.Days < 1 {
+			return ErrInvalidSegment.WithDetails("inactive segment needs days")
+		}
+	case SegmentSkill:
+		if s.Skill == "" {
+			return ErrInvalidSegment.WithDetails("skill segment needs a skill")
+		}
+	case SegmentLanguage:
+		if s.Language == "" {
+			return ErrInvalidSegment.WithDetails("language segment needs a language")
+		}
+	default:
+		return ErrInvalidSegment.WithDetails(fmt.Sprintf("unknown type %q", s.Type))
+	}
+	return nil
+}
+
+func (s Segment) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+func (s *Segment) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, s)
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	default:
+		return fmt.Errorf("cannot scan %T into Segment", src)
+	}
+}
+
+type Broadcast struct {
+	ID           uuid.UUID       `json:"id" db:"id"`
+	CreatedBy    *uuid.UUID      `json:"created_by" db:"created_by"`
+	Text         string          `json:"text" db:"text"`
+	Segment      Segment         `json:"segment" db:"segment"`
+	Status       BroadcastStatus `json:"status" db:"status"`
+	AudienceSize int             `json:"audience_size" db:"audience_size"`
+	CreatedAt    time.Time       `json:"created_at" db:"created_at"`
+	ConfirmedAt  *time.Time      `json:"confirmed_at" db:"confirmed_at"`
+}
+
+// BroadcastStats counts recipients by delivery status.
+type BroadcastStats struct {
+	Pending int `json:"pending"`
+	Sent    int `json:"sent"`
+	Failed  int `json:"failed"`
+}
+
+type BroadcastReport struct {
+	Broadcast *Broadcast     `json:"broadcast"`
+	Stats     BroadcastStats `json:"stats"`
+}
+
+type Recipient struct {
+	UserID    *uuid.UUID                            `json:"user_id" db:"user_id"`
+	ChatID    int64                                 `json:"chat_id" db:"chat_id"`
+	Status    notificationDomain.NotificationStatus `json:"status" db:"status"`
+	Attempts  int                                   `json:"attempts" db:"attempts"`
+	LastError *string                               `json:"last_error" db:"last_error"`
+	SentAt    *time.Time                            `json:"sent_at" db:"sent_at"`
+}
+
+type CreateBroadcastRequest struct {
+	Text    string  `json:"text" validate:"required,max=4000"`
+	Segment Segment `json:"segment"`
+}
+
+type BroadcastRepository interface {
+	Create(ctx context.Context, broadcast *Broadcast) error
+	GetByID(ctx context.Context, id uuid.UUID) (*Broadcast, error)
+	List(ctx context.Context, limit int) ([]Broadcast, error)
+	CountAudience(ctx context.Context, segment Segment) (int, error)
+	// Confirm moves a draft to sending and queues one notification per
+	// recipient in the same transaction.
+	Confirm(ctx context.Context, id uuid.UUID, text string) (*Broadcast, error)
+	Cancel(ctx context.Context, id uuid.UUID) error
+	Stats(ctx context.Context, id uuid.UUID) (BroadcastStats, error)
+	Recipients(ctx context.Context, id uuid.UUID, limit, offset int) ([]Recipient, error)
+}
+
+type BroadcastService interface {
+	// Preview stores a draft and reports how many users it would reach.
+	Preview(ctx context.Context, createdBy *uuid.UUID, req CreateBroadcastRequest) (*Broadcast, error)
+	Confirm(ctx context.Context, id uuid.UUID) (*Broadcast, error)
+	Cancel(ctx context.Context, id uuid.UUID) error
+	GetReport(ctx context.Context, id uuid.UUID) (*BroadcastReport, error)
+	List(ctx context.Context, limit int) ([]Broadcast, error)
+	Recipients(ctx context.Context, id uuid.UUID, limit, offset int) ([]Recipient, error)
+	IsAdmin(telegramID int64) bool
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"errors"
+	"html"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/google/uuid"
+	"github.com/merdernoty/job-hunter/internal/bot"
+	"github.com/merdernoty/job-hunter/internal/broadcasts/domain"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+)
+
+const callbackPrefix = "broadcast"
+
+type BroadcastHandlers struct {
+	broadcastService domain.BroadcastService
+	logger           logger.Logger
+}
+
+func NewBroadcastHandlers(broadcastService domain.BroadcastService, logger logger.Logger) bot.Handlers {
+	return &BroadcastHandlers{
+		broadcastService: broadcastService,
+		logger:           logger,
+	}
+}
+
+func (h *BroadcastHandlers) Register(r *bot.Router) {
+	r.HiddenCommand("broadcast", h.preview)
+	r.Callback(callbackPrefix, h.callback)
+}
+
+// preview handles "/broadcast <segment> <text>" where segment is one of
+// all, inactive:<days>, skill:<name> or lang:<code>.
+func (h *BroadcastHandlers) preview(c *bot.Context) error {
+	if !h.isAdmin(c) {
+		return bot.ErrSkip
+	}
+
+	segmentArg, text, _ := strings.Cut(c.Args(), " ")
+	text = strings.TrimSpace(text)
+	segment, ok := parseSegment(segmentArg)
+	if !ok || text == "" {
+		return c.ReplyHTML(c.T("broadcast.usage"))
+	}
+
+	var createdBy *uuid.UUID
+	if user := c.CurrentUser(); user != nil {
+		createdBy = &user.ID
+	}
+
+	broadcast, err := h.broadcastService.Preview(c, createdBy, domain.CreateBroadcastRequest{Text: text, Segment: segment})
+	if errors.Is(err, domain.ErrInvalidSegment) {
+		return c.ReplyHTML(c.T("broadcast.usage"))
+	}
+	if err != nil {
+		return err
+	}
+
+	reply := tgbotapi.NewMessage(c.ChatID(), c.T(
+		"broadcast.preview",
+		html.EscapeString(segmentArg),
+		broadcast.AudienceSize,
+		html.EscapeString(broadcast.Text),
+	))
+	reply.ParseMode = tgbotapi.ModeHTML
+	reply.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(c.T("broadcast.confirm"), callbackPrefix+":confirm:"+broadcast.ID.String()),
+		tgbotapi.NewInlineKeyboardButtonData(c.T("broadcast.cancel"), callbackPrefix+":cancel:"+broadcast.ID.String()),
+	))
+
+	_, err = c.Send(reply)
+	return err
+}
+
+func (h *BroadcastHandlers) callback(c *bot.Context) error {
+	if !h.isAdmin(c) {
+		return c.AnswerCallback("")
+	}
+
+	action, rawID, _ := strings.Cut(c.CallbackData(), ":")
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		return c.AnswerCallback("")
+	}
+
+	var text string
+	switch action {
+	case "confirm":
+		broadcast, err := h.broadcastService.Confirm(c, id)
+		switch {
+		case errors.Is(err, domain.ErrEmptyAudience):
+			text = c.T("broadcast.empty")
+		case err != nil:
+			return h.answerError(c, err)
+		default:
+			text = c.T("broadcast.queued", broadcast.AudienceSize)
+		}
+	case "cancel":
+		if err := h.broadcastService.Cancel(c, id); err != nil {
+			return h.answerError(c, err)
+		}
+		text = c.T("broadcast.cancelled")
+	default:
+		return c.AnswerCallback("")
+	}
+
+	if err := c.AnswerCallback(""); err != nil {
+		h.logger.FromContext(c).Warnf("Failed to answer callback: %v", err)
+	}
+	h.removeKeyboard(c)
+	return c.ReplyHTML(text)
+}
+
+func (h *BroadcastHandlers) answerError(c *bot.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrBroadcastNotFound), errors.Is(err, domain.ErrBroadcastNotDraft):
+		h.removeKeyboard(c)
+		return c.AnswerCallback(c.T("broadcast.closed"))
+	default:
+		return err
+	}
+}
+
+// removeKeyboard drops the confirm/cancel buttons so a preview cannot be
+// acted on twice.
+func (h *BroadcastHandlers) removeKeyboard(c *bot.Context) {
+	msg := c.Message()
+	if msg == nil {
+		return
+	}
+
+	edit := tgbotapi.NewEditMessageReplyMarkup(msg.Chat.ID, msg.MessageID, tgbotapi.InlineKeyboardMarkup{
+		InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{},
+	})
+	if _, err := c.Request(edit); err != nil {
+		h.logger.FromContext(c).Warnf("Failed to remove broadcast keyboard: %v", err)
+	}
+}
+
+func (h *BroadcastHandlers) isAdmin(c *bot.Context) bool {
+	sender := c.Sender()
+	return sender != nil && h.broadcastService.IsAdmin(sender.ID)
+}
+
+func parseSegment(arg string) (domain.Segment, bool) {
+	kind, value, _ := strings.Cut(arg, ":")
+
+	switch strings.ToLower(kind) {
+	case domain.SegmentAll:
+		return domain.Segment{Type: domain.SegmentAll}, true
+	case domain.SegmentInactive:
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 1 {
+			return domain.Segment{}, false
+		}
+		return domain.Segment{Type: domain.SegmentInactive, Days: days}, true
+	case domain.SegmentSkill:
+		if value == "" {
+			return domain.Segment{}, false
+		}
+		return domain.Segment{Type: domain.SegmentSkill, Skill: value}, true
+	case "lang", domain.SegmentLanguage:
+		if value == "" {
+			return domain.Segment{}, false
+		}
+		return domain.Segment{Type: domain.SegmentLanguage, Language: strings.ToLower(value)}, true
+	default:
+		return domain.Segment{}, false
+	}
+}
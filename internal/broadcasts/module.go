@@ -0,0 +1,28 @@
+package broadcasts
+
+import (
+	"github.com/merdernoty/job-hunter/internal/broadcasts/domain"
+	"github.com/merdernoty/job-hunter/internal/broadcasts/handler"
+	"github.com/merdernoty/job-hunter/internal/broadcasts/repository"
+	"github.com/merdernoty/job-hunter/internal/broadcasts/service"
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module("broadcasts",
+	fx.Provide(
+		fx.Annotate(
+			repository.NewBroadcastRepository,
+			fx.As(new(domain.BroadcastRepository)),
+		),
+		fx.Annotate(
+			service.NewBroadcastService,
+			fx.As(new(domain.BroadcastService)),
+		),
+	),
+	fx.Provide(
+		fx.Annotate(
+			handler.NewBroadcastHandlers,
+			fx.ResultTags(`group:"bot_handlers"`),
+		),
+	),
+)
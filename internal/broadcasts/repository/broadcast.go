@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/merdernoty/job-hunter/internal/broadcasts/domain"
+	notificationDomain "github.com/merdernoty/job-hunter/internal/notifications/domain"
+	"github.com/merdernoty/job-hunter/pkg/i18n"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+)
+
+const broadcastColumns = `id, created_by, text, segment, status, audience_size, created_at, confirmed_at`
+
+type broadcastRepository struct {
+	db     *sqlx.DB
+	logger logger.Logger
+}
+
+func NewBroadcastRepository(db *sqlx.DB, logger logger.Logger) domain.BroadcastRepository {
+	return &broadcastRepository{db: db, logger: logger}
+}
+
+func (r *broadcastRepository) Create(ctx context.Context, broadcast *domain.Broadcast) error {
+	if broadcast.ID == uuid.Nil {
+		broadcast.ID = uuid.New()
+	}
+
+	query := `
+		INSERT INTO broadcasts (id, created_by, text, segment, status, audience_size)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at`
+
+	err := r.db.QueryRowContext(
+		ctx, query,
+		broadcast.ID, broadcast.CreatedBy, broadcast.Text, broadcast.Segment, broadcast.Status, broadcast.AudienceSize,
+	).Scan(&broadcast.CreatedAt)
+	if err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to create broadcast: %v", err)
+		return fmt.Errorf("failed to create broadcast")
+	}
+
+	return nil
+}
+
+func (r *broadcastRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Broadcast, error) {
+	var broadcast domain.Broadcast
+	query := `SELECT ` + broadcastColumns + ` FROM broadcasts WHERE id = $1`
+
+	err := r.db.GetContext(ctx, &broadcast, query, id)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrBroadcastNotFound
+	}
+	if err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to get broadcast %s: %v", id, err)
+		return nil, fmt.Errorf("database error")
+	}
+
+	return &broadcast, nil
+}
+
+func (r *broadcastRepository) List(ctx context.Context, limit int) ([]domain.Broadcast, error) {
+	broadcasts := []domain.Broadcast{}
+	query := `SELECT ` + broadcastColumns + ` FROM broadcasts ORDER BY created_at DESC LIMIT $1`
+
+	if err := r.db.SelectContext(ctx, &broadcasts, query, limit); err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to list broadcasts: %v", err)
+		return nil, fmt.Errorf("database error")
+	}
+
+	return broadcasts, nil
+}
+
+func (r *broadcastRepository) CountAudience(ctx context.Context, segment domain.Segment) (int, error) {
+	where, args := audienceCondition(segment, 1)
+
+	var count int
+	query := `SELECT COUNT(*) FROM users WHERE ` + where
+
+	if err := r.db.GetContext(ctx, &count, query, args...); err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to count broadcast audience: %v", err)
+		return 0, fmt.Errorf("database error")
+	}
+
+	return count, nil
+}
+
+func (r *broadcastRepository) Confirm(ctx context.Context, id uuid.UUID, text string) (*domain.Broadcast, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to begin transaction: %v", err)
+		return nil, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	var broadcast domain.Broadcast
+	err = tx.GetContext(ctx, &broadcast, `SELECT `+broadcastColumns+` FROM broadcasts WHERE id = $1 FOR UPDATE`, id)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrBroadcastNotFound
+	}
+	if err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to lock broadcast %s: %v", id, err)
+		return nil, fmt.Errorf("database error")
+	}
+	if broadcast.Status != domain.BroadcastStatusDraft {
+		return nil, domain.ErrBroadcastNotDraft
+	}
+
+	where, args := audienceCondition(broadcast.Segment, 4)
+	insert := `
+		INSERT INTO notifications (user_id, chat_id, kind, text, broadcast_id)
+		SELECT id, bot_chat_id, $1, $2, $3
+		FROM users
+		WHERE ` + where
+
+	result, err := tx.ExecContext(ctx, insert, append([]interface{}{notificationDomain.KindBroadcast, text, id}, args...)...)
+	if err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to queue broadcast %s: %v", id, err)
+		return nil, fmt.Errorf("failed to queue broadcast")
+	}
+	queued, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("database error")
+	}
+
+	update := `
+		UPDATE broadcasts
+		SET status = $2, audience_size = $3, confirmed_at = NOW()
+		WHERE id = $1
+		RETURNING ` + broadcastColumns
+	if err := tx.GetContext(ctx, &broadcast, update, id, domain.BroadcastStatusSending, queued); err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to confirm broadcast %s: %v", id, err)
+		return nil, fmt.Errorf("database error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to commit broadcast %s: %v", id, err)
+		return nil, fmt.Errorf("database error")
+	}
+
+	r.logger.FromContext(ctx).Infof("Queued broadcast %s for %d recipients", id, queued)
+	return &broadcast, nil
+}
+
+func (r *broadcastRepository) Cancel(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE broadcasts SET status = $2 WHERE id = $1 AND status = $3`
+
+	result, err := r.db.ExecContext(ctx, query, id, domain.BroadcastStatusCancelled, domain.BroadcastStatusDraft)
+	if err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to cancel broadcast %s: %v", id, err)
+		return fmt.Errorf("database error")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("database error")
+	}
+	if rows == 0 {
+		if _, err := r.GetByID(ctx, id); err != nil {
+			return err
+		}
+		return domain.ErrBroadcastNotDraft
+	}
+
+	return nil
+}
+
+func (r *broadcastRepository) Stats(ctx context.Context, id uuid.UUID) (domain.BroadcastStats, error) {
+	var rows []struct {
+		Status string `db:"status"`
+		Count  int    `db:"count"`
+	}
+	query := `
+		SELECT status, COUNT(*) AS count
+		FROM notifications
+		WHERE broadcast_id = $1
+		GROUP BY status`
+
+	var stats domain.BroadcastStats
+	if err := r.db.SelectContext(ctx, &rows, query, id); err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to get stats for broadcast %s: %v", id, err)
+		return stats, fmt.Errorf("database error")
+	}
+
+	for _, row := range rows {
+		switch notificationDomain.NotificationStatus(row.Status) {
+		case notificationDomain.NotificationStatusPending:
+			stats.Pending = row.Count
+		case notificationDomain.NotificationStatusSent:
+			stats.Sent = row.Count
+		case notificationDomain.NotificationStatusFailed:
+			stats.Failed = row.Count
+		}
+	}
+
+	return stats, nil
+}
+
+func (r *broadcastRepository) Recipients(ctx context.Context, id uuid.UUID, limit, offset int) ([]domain.Recipient, error) {
+	recipients := []domain.Recipient{}
+	query := `
+		SELECT user_id, chat_id, status, attempts, last_error, sent_at
+		FROM notifications
+		WHERE broadcast_id = $1
+		ORDER BY created_at, id
+		LIMIT $2 OFFSET $3`
+
+	if err := r.db.SelectContext(ctx, &recipients, query, id, limit, offset); err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to get recipients for broadcast %s: %v", id, err)
+		return nil, fmt.Errorf("database error")
+	}
+
+	return recipients, nil
+}
+
+// audienceCondition renders the WHERE clause for a segment, numbering its
+// placeholders from first.
+func audienceCondition(segment domain.Segment, first int) (string, []interface{}) {
+	base := "bot_status = 'started' AND bot_chat_id IS NOT NULL"
+
+	switch segment.Type {
+	case domain.SegmentInactive:
+		return fmt.Sprintf("%s AND last_seen_at < NOW() - make_interval(days => $%d)", base, first),
+			[]interface{}{segment.Days}
+	case domain.SegmentSkill:
+		return fmt.Sprintf("%s AND EXISTS (SELECT 1 FROM unnest(skills) AS skill WHERE lower(skill) = lower($%d))", base, first),
+			[]interface{}{segment.Skill}
+	case domain.SegmentLanguage:
+		// Users who never picked a language get the default one.
+		return fmt.Sprintf("%s AND COALESCE(language, $%d) = $%d", base, first, first+1),
+			[]interface{}{i18n.DefaultLanguage, segment.Language}
+	default:
+		return base, nil
+	}
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"html"
+
+	"github.com/google/uuid"
+	"github.com/merdernoty/job-hunter/config"
+	"github.com/merdernoty/job-hunter/internal/broadcasts/domain"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+)
+
+const maxRecipientsPage = 500
+
+type broadcastService struct {
+	broadcastRepo domain.BroadcastRepository
+	admins        map[int64]bool
+	logger        logger.Logger
+}
+
+func NewBroadcastService(
+	broadcastRepo domain.BroadcastRepository,
+	cfg *config.Config,
+	logger logger.Logger,
+) domain.BroadcastService {
+	admins := make(map[int64]bool, len(cfg.Admin.TelegramIDs))
+	for _, id := range cfg.Admin.TelegramIDs {
+		admins[id] = true
+	}
+
+	return &broadcastService{
+		broadcastRepo: broadcastRepo,
+		admins:        admins,
+		logger:        logger,
+	}
+}
+
+func (s *broadcastService) Preview(ctx context.Context, createdBy *uuid.UUID, req domain.CreateBroadcastRequest) (*domain.Broadcast, error) {
+	if err := req.Segment.Validate(); err != nil {
+		return nil, err
+	}
+
+	audience, err := s.broadcastRepo.CountAudience(ctx, req.Segment)
+	if err != nil {
+		return nil, err
+	}
+
+	broadcast := &domain.Broadcast{
+		ID:           uuid.New(),
+		CreatedBy:    createdBy,
+		Text:         req.Text,
+		Segment:      req.Segment,
+		Status:       domain.BroadcastStatusDraft,
+		AudienceSize: audience,
+	}
+	if err := s.broadcastRepo.Create(ctx, broadcast); err != nil {
+		return nil, err
+	}
+
+	s.logger.FromContext(ctx).Infof("Broadcast draft %s created for segment %s (audience: %d)", broadcast.ID, req.Segment.Type, audience)
+	return broadcast, nil
+}
+
+// Confirm queues the broadcast. The audience is selected again at this point,
+// so it may differ slightly from the preview.
+func (s *broadcastService) Confirm(ctx context.Context, id uuid.UUID) (*domain.Broadcast, error) {
+	draft, err := s.broadcastRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if draft.Status != domain.BroadcastStatusDraft {
+		return nil, domain.ErrBroadcastNotDraft
+	}
+
+	// Broadcast texts are plain text; notifications are sent as HTML.
+	broadcast, err := s.broadcastRepo.Confirm(ctx, id, html.EscapeString(draft.Text))
+	if err != nil {
+		return nil, err
+	}
+	if broadcast.AudienceSize == 0 {
+		return broadcast, domain.ErrEmptyAudience
+	}
+
+	return broadcast, nil
+}
+
+func (s *broadcastService) Cancel(ctx context.Context, id uuid.UUID) error {
+	return s.broadcastRepo.Cancel(ctx, id)
+}
+
+func (s *broadcastService) GetReport(ctx context.Context, id uuid.UUID) (*domain.BroadcastReport, error) {
+	broadcast, err := s.broadcastRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.broadcastRepo.Stats(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.BroadcastReport{Broadcast: broadcast, Stats: stats}, nil
+}
+
+func (s *broadcastService) List(ctx context.Context, limit int) ([]domain.Broadcast, error) {
+	return s.broadcastRepo.List(ctx, limit)
+}
+
+func (s *broadcastService) Recipients(ctx context.Context, id uuid.UUID, limit, offset int) ([]domain.Recipient, error) {
+	if limit <= 0 || limit > maxRecipientsPage {
+		limit = maxRecipientsPage
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	if _, err := s.broadcastRepo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.broadcastRepo.Recipients(ctx, id, limit, offset)
+}
+
+func (s *broadcastService) IsAdmin(telegramID int64) bool {
+	return s.admins[telegramID]
+}
@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/merdernoty/job-hunter/internal/channels/domain"
+	"github.com/merdernoty/job-hunter/internal/users/middleware"
+	httpResponse "github.com/merdernoty/job-hunter/pkg/http"
+	"github.com/merdernoty/job-hunter/pkg/i18n"
+)
+
+type ChannelController struct {
+	channelService domain.ChannelService
+}
+
+func NewChannelController(channelService domain.ChannelService) *ChannelController {
+	return &ChannelController{channelService: channelService}
+}
+
+func (ctrl *ChannelController) RegisterRoutes(rg *echo.Group, jwtMiddleware echo.MiddlewareFunc) {
+	channels := rg.Group("/channels", jwtMiddleware)
+	channels.GET("", ctrl.list)
+	channels.POST("", ctrl.link)
+	channels.DELETE("/:id", ctrl.unlink)
+}
+
+func (ctrl *ChannelController) list(c echo.Context) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return httpResponse.UnauthorizedResponse(c, i18n.Message(c, "api.auth.required"))
+	}
+
+	channels, err := ctrl.channelService.List(c.Request().Context(), userID)
+	if err != nil {
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.channels.list_failed"))
+	}
+
+	return httpResponse.SuccessResponse(c, channels)
+}
+
+func (ctrl *ChannelController) link(c echo.Context) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return httpResponse.UnauthorizedResponse(c, i18n.Message(c, "api.auth.required"))
+	}
+
+	var req domain.LinkChannelRequest
+	if err := httpResponse.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	channel, err := ctrl.channelService.Link(c.Request().Context(), userID, req)
+	if err != nil {
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.channels.link_failed"))
+	}
+
+	return httpResponse.CreatedResponse(c, channel, i18n.Message(c, "api.channels.linked"))
+}
+
+func (ctrl *ChannelController) unlink(c echo.Context) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return httpResponse.UnauthorizedResponse(c, i18n.Message(c, "api.auth.required"))
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return httpResponse.BadRequestResponse(c, i18n.Message(c, "api.channels.invalid_id"))
+	}
+
+	if err := ctrl.channelService.Unlink(c.Request().Context(), userID, id); err != nil {
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.channels.unlink_failed"))
+	}
+
+	return httpResponse.SuccessResponse(c, nil, i18n.Message(c, "api.channels.unlinked"))
+}
@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/merdernoty/job-hunter/pkg/apperr"
+	"github.com/merdernoty/job-hunter/pkg/deeplink"
+)
+
+var (
+	ErrChannelNotFound = apperr.NotFound("CHANNEL_NOT_FOUND", "channel not found")
+	ErrChannelLinked   = apperr.Conflict("CHANNEL_ALREADY_LINKED", "channel is already linked")
+	ErrChatNotFound    = apperr.Validation("CHAT_NOT_FOUND", "chat not found")
+	ErrUnsupportedChat = apperr.Validation("UNSUPPORTED_CHAT", "only channels and groups can be linked")
+	ErrBotNotAdmin     = apperr.Validation("BOT_NOT_ADMIN", "bot is not an admin of the chat")
+	ErrNotChatAdmin    = apperr.Forbidden("NOT_CHAT_ADMIN", "user is not an admin of the chat")
+	ErrBotUnavailable  = apperr.Unavailable("BOT_UNAVAILABLE", "bot is not configured")
+	ErrPostNotFound    = apperr.NotFound("CHANNEL_POST_NOT_FOUND", "channel post not found")
+)
+
+// Channel is a Telegram channel or group that published items of its owner
+// are posted to.
+type Channel struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	OwnerID   uuid.UUID `json:"owner_id" db:"owner_id"`
+	ChatID    int64     `json:"chat_id" db:"chat_id"`
+	Type      string    `json:"type" db:"type"`
+	Title     string    `json:"title" db:"title"`
+	Username  *string   `json:"username" db:"username"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// Post is the message a card was posted as in one channel.
+type Post struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	ChannelID  uuid.UUID `json:"channel_id" db:"channel_id"`
+	SourceType string    `json:"source_type" db:"source_type"`
+	SourceID   uuid.UUID `json:"source_id" db:"source_id"`
+	MessageID  int       `json:"message_id" db:"message_id"`
+	Closed     bool      `json:"closed" db:"closed"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Card is an item to post, such as a vacancy. The module that owns the item
+// renders Text as HTML; the publisher adds the button that opens Link in the
+// mini app, or marks the post closed.
+type Card struct {
+	SourceType string
+	SourceID   uuid.UUID
+	Text       string
+	Link       deeplink.Link
+	Closed     bool
+}
+
+type LinkChannelRequest struct {
+	// Chat is the @username or numeric ID of the channel or group.
+	Chat string `json:"chat" validate:"required,max=64"`
+}
+
+type ChannelRepository interface {
+	// Create returns ErrChannelLinked if the owner already linked the chat.
+	Create(ctx context.Context, channel *Channel) error
+	GetByID(ctx context.Context, id uuid.UUID) (*Channel, error)
+	ListByOwner(ctx context.Context, ownerID uuid.UUID) ([]Channel, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type PostRepository interface {
+	Get(ctx context.Context, channelID uuid.UUID, sourceType string, sourceID uuid.UUID) (*Post, error)
+	// Save creates the post or updates the one for the same channel and
+	// source.
+	Save(ctx context.Context, post *Post) error
+}
+
+type ChannelService interface {
+	// Link verifies that both the bot and the owner are admins of the chat
+	// before linking it.
+	Link(ctx context.Context, ownerID uuid.UUID, req LinkChannelRequest) (*Channel, error)
+	List(ctx context.Context, ownerID uuid.UUID) ([]Channel, error)
+	Unlink(ctx context.Context, ownerID, id uuid.UUID) error
+}
+
+// Publisher posts cards to every channel their owner linked and keeps the
+// posts in sync: publishing the same card again edits the posts, and a closed
+// card marks them closed.
+type Publisher interface {
+	Publish(ctx context.Context, ownerID uuid.UUID, card Card) error
+}
@@ -0,0 +1,29 @@
+package channels
+
+import (
+	"github.com/merdernoty/job-hunter/internal/channels/domain"
+	"github.com/merdernoty/job-hunter/internal/channels/repository"
+	"github.com/merdernoty/job-hunter/internal/channels/service"
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module("channels",
+	fx.Provide(
+		fx.Annotate(
+			repository.NewChannelRepository,
+			fx.As(new(domain.ChannelRepository)),
+		),
+		fx.Annotate(
+			repository.NewPostRepository,
+			fx.As(new(domain.PostRepository)),
+		),
+		fx.Annotate(
+			service.NewChannelService,
+			fx.As(new(domain.ChannelService)),
+		),
+		fx.Annotate(
+			service.NewPublisher,
+			fx.As(new(domain.Publisher)),
+		),
+	),
+)
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/merdernoty/job-hunter/internal/channels/domain"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+)
+
+const channelColumns = `id, owner_id, chat_id, type, title, username, created_at`
+
+type channelRepository struct {
+	db     *sqlx.DB
+	logger logger.Logger
+}
+
+func NewChannelRepository(db *sqlx.DB, logger logger.Logger) domain.ChannelRepository {
+	return &channelRepository{db: db, logger: logger}
+}
+
+func (r *channelRepository) Create(ctx context.Context, channel *domain.Channel) error {
+	if channel.ID == uuid.Nil {
+		channel.ID = uuid.New()
+	}
+
+	query := `
+		INSERT INTO channels (id, owner_id, chat_id, type, title, username)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (owner_id, chat_id) DO NOTHING
+		RETURNING created_at`
+
+	err := r.db.QueryRowContext(
+		ctx, query,
+		channel.ID, channel.OwnerID, channel.ChatID, channel.Type, channel.Title, channel.Username,
+	).Scan(&channel.CreatedAt)
+	if err == sql.ErrNoRows {
+		return domain.ErrChannelLinked
+	}
+	if err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to link chat %d for user %s: %v", channel.ChatID, channel.OwnerID, err)
+		return fmt.Errorf("failed to link channel")
+	}
+
+	return nil
+}
+
+func (r *channelRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Channel, error) {
+	var channel domain.Channel
+	query := `SELECT ` + channelColumns + ` FROM channels WHERE id = $1`
+
+	err := r.db.GetContext(ctx, &channel, query, id)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrChannelNotFound
+	}
+	if err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to get channel %s: %v", id, err)
+		return nil, fmt.Errorf("database error")
+	}
+
+	return &channel, nil
+}
+
+func (r *channelRepository) ListByOwner(ctx context.Context, ownerID uuid.UUID) ([]domain.Channel, error) {
+	channels := []domain.Channel{}
+	query := `SELECT ` + channelColumns + ` FROM channels WHERE owner_id = $1 ORDER BY created_at`
+
+	if err := r.db.SelectContext(ctx, &channels, query, ownerID); err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to list channels of user %s: %v", ownerID, err)
+		return nil, fmt.Errorf("database error")
+	}
+
+	return channels, nil
+}
+
+func (r *channelRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM channels WHERE id = $1`, id); err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to delete channel %s: %v", id, err)
+		return fmt.Errorf("database error")
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/merdernoty/job-hunter/internal/channels/domain"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+)
+
+type postRepository struct {
+	db     *sqlx.DB
+	logger logger.Logger
+}
+
+func NewPostRepository(db *sqlx.DB, logger logger.Logger) domain.PostRepository {
+	return &postRepository{db: db, logger: logger}
+}
+
+func (r *postRepository) Get(ctx context.Context, channelID uuid.UUID, sourceType string, sourceID uuid.UUID) (*domain.Post, error) {
+	var post domain.Post
+	query := `
+		SELECT id, channel_id, source_type, source_id, message_id, closed, created_at, updated_at
+		FROM channel_posts
+		WHERE channel_id = $1 AND source_type = $2 AND source_id = $3`
+
+	err := r.db.GetContext(ctx, &post, query, channelID, sourceType, sourceID)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrPostNotFound
+	}
+	if err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to get %s %s post in channel %s: %v", sourceType, sourceID, channelID, err)
+		return nil, fmt.Errorf("database error")
+	}
+
+	return &post, nil
+}
+
+func (r *postRepository) Save(ctx context.Context, post *domain.Post) error {
+	if post.ID == uuid.Nil {
+		post.ID = uuid.New()
+	}
+
+	query := `
+		INSERT INTO channel_posts (id, channel_id, source_type, source_id, message_id, closed)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (channel_id, source_type, source_id) DO UPDATE
+		SET message_id = EXCLUDED.message_id, closed = EXCLUDED.closed, updated_at = NOW()
+		RETURNING id, created_at, updated_at`
+
+	err := r.db.QueryRowContext(
+		ctx, query,
+		post.ID, post.ChannelID, post.SourceType, post.SourceID, post.MessageID, post.Closed,
+	).Scan(&post.ID, &post.CreatedAt, &post.UpdatedAt)
+	if err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to save %s %s post in channel %s: %v", post.SourceType, post.SourceID, post.ChannelID, err)
+		return fmt.Errorf("database error")
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/google/uuid"
+	"github.com/merdernoty/job-hunter/internal/bot"
+	"github.com/merdernoty/job-hunter/internal/channels/domain"
+	userDomain "github.com/merdernoty/job-hunter/internal/users/domain"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+)
+
+type channelService struct {
+	channelRepo domain.ChannelRepository
+	userService userDomain.UserService
+	bot         *bot.Bot
+	logger      logger.Logger
+}
+
+func NewChannelService(
+	channelRepo domain.ChannelRepository,
+	userService userDomain.UserService,
+	b *bot.Bot,
+	logger logger.Logger,
+) domain.ChannelService {
+	return &channelService{
+		channelRepo: channelRepo,
+		userService: userService,
+		bot:         b,
+		logger:      logger,
+	}
+}
+
+func (s *channelService) Link(ctx context.Context, ownerID uuid.UUID, req domain.LinkChannelRequest) (*domain.Channel, error) {
+	if s.bot == nil {
+		return nil, domain.ErrBotUnavailable
+	}
+
+	owner, err := s.userService.GetUser(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	chat, err := s.bot.GetChat(req.Chat)
+	if err != nil {
+		s.logger.FromContext(ctx).Warnf("Failed to look up chat %q: %v", req.Chat, err)
+		return nil, domain.ErrChatNotFound
+	}
+	if !chat.IsChannel() && !chat.IsGroup() && !chat.IsSuperGroup() {
+		return nil, domain.ErrUnsupportedChat
+	}
+
+	botMember, err := s.bot.GetChatMember(chat.ID, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check bot rights in chat %d: %w", chat.ID, err)
+	}
+	if !canPost(chat, botMember) {
+		return nil, domain.ErrBotNotAdmin
+	}
+
+	// Anyone can name a public channel, so only its admins may link it.
+	member, err := s.bot.GetChatMember(chat.ID, owner.TelegramID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check user rights in chat %d: %w", chat.ID, err)
+	}
+	if !member.IsCreator() && !member.IsAdministrator() {
+		return nil, domain.ErrNotChatAdmin
+	}
+
+	channel := &domain.Channel{
+		ID:      uuid.New(),
+		OwnerID: ownerID,
+		ChatID:  chat.ID,
+		Type:    chat.Type,
+		Title:   chat.Title,
+	}
+	if chat.UserName != "" {
+		channel.Username = &chat.UserName
+	}
+	if err := s.channelRepo.Create(ctx, channel); err != nil {
+		return nil, err
+	}
+
+	s.logger.FromContext(ctx).Infof("User %s linked %s %d (%s)", ownerID, chat.Type, chat.ID, chat.Title)
+	return channel, nil
+}
+
+func (s *channelService) List(ctx context.Context, ownerID uuid.UUID) ([]domain.Channel, error) {
+	return s.channelRepo.ListByOwner(ctx, ownerID)
+}
+
+func (s *channelService) Unlink(ctx context.Context, ownerID, id uuid.UUID) error {
+	channel, err := s.channelRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if channel.OwnerID != ownerID {
+		return domain.ErrChannelNotFound
+	}
+
+	return s.channelRepo.Delete(ctx, id)
+}
+
+// canPost reports whether the bot may post cards to the chat and edit them
+// later. Channel admins need both rights granted explicitly.
+func canPost(chat tgbotapi.Chat, member tgbotapi.ChatMember) bool {
+	if member.IsCreator() {
+		return true
+	}
+	if !member.IsAdministrator() {
+		return false
+	}
+	if chat.IsChannel() {
+		return member.CanPostMessages && member.CanEditMessages
+	}
+	return true
+}
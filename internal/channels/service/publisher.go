@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/google/uuid"
+	"github.com/merdernoty/job-hunter/internal/bot"
+	"github.com/merdernoty/job-hunter/internal/channels/domain"
+	"github.com/merdernoty/job-hunter/pkg/deeplink"
+	"github.com/merdernoty/job-hunter/pkg/i18n"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+)
+
+type publisher struct {
+	channelRepo domain.ChannelRepository
+	postRepo    domain.PostRepository
+	deepLinks   *deeplink.Codec
+	translator  *i18n.Translator
+	bot         *bot.Bot
+	logger      logger.Logger
+}
+
+func NewPublisher(
+	channelRepo domain.ChannelRepository,
+	postRepo domain.PostRepository,
+	deepLinks *deeplink.Codec,
+	translator *i18n.Translator,
+	b *bot.Bot,
+	logger logger.Logger,
+) domain.Publisher {
+	return &publisher{
+		channelRepo: channelRepo,
+		postRepo:    postRepo,
+		deepLinks:   deepLinks,
+		translator:  translator,
+		bot:         b,
+		logger:      logger,
+	}
+}
+
+// Publish is attempted for every channel; the errors of the channels that
+// failed are joined.
+func (p *publisher) Publish(ctx context.Context, ownerID uuid.UUID, card domain.Card) error {
+	if p.bot == nil {
+		return domain.ErrBotUnavailable
+	}
+
+	channels, err := p.channelRepo.ListByOwner(ctx, ownerID)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, channel := range channels {
+		if err := p.publish(ctx, channel, card); err != nil {
+			p.logger.FromContext(ctx).Errorf("Failed to publish %s %s to chat %d: %v", card.SourceType, card.SourceID, channel.ChatID, err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (p *publisher) publish(ctx context.Context, channel domain.Channel, card domain.Card) error {
+	post, err := p.postRepo.Get(ctx, channel.ID, card.SourceType, card.SourceID)
+	if errors.Is(err, domain.ErrPostNotFound) {
+		if card.Closed {
+			return nil
+		}
+		return p.send(ctx, channel, card)
+	}
+	if err != nil {
+		return err
+	}
+	if post.Closed && card.Closed {
+		return nil
+	}
+
+	text, keyboard := p.render(ctx, card)
+	edit := tgbotapi.NewEditMessageTextAndMarkup(channel.ChatID, post.MessageID, text, keyboard)
+	edit.ParseMode = tgbotapi.ModeHTML
+
+	if _, err := p.bot.Request(edit); err != nil && !isNotModified(err) {
+		return err
+	}
+
+	post.Closed = card.Closed
+	return p.postRepo.Save(ctx, post)
+}
+
+func (p *publisher) send(ctx context.Context, channel domain.Channel, card domain.Card) error {
+	text, keyboard := p.render(ctx, card)
+	msg := tgbotapi.NewMessage(channel.ChatID, text)
+	msg.ParseMode = tgbotapi.ModeHTML
+	msg.ReplyMarkup = keyboard
+
+	sent, err := p.bot.Send(msg)
+	if err != nil {
+		return err
+	}
+
+	return p.postRepo.Save(ctx, &domain.Post{
+		ChannelID:  channel.ID,
+		SourceType: card.SourceType,
+		SourceID:   card.SourceID,
+		MessageID:  sent.MessageID,
+	})
+}
+
+// render returns the post text and buttons. Channels have no reader language,
+// so posts use the default one. web_app buttons do not work outside private
+// chats, hence the t.me link.
+func (p *publisher) render(ctx context.Context, card domain.Card) (string, tgbotapi.InlineKeyboardMarkup) {
+	lang := i18n.DefaultLanguage
+	if card.Closed {
+		text := card.Text + "\n\n" + p.translator.T(lang, "channels.closed")
+		return text, tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}
+	}
+
+	startParam, err := p.deepLinks.Encode(card.Link)
+	if err != nil {
+		p.logger.FromContext(ctx).Warnf("Failed to encode link for %s %s: %v", card.SourceType, card.SourceID, err)
+	}
+
+	return card.Text, tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonURL(p.translator.T(lang, "channels.apply"), p.bot.MiniAppLink(startParam)),
+		),
+	)
+}
+
+// isNotModified reports Telegram rejecting an edit that changes nothing,
+// e.g. when a card is published again unchanged.
+func isNotModified(err error) bool {
+	var apiErr *tgbotapi.Error
+	return errors.As(err, &apiErr) && strings.Contains(apiErr.Message, "message is not modified")
+}
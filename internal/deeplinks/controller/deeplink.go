@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/merdernoty/job-hunter/internal/deeplinks/domain"
+	"github.com/merdernoty/job-hunter/internal/users/middleware"
+	httpResponse "github.com/merdernoty/job-hunter/pkg/http"
+	"github.com/merdernoty/job-hunter/pkg/i18n"
+)
+
+type DeepLinkController struct {
+	deepLinkService domain.DeepLinkService
+}
+
+func NewDeepLinkController(deepLinkService domain.DeepLinkService) *DeepLinkController {
+	return &DeepLinkController{deepLinkService: deepLinkService}
+}
+
+func (ctrl *DeepLinkController) RegisterRoutes(rg *echo.Group, jwtMiddleware echo.MiddlewareFunc) {
+	links := rg.Group("/links", jwtMiddleware)
+	links.POST("", ctrl.create)
+}
+
+func (ctrl *DeepLinkController) create(c echo.Context) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return httpResponse.UnauthorizedResponse(c, i18n.Message(c, "api.auth.required"))
+	}
+
+	var req domain.CreateLinkRequest
+	if err := httpResponse.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	links, err := ctrl.deepLinkService.Create(c.Request().Context(), userID, req)
+	if err != nil {
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.links.create_failed"))
+	}
+
+	return httpResponse.CreatedResponse(c, links)
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/merdernoty/job-hunter/pkg/apperr"
+	"github.com/merdernoty/job-hunter/pkg/deeplink"
+)
+
+var ErrTargetRequired = apperr.Validation("TARGET_REQUIRED", "link target id is required")
+
+type CreateLinkRequest struct {
+	Type string `json:"type" validate:"required,oneof=profile vacancy referral company_invite"`
+	// ID is the linked object. Profile links default to the caller; referral
+	// links always point to the caller.
+	ID *uuid.UUID `json:"id,omitempty"`
+}
+
+// Links are the ways to share a deep link. AppLink and BotLink need the bot
+// and are empty when it is not configured.
+type Links struct {
+	deeplink.Target
+	StartParam string `json:"start_param"`
+	// WebAppURL opens the mini app directly; the app passes start_param on
+	// to the auth endpoint.
+	WebAppURL string `json:"web_app_url,omitempty"`
+	// AppLink opens the mini app from any chat with start_param in initData.
+	AppLink string `json:"app_link,omitempty"`
+	// BotLink opens the chat with the bot and sends /start with the payload.
+	BotLink string `json:"bot_link,omitempty"`
+}
+
+type DeepLinkService interface {
+	Create(ctx context.Context, userID uuid.UUID, req CreateLinkRequest) (*Links, error)
+}
@@ -0,0 +1,16 @@
+package deeplinks
+
+import (
+	"github.com/merdernoty/job-hunter/internal/deeplinks/domain"
+	"github.com/merdernoty/job-hunter/internal/deeplinks/service"
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module("deeplinks",
+	fx.Provide(
+		fx.Annotate(
+			service.NewDeepLinkService,
+			fx.As(new(domain.DeepLinkService)),
+		),
+	),
+)
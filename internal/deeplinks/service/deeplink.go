@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/merdernoty/job-hunter/config"
+	"github.com/merdernoty/job-hunter/internal/bot"
+	"github.com/merdernoty/job-hunter/internal/deeplinks/domain"
+	"github.com/merdernoty/job-hunter/pkg/deeplink"
+)
+
+type deepLinkService struct {
+	codec     *deeplink.Codec
+	bot       *bot.Bot
+	webAppURL string
+}
+
+func NewDeepLinkService(codec *deeplink.Codec, b *bot.Bot, cfg *config.Config) domain.DeepLinkService {
+	return &deepLinkService{
+		codec:     codec,
+		bot:       b,
+		webAppURL: cfg.Bot.WebAppURL,
+	}
+}
+
+func (s *deepLinkService) Create(ctx context.Context, userID uuid.UUID, req domain.CreateLinkRequest) (*domain.Links, error) {
+	linkType, err := deeplink.ParseType(req.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	link := deeplink.Link{Type: linkType}
+	switch {
+	case linkType == deeplink.Referral:
+		link.ID = userID
+	case req.ID != nil:
+		link.ID = *req.ID
+	case linkType == deeplink.Profile:
+		link.ID = userID
+	default:
+		return nil, domain.ErrTargetRequired
+	}
+
+	startParam, err := s.codec.Encode(link)
+	if err != nil {
+		return nil, err
+	}
+
+	links := &domain.Links{
+		Target:     link.Target(),
+		StartParam: startParam,
+	}
+	if s.webAppURL != "" {
+		links.WebAppURL = deeplink.WebAppURL(s.webAppURL, startParam)
+	}
+	if s.bot != nil {
+		links.AppLink = s.bot.MiniAppLink(startParam)
+		links.BotLink = s.bot.StartLink(startParam)
+	}
+
+	return links, nil
+}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/merdernoty/job-hunter/pkg/apperr"
+)
+
+type NotificationStatus string
+
+const (
+	NotificationStatusPending NotificationStatus = "pending"
+	NotificationStatusSent    NotificationStatus = "sent"
+	NotificationStatusFailed  NotificationStatus = "failed"
+)
+
+const (
+	KindNewMatch          = "new_match"
+	KindProfileViewed     = "profile_viewed"
+	KindApplicationStatus = "application_status"
+	KindBroadcast         = "broadcast"
+)
+
+var (
+	ErrDuplicateNotification = apperr.Conflict("DUPLICATE_NOTIFICATION", "notification already queued")
+	// ErrUnreachable means the user has not started the bot or blocked it.
+	ErrUnreachable = apperr.Forbidden("USER_UNREACHABLE", "user cannot be messaged by the bot")
+)
+
+type Notification struct {
+	ID            uuid.UUID          `json:"id" db:"id"`
+	UserID        *uuid.UUID         `json:"user_id" db:"user_id"`
+	ChatID        int64              `json:"chat_id" db:"chat_id"`
+	Kind          string             `json:"kind" db:"kind"`
+	Text          string             `json:"text" db:"text"`
+	ButtonText    *string            `json:"button_text" db:"button_text"`
+	StartParam    *string            `json:"start_param" db:"start_param"`
+	DedupeKey     *string            `json:"-" db:"dedupe_key"`
+	BroadcastID   *uuid.UUID         `json:"broadcast_id,omitempty" db:"broadcast_id"`
+	Status        NotificationStatus `json:"status" db:"status"`
+	Attempts      int                `json:"attempts" db:"attempts"`
+	NextAttemptAt time.Time          `json:"next_attempt_at" db:"next_attempt_at"`
+	LastError     *string            `json:"last_error" db:"last_error"`
+	CreatedAt     time.Time          `json:"created_at" db:"created_at"`
+	SentAt        *time.Time         `json:"sent_at" db:"sent_at"`
+}
+
+// Message describes a notification before it is rendered in the recipient's
+// language. Key is a message catalog key formatted with Args.
+type Message struct {
+	Kind string
+	Key  string
+	Args []interface{}
+	// StartParam, when set, adds a button that opens the mini app with it.
+	StartParam string
+	// DedupeKey, when set, drops the notification if one with the same key
+	// was already queued.
+	DedupeKey string
+}
+
+// Notifier is what domain modules use to message users through the bot.
+// Notifications are written to an outbox and delivered asynchronously.
+type Notifier interface {
+	// Notify returns ErrUnreachable when the user has no open chat with
+	// the bot.
+	Notify(ctx context.Context, userID uuid.UUID, msg Message) (*Notification, error)
+	NotifyChat(ctx context.Context, chatID int64, lang string, msg Message) (*Notification, error)
+}
+
+type NotificationRepository interface {
+	// Create queues the notification, returning ErrDuplicateNotification
+	// if its dedupe key was already used.
+	Create(ctx context.Context, notification *Notification) error
+	// ClaimDue leases up to limit due personal notifications and up to
+	// broadcastLimit due broadcast ones, so that concurrent dispatchers do
+	// not pick them up again before lease expires. Personal notifications
+	// come first.
+	ClaimDue(ctx context.Context, limit, broadcastLimit int, lease time.Duration) ([]Notification, error)
+	MarkSent(ctx context.Context, id uuid.UUID) error
+	// Retry counts a failed attempt and schedules the next one.
+	Retry(ctx context.Context, id uuid.UUID, at time.Time, lastError string) error
+	// Postpone reschedules without counting an attempt, e.g. when a rate
+	// limit was hit before sending.
+	Postpone(ctx context.Context, id uuid.UUID, at time.Time) error
+	MarkFailed(ctx context.Context, id uuid.UUID, lastError string) error
+}
+
+// Sender delivers a rendered notification. It is implemented on top of the
+// Telegram bot.
+type Sender interface {
+	Send(notification *Notification) error
+}
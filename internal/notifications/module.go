@@ -0,0 +1,80 @@
+package notifications
+
+import (
+	"context"
+
+	"github.com/merdernoty/job-hunter/config"
+	"github.com/merdernoty/job-hunter/internal/bot"
+	"github.com/merdernoty/job-hunter/internal/notifications/domain"
+	"github.com/merdernoty/job-hunter/internal/notifications/repository"
+	"github.com/merdernoty/job-hunter/internal/notifications/service"
+	userDomain "github.com/merdernoty/job-hunter/internal/users/domain"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module("notifications",
+	fx.Provide(
+		fx.Annotate(
+			repository.NewNotificationRepository,
+			fx.As(new(domain.NotificationRepository)),
+		),
+		fx.Annotate(
+			service.NewNotifier,
+			fx.As(new(domain.Notifier)),
+		),
+	),
+	fx.Invoke(RunDispatcher),
+)
+
+// RunDispatcher delivers the outbox through the bot. Without a configured bot
+// notifications are still queued and go out once the bot is configured.
+func RunDispatcher(
+	lc fx.Lifecycle,
+	b *bot.Bot,
+	notificationRepo domain.NotificationRepository,
+	userService userDomain.UserService,
+	cfg *config.Config,
+	log logger.Logger,
+) {
+	if !cfg.Notifications.Enabled {
+		log.Info("Notification delivery is disabled")
+		return
+	}
+	if b == nil {
+		log.Warn("Bot is not configured, notifications will stay queued")
+		return
+	}
+
+	dispatcher := service.NewDispatcher(notificationRepo, service.NewBotSender(b), userService, cfg.Notifications, log)
+
+	var cancel context.CancelFunc
+	done := make(chan struct{})
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			jobCtx, jobCancel := context.WithCancel(context.Background())
+			cancel = jobCancel
+
+			go func() {
+				defer close(done)
+				dispatcher.Run(jobCtx)
+			}()
+
+			log.Infof("Notification dispatcher started (poll interval: %v)", cfg.Notifications.PollInterval)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			if cancel == nil {
+				return nil
+			}
+			cancel()
+
+			select {
+			case <-done:
+			case <-ctx.Done():
+			}
+			return nil
+		},
+	})
+}
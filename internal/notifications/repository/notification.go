@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/merdernoty/job-hunter/internal/notifications/domain"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+)
+
+const notificationColumns = `id, user_id, chat_id, kind, text, button_text, start_param, dedupe_key,
+	broadcast_id, status, attempts, next_attempt_at, last_error, created_at, sent_at`
+
+type notificationRepository struct {
+	db     *sqlx.DB
+	logger logger.Logger
+}
+
+func NewNotificationRepository(db *sqlx.DB, logger logger.Logger) domain.NotificationRepository {
+	return &notificationRepository{db: db, logger: logger}
+}
+
+func (r *notificationRepository) Create(ctx context.Context, notification *domain.Notification) error {
+	if notification.ID == uuid.Nil {
+		notification.ID = uuid.New()
+	}
+	notification.Status = domain.NotificationStatusPending
+
+	query := `
+		INSERT INTO notifications (id, user_id, chat_id, kind, text, button_text, start_param, dedupe_key)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (dedupe_key) DO NOTHING
+		RETURNING next_attempt_at, created_at`
+
+	err := r.db.QueryRowContext(
+		ctx, query,
+		notification.ID, notification.UserID, notification.ChatID, notification.Kind, notification.Text,
+		notification.ButtonText, notification.StartParam, notification.DedupeKey,
+	).Scan(&notification.NextAttemptAt, &notification.CreatedAt)
+	if err == sql.ErrNoRows {
+		return domain.ErrDuplicateNotification
+	}
+	if err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to queue %s notification for chat %d: %v", notification.Kind, notification.ChatID, err)
+		return fmt.Errorf("failed to queue notification")
+	}
+
+	return nil
+}
+
+func (r *notificationRepository) ClaimDue(ctx context.Context, limit, broadcastLimit int, lease time.Duration) ([]domain.Notification, error) {
+	notifications := []domain.Notification{}
+	query := `
+		WITH claimed AS (
+			UPDATE notifications
+			SET next_attempt_at = NOW() + make_interval(secs => $3)
+			WHERE id IN (
+				(SELECT id FROM notifications
+				WHERE status = 'pending' AND next_attempt_at <= NOW() AND kind <> $4
+				ORDER BY next_attempt_at
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED)
+				UNION ALL
+				(SELECT id FROM notifications
+				WHERE status = 'pending' AND next_attempt_at <= NOW() AND kind = $4
+				ORDER BY next_attempt_at
+				LIMIT $2
+				FOR UPDATE SKIP LOCKED)
+			)
+			RETURNING ` + notificationColumns + `
+		)
+		SELECT * FROM claimed
+		ORDER BY kind = $4, next_attempt_at`
+
+	if err := r.db.SelectContext(ctx, &notifications, query, limit, broadcastLimit, lease.Seconds(), domain.KindBroadcast); err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to claim due notifications: %v", err)
+		return nil, fmt.Errorf("database error")
+	}
+
+	return notifications, nil
+}
+
+func (r *notificationRepository) MarkSent(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE notifications
+		SET status = 'sent', sent_at = NOW(), attempts = attempts + 1, last_error = NULL
+		WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to mark notification %s as sent: %v", id, err)
+		return fmt.Errorf("database error")
+	}
+
+	return nil
+}
+
+func (r *notificationRepository) Retry(ctx context.Context, id uuid.UUID, at time.Time, lastError string) error {
+	query := `
+		UPDATE notifications
+		SET attempts = attempts + 1, next_attempt_at = $2, last_error = $3
+		WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, id, at, lastError); err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to reschedule notification %s: %v", id, err)
+		return fmt.Errorf("database error")
+	}
+
+	return nil
+}
+
+func (r *notificationRepository) Postpone(ctx context.Context, id uuid.UUID, at time.Time) error {
+	query := `UPDATE notifications SET next_attempt_at = $2 WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, id, at); err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to postpone notification %s: %v", id, err)
+		return fmt.Errorf("database error")
+	}
+
+	return nil
+}
+
+func (r *notificationRepository) MarkFailed(ctx context.Context, id uuid.UUID, lastError string) error {
+	query := `
+		UPDATE notifications
+		SET status = 'failed', attempts = attempts + 1, last_error = $2
+		WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, id, lastError); err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to mark notification %s as failed: %v", id, err)
+		return fmt.Errorf("database error")
+	}
+
+	return nil
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/merdernoty/job-hunter/config"
+	"github.com/merdernoty/job-hunter/internal/notifications/domain"
+	userDomain "github.com/merdernoty/job-hunter/internal/users/domain"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+	"golang.org/x/time/rate"
+)
+
+// claimLease is how long a claimed notification stays invisible to other
+// dispatchers. It must comfortably exceed the time to send one batch.
+const claimLease = 2 * time.Minute
+
+// Dispatcher delivers queued notifications within Telegram's rate limits.
+type Dispatcher struct {
+	notificationRepo domain.NotificationRepository
+	sender           domain.Sender
+	userService      userDomain.UserService
+	cfg              config.NotificationsConfig
+	logger           logger.Logger
+
+	global    *rate.Limiter
+	broadcast *rate.Limiter
+
+	mu          sync.Mutex
+	chats       map[int64]*rate.Limiter
+	pausedUntil time.Time
+}
+
+func NewDispatcher(
+	notificationRepo domain.NotificationRepository,
+	sender domain.Sender,
+	userService userDomain.UserService,
+	cfg config.NotificationsConfig,
+	logger logger.Logger,
+) *Dispatcher {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 2 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 50
+	}
+	if cfg.GlobalRate <= 0 {
+		cfg.GlobalRate = 25
+	}
+	if cfg.PerChatRate <= 0 {
+		cfg.PerChatRate = 1
+	}
+	if cfg.BroadcastRate <= 0 || cfg.BroadcastRate > cfg.GlobalRate {
+		cfg.BroadcastRate = cfg.GlobalRate
+	}
+
+	return &Dispatcher{
+		notificationRepo: notificationRepo,
+		sender:           sender,
+		userService:      userService,
+		cfg:              cfg,
+		logger:           logger,
+		global:           rate.NewLimiter(rate.Limit(cfg.GlobalRate), 1),
+		broadcast:        rate.NewLimiter(rate.Limit(cfg.BroadcastRate), 1),
+		chats:            make(map[int64]*rate.Limiter),
+	}
+}
+
+// Run polls the outbox until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				claimed, err := d.DispatchBatch(ctx)
+				if err != nil {
+					d.logger.Errorf("Failed to dispatch notifications: %v", err)
+					break
+				}
+				// Keep draining while full batches come back.
+				if claimed < d.cfg.BatchSize || ctx.Err() != nil {
+					break
+				}
+			}
+		}
+	}
+}
+
+// DispatchBatch claims one batch of due notifications and tries to send each
+// of them, returning how many were claimed.
+func (d *Dispatcher) DispatchBatch(ctx context.Context) (int, error) {
+	notifications, err := d.notificationRepo.ClaimDue(ctx, d.cfg.BatchSize, d.broadcastBatch(), claimLease)
+	if err != nil {
+		return 0, err
+	}
+
+	// Claimed notifications are still released or recorded once ctx is
+	// cancelled, so that stopping does not leave them leased.
+	record := context.WithoutCancel(ctx)
+
+	for i := range notifications {
+		notification := &notifications[i]
+
+		if ctx.Err() != nil {
+			d.postpone(record, notification, time.Now())
+			continue
+		}
+
+		if until := d.paused(); !until.IsZero() {
+			d.postpone(record, notification, until)
+			continue
+		}
+
+		reservation := d.chatLimiter(notification.ChatID).Reserve()
+		if delay := reservation.Delay(); delay > 0 {
+			reservation.Cancel()
+			d.postpone(record, notification, time.Now().Add(delay))
+			continue
+		}
+
+		// Broadcasts get a slower lane so that they leave room for
+		// personal notifications. Batches hold no more broadcasts than the
+		// lane sends in a poll interval, so waiting here stays short.
+		if notification.Kind == domain.KindBroadcast {
+			if err := d.broadcast.Wait(ctx); err != nil {
+				d.postpone(record, notification, time.Now())
+				continue
+			}
+		}
+
+		if err := d.global.Wait(ctx); err != nil {
+			d.postpone(record, notification, time.Now())
+			continue
+		}
+
+		d.deliver(record, notification)
+	}
+
+	return len(notifications), nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, notification *domain.Notification) {
+	err := d.sender.Send(notification)
+	if err == nil {
+		if err := d.notificationRepo.MarkSent(ctx, notification.ID); err != nil {
+			d.logger.Errorf("Notification %s was sent but could not be marked: %v", notification.ID, err)
+		}
+		return
+	}
+
+	var apiErr *tgbotapi.Error
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.RetryAfter > 0:
+			// Flood control applies to the whole bot, so stop sending
+			// anything until Telegram allows it again.
+			until := time.Now().Add(time.Duration(apiErr.RetryAfter) * time.Second)
+			d.pause(until)
+			d.logger.Warnf("Telegram flood control, pausing notifications for %ds", apiErr.RetryAfter)
+			d.postpone(ctx, notification, until)
+			return
+		case apiErr.Code == http.StatusForbidden || apiErr.Code == http.StatusBadRequest:
+			// The user blocked the bot, the chat is gone or the message
+			// is malformed: retrying will not help.
+			d.logger.Warnf("Notification %s to chat %d failed permanently: %v", notification.ID, notification.ChatID, err)
+			if err := d.notificationRepo.MarkFailed(ctx, notification.ID, err.Error()); err != nil {
+				d.logger.Errorf("Failed to mark notification %s as failed: %v", notification.ID, err)
+			}
+			if apiErr.Code == http.StatusForbidden && notification.UserID != nil {
+				if err := d.userService.MarkBotBlocked(ctx, notification.ChatID); err != nil {
+					d.logger.Errorf("Failed to mark chat %d as blocked: %v", notification.ChatID, err)
+				}
+			}
+			return
+		}
+	}
+
+	attempts := notification.Attempts + 1
+	if attempts >= d.cfg.MaxAttempts {
+		d.logger.Errorf("Giving up on notification %s after %d attempts: %v", notification.ID, attempts, err)
+		if err := d.notificationRepo.MarkFailed(ctx, notification.ID, err.Error()); err != nil {
+			d.logger.Errorf("Failed to mark notification %s as failed: %v", notification.ID, err)
+		}
+		return
+	}
+
+	next := time.Now().Add(d.backoff(attempts))
+	d.logger.Warnf("Notification %s attempt %d failed, retrying at %s: %v", notification.ID, attempts, next.Format(time.RFC3339), err)
+	if err := d.notificationRepo.Retry(ctx, notification.ID, next, err.Error()); err != nil {
+		d.logger.Errorf("Failed to reschedule notification %s: %v", notification.ID, err)
+	}
+}
+
+// broadcastBatch is how many broadcast notifications the broadcast rate
+// allows per poll interval, at least one and at most a batch.
+func (d *Dispatcher) broadcastBatch() int {
+	n := int(math.Ceil(d.cfg.BroadcastRate * d.cfg.PollInterval.Seconds()))
+	if n < 1 {
+		n = 1
+	}
+	if n > d.cfg.BatchSize {
+		n = d.cfg.BatchSize
+	}
+	return n
+}
+
+// backoff doubles the delay with every attempt, capped at RetryMax.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.cfg.RetryBase
+	for i := 1; i < attempts && delay < d.cfg.RetryMax; i++ {
+		delay *= 2
+	}
+	if delay > d.cfg.RetryMax {
+		delay = d.cfg.RetryMax
+	}
+	return delay
+}
+
+func (d *Dispatcher) postpone(ctx context.Context, notification *domain.Notification, at time.Time) {
+	if err := d.notificationRepo.Postpone(ctx, notification.ID, at); err != nil {
+		d.logger.Errorf("Failed to postpone notification %s: %v", notification.ID, err)
+	}
+}
+
+func (d *Dispatcher) chatLimiter(chatID int64) *rate.Limiter {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	limiter, ok := d.chats[chatID]
+	if !ok {
+		// Limiters that have refilled carry no state worth keeping.
+		if len(d.chats) > 10000 {
+			for id, l := range d.chats {
+				if l.Tokens() >= 1 {
+					delete(d.chats, id)
+				}
+			}
+		}
+		limiter = rate.NewLimiter(rate.Limit(d.cfg.PerChatRate), 1)
+		d.chats[chatID] = limiter
+	}
+	return limiter
+}
+
+func (d *Dispatcher) pause(until time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if until.After(d.pausedUntil) {
+		d.pausedUntil = until
+	}
+}
+
+func (d *Dispatcher) paused() time.Time {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if time.Now().Before(d.pausedUntil) {
+		return d.pausedUntil
+	}
+	return time.Time{}
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/merdernoty/job-hunter/internal/notifications/domain"
+	userDomain "github.com/merdernoty/job-hunter/internal/users/domain"
+	"github.com/merdernoty/job-hunter/pkg/i18n"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+)
+
+type notifier struct {
+	notificationRepo domain.NotificationRepository
+	userService      userDomain.UserService
+	translator       *i18n.Translator
+	logger           logger.Logger
+}
+
+func NewNotifier(
+	notificationRepo domain.NotificationRepository,
+	userService userDomain.UserService,
+	translator *i18n.Translator,
+	logger logger.Logger,
+) domain.Notifier {
+	return &notifier{
+		notificationRepo: notificationRepo,
+		userService:      userService,
+		translator:       translator,
+		logger:           logger,
+	}
+}
+
+// Notify queues a message to the user's private chat with the bot, rendered
+// in the user's saved language.
+func (n *notifier) Notify(ctx context.Context, userID uuid.UUID, msg domain.Message) (*domain.Notification, error) {
+	user, err := n.userService.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.CanMessage() {
+		return nil, domain.ErrUnreachable
+	}
+
+	var saved string
+	if user.Language != nil {
+		saved = *user.Language
+	}
+
+	notification := n.render(*user.BotChatID, n.translator.Resolve(saved), msg)
+	notification.UserID = &user.ID
+
+	return n.enqueue(ctx, notification)
+}
+
+func (n *notifier) NotifyChat(ctx context.Context, chatID int64, lang string, msg domain.Message) (*domain.Notification, error) {
+	return n.enqueue(ctx, n.render(chatID, n.translator.Resolve(lang), msg))
+}
+
+func (n *notifier) render(chatID int64, lang string, msg domain.Message) *domain.Notification {
+	notification := &domain.Notification{
+		ID:     uuid.New(),
+		ChatID: chatID,
+		Kind:   msg.Kind,
+		Text:   n.translator.T(lang, msg.Key, msg.Args...),
+	}
+
+	if msg.StartParam != "" {
+		buttonText := n.translator.T(lang, "notifications.open_app")
+		notification.ButtonText = &buttonText
+		notification.StartParam = &msg.StartParam
+	}
+	if msg.DedupeKey != "" {
+		notification.DedupeKey = &msg.DedupeKey
+	}
+
+	return notification
+}
+
+func (n *notifier) enqueue(ctx context.Context, notification *domain.Notification) (*domain.Notification, error) {
+	if err := n.notificationRepo.Create(ctx, notification); err != nil {
+		return nil, err
+	}
+
+	n.logger.FromContext(ctx).Infof("Queued %s notification %s for chat %d", notification.Kind, notification.ID, notification.ChatID)
+	return notification, nil
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/merdernoty/job-hunter/internal/bot"
+	"github.com/merdernoty/job-hunter/internal/notifications/domain"
+)
+
+type botSender struct {
+	bot *bot.Bot
+}
+
+func NewBotSender(b *bot.Bot) domain.Sender {
+	return &botSender{bot: b}
+}
+
+func (s *botSender) Send(notification *domain.Notification) error {
+	msg := tgbotapi.NewMessage(notification.ChatID, notification.Text)
+	msg.ParseMode = tgbotapi.ModeHTML
+
+	if notification.ButtonText != nil && notification.StartParam != nil {
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonURL(*notification.ButtonText, s.bot.MiniAppLink(*notification.StartParam)),
+			),
+		)
+	}
+
+	_, err := s.bot.Send(msg)
+	return err
+}
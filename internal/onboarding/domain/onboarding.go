@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	userDomain "github.com/merdernoty/job-hunter/internal/users/domain"
+	"github.com/merdernoty/job-hunter/pkg/apperr"
+)
+
+// FlowOnboarding identifies onboarding rows in bot_conversations. The table
+// holds at most one active conversation per Telegram user.
+const FlowOnboarding = "onboarding"
+
+type Step string
+
+const (
+	StepRole      Step = "role"
+	StepSeniority Step = "seniority"
+	StepSkills    Step = "skills"
+	StepBio       Step = "bio"
+	StepConfirm   Step = "confirm"
+)
+
+// Steps is the order the bot asks its questions in.
+var Steps = []Step{StepRole, StepSeniority, StepSkills, StepBio, StepConfirm}
+
+const (
+	MaxRoleLength  = 100
+	MaxSkills      = 20
+	MaxSkillLength = 30
+	MaxBioLength   = 500
+)
+
+var (
+	ErrConversationNotFound = apperr.NotFound("CONVERSATION_NOT_FOUND", "conversation not found")
+	ErrNoPreviousStep       = apperr.Conflict("NO_PREVIOUS_STEP", "already at the first step")
+	ErrInvalidRole          = apperr.Validation("INVALID_ROLE", "invalid role")
+	ErrInvalidSeniority     = apperr.Validation("INVALID_SENIORITY", "invalid seniority")
+	ErrInvalidSkills        = apperr.Validation("INVALID_SKILLS", "invalid skills")
+	ErrInvalidBio           = apperr.Validation("INVALID_BIO", "invalid bio")
+	ErrNotConfirmable       = apperr.Conflict("NOT_CONFIRMABLE", "onboarding is not ready to be saved")
+)
+
+// ConversationData holds the answers collected so far, keyed by step.
+type ConversationData map[string]string
+
+func (d ConversationData) Value() (driver.Value, error) {
+	if d == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(d)
+}
+
+func (d *ConversationData) Scan(src interface{}) error {
+	var raw []byte
+	switch v := src.(type) {
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	case nil:
+		*d = ConversationData{}
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into ConversationData", src)
+	}
+
+	data := ConversationData{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return err
+	}
+	*d = data
+	return nil
+}
+
+type Conversation struct {
+	TelegramID int64            `db:"telegram_id"`
+	Flow       string           `db:"flow"`
+	Step       Step             `db:"step"`
+	Data       ConversationData `db:"data"`
+	UpdatedAt  time.Time        `db:"updated_at"`
+}
+
+type ConversationRepository interface {
+	Get(ctx context.Context, telegramID int64) (*Conversation, error)
+	// Save inserts the conversation or replaces the user's current one.
+	Save(ctx context.Context, conversation *Conversation) error
+	Delete(ctx context.Context, telegramID int64) error
+}
+
+type OnboardingService interface {
+	Start(ctx context.Context, telegramID int64) (*Conversation, error)
+	// Current returns the user's onboarding conversation, or
+	// ErrConversationNotFound if they are not onboarding.
+	Current(ctx context.Context, telegramID int64) (*Conversation, error)
+	// Answer validates the answer for the current step and moves forward.
+	Answer(ctx context.Context, telegramID int64, answer string) (*Conversation, error)
+	Skip(ctx context.Context, telegramID int64) (*Conversation, error)
+	Back(ctx context.Context, telegramID int64) (*Conversation, error)
+	Cancel(ctx context.Context, telegramID int64) error
+	// Finish writes the collected answers to the user's profile.
+	Finish(ctx context.Context, telegramID int64, username string) (*userDomain.User, error)
+}
@@ -0,0 +1,313 @@
+package handler
+
+import (
+	"errors"
+	"html"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/merdernoty/job-hunter/internal/bot"
+	"github.com/merdernoty/job-hunter/internal/onboarding/domain"
+	userDomain "github.com/merdernoty/job-hunter/internal/users/domain"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+)
+
+const callbackPrefix = "onboarding"
+
+type OnboardingHandlers struct {
+	onboardingService domain.OnboardingService
+	logger            logger.Logger
+}
+
+func NewOnboardingHandlers(onboardingService domain.OnboardingService, logger logger.Logger) bot.Handlers {
+	return &OnboardingHandlers{
+		onboardingService: onboardingService,
+		logger:            logger,
+	}
+}
+
+func (h *OnboardingHandlers) Register(r *bot.Router) {
+	r.Command("profile", "command.profile", h.start)
+	r.Command("cancel", "command.cancel", h.cancel)
+	r.Callback(callbackPrefix, h.callback)
+	r.Message(h.answer)
+}
+
+func (h *OnboardingHandlers) start(c *bot.Context) error {
+	sender := c.Sender()
+	if sender == nil {
+		return nil
+	}
+
+	conversation, err := h.onboardingService.Start(c, sender.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := c.ReplyHTML(c.T("onboarding.intro")); err != nil {
+		return err
+	}
+	return h.prompt(c, conversation)
+}
+
+func (h *OnboardingHandlers) cancel(c *bot.Context) error {
+	sender := c.Sender()
+	if sender == nil {
+		return nil
+	}
+
+	err := h.onboardingService.Cancel(c, sender.ID)
+	if errors.Is(err, domain.ErrConversationNotFound) {
+		return c.ReplyHTML(c.T("onboarding.nothing_to_cancel"))
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.ReplyHTML(c.T("onboarding.cancelled"))
+}
+
+// answer handles free-text replies while the user is onboarding and skips
+// everyone else so other message handlers can run.
+func (h *OnboardingHandlers) answer(c *bot.Context) error {
+	sender := c.Sender()
+	msg := c.Update.Message
+	if sender == nil || msg.Text == "" {
+		return bot.ErrSkip
+	}
+
+	if _, err := h.onboardingService.Current(c, sender.ID); err != nil {
+		if errors.Is(err, domain.ErrConversationNotFound) {
+			return bot.ErrSkip
+		}
+		return err
+	}
+
+	if isBackWord(c, msg.Text) {
+		return h.back(c, sender.ID)
+	}
+
+	conversation, err := h.onboardingService.Answer(c, sender.ID, msg.Text)
+	if err != nil {
+		if text, ok := validationMessage(c, err); ok {
+			if err := c.ReplyHTML(text); err != nil {
+				return err
+			}
+			return h.prompt(c, conversation)
+		}
+		return err
+	}
+
+	return h.prompt(c, conversation)
+}
+
+func (h *OnboardingHandlers) callback(c *bot.Context) error {
+	sender := c.Sender()
+	if sender == nil {
+		return c.AnswerCallback("")
+	}
+
+	action := c.CallbackData()
+	var (
+		conversation *domain.Conversation
+		err          error
+	)
+
+	switch {
+	case action == "back":
+		if err := c.AnswerCallback(""); err != nil {
+			h.logger.FromContext(c).Warnf("Failed to answer callback: %v", err)
+		}
+		return h.back(c, sender.ID)
+	case action == "skip":
+		conversation, err = h.onboardingService.Skip(c, sender.ID)
+	case action == "save":
+		return h.finish(c, sender)
+	case strings.HasPrefix(action, "seniority:"):
+		return h.seniority(c, sender.ID, strings.TrimPrefix(action, "seniority:"))
+	default:
+		return c.AnswerCallback("")
+	}
+
+	if errors.Is(err, domain.ErrConversationNotFound) {
+		return c.AnswerCallback(c.T("onboarding.closed"))
+	}
+	if err != nil {
+		if text, ok := validationMessage(c, err); ok {
+			return c.AnswerCallback(text)
+		}
+		return err
+	}
+
+	if err := c.AnswerCallback(""); err != nil {
+		h.logger.FromContext(c).Warnf("Failed to answer callback: %v", err)
+	}
+	return h.prompt(c, conversation)
+}
+
+// seniority answers the seniority step from its keyboard. A button left
+// over from an earlier prompt only re-prompts the current step, so that its
+// value does not land in another field.
+func (h *OnboardingHandlers) seniority(c *bot.Context, telegramID int64, seniority string) error {
+	conversation, err := h.onboardingService.Current(c, telegramID)
+	if errors.Is(err, domain.ErrConversationNotFound) {
+		return c.AnswerCallback(c.T("onboarding.closed"))
+	}
+	if err != nil {
+		return err
+	}
+
+	if conversation.Step == domain.StepSeniority {
+		conversation, err = h.onboardingService.Answer(c, telegramID, seniority)
+		if err != nil {
+			if text, ok := validationMessage(c, err); ok {
+				return c.AnswerCallback(text)
+			}
+			return err
+		}
+	}
+
+	if err := c.AnswerCallback(""); err != nil {
+		h.logger.FromContext(c).Warnf("Failed to answer callback: %v", err)
+	}
+	return h.prompt(c, conversation)
+}
+
+func (h *OnboardingHandlers) back(c *bot.Context, telegramID int64) error {
+	conversation, err := h.onboardingService.Back(c, telegramID)
+	if errors.Is(err, domain.ErrNoPreviousStep) {
+		if err := c.ReplyHTML(c.T("onboarding.first_step")); err != nil {
+			return err
+		}
+		return h.prompt(c, conversation)
+	}
+	if errors.Is(err, domain.ErrConversationNotFound) {
+		return c.ReplyHTML(c.T("onboarding.closed"))
+	}
+	if err != nil {
+		return err
+	}
+
+	return h.prompt(c, conversation)
+}
+
+func (h *OnboardingHandlers) finish(c *bot.Context, sender *tgbotapi.User) error {
+	user, err := h.onboardingService.Finish(c, sender.ID, sender.UserName)
+	if errors.Is(err, domain.ErrConversationNotFound) {
+		return c.AnswerCallback(c.T("onboarding.closed"))
+	}
+	if errors.Is(err, domain.ErrNotConfirmable) {
+		return c.AnswerCallback(c.T("onboarding.answer_all"))
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := c.AnswerCallback(c.T("onboarding.saved")); err != nil {
+		h.logger.FromContext(c).Warnf("Failed to answer callback: %v", err)
+	}
+	return c.ReplyHTML(c.T("onboarding.profile_saved", summary(c, user)))
+}
+
+func (h *OnboardingHandlers) prompt(c *bot.Context, conversation *domain.Conversation) error {
+	var (
+		text string
+		rows [][]tgbotapi.InlineKeyboardButton
+	)
+
+	back := tgbotapi.NewInlineKeyboardButtonData(c.T("onboarding.back"), callbackPrefix+":back")
+
+	switch conversation.Step {
+	case domain.StepRole:
+		text = c.T("onboarding.step.role")
+	case domain.StepSeniority:
+		text = c.T("onboarding.step.seniority")
+		var row []tgbotapi.InlineKeyboardButton
+		for _, seniority := range userDomain.Seniorities {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(seniority, callbackPrefix+":seniority:"+seniority))
+		}
+		rows = append(rows, row, tgbotapi.NewInlineKeyboardRow(back))
+	case domain.StepSkills:
+		text = c.T("onboarding.step.skills", domain.MaxSkills)
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(back))
+	case domain.StepBio:
+		text = c.T("onboarding.step.bio", domain.MaxBioLength)
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			back,
+			tgbotapi.NewInlineKeyboardButtonData(c.T("onboarding.skip"), callbackPrefix+":skip"),
+		))
+	case domain.StepConfirm:
+		text = c.T("onboarding.step.confirm", draftSummary(c, conversation.Data))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			back,
+			tgbotapi.NewInlineKeyboardButtonData(c.T("onboarding.save"), callbackPrefix+":save"),
+		))
+	}
+
+	reply := tgbotapi.NewMessage(c.ChatID(), text)
+	reply.ParseMode = tgbotapi.ModeHTML
+	if len(rows) > 0 {
+		reply.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	}
+
+	_, err := c.Send(reply)
+	return err
+}
+
+// isBackWord reports whether a text answer means "go back", in any of the
+// words listed in the catalog.
+func isBackWord(c *bot.Context, text string) bool {
+	text = strings.ToLower(strings.TrimSpace(text))
+	for _, word := range strings.Split(c.T("onboarding.back_words"), ",") {
+		if text == word {
+			return true
+		}
+	}
+	return false
+}
+
+func validationMessage(c *bot.Context, err error) (string, bool) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidRole):
+		return c.T("onboarding.invalid.role", domain.MaxRoleLength), true
+	case errors.Is(err, domain.ErrInvalidSeniority):
+		return c.T("onboarding.invalid.seniority", strings.Join(userDomain.Seniorities, ", ")), true
+	case errors.Is(err, domain.ErrInvalidSkills):
+		return c.T("onboarding.invalid.skills", domain.MaxSkills, domain.MaxSkillLength), true
+	case errors.Is(err, domain.ErrInvalidBio):
+		return c.T("onboarding.invalid.bio", domain.MaxBioLength), true
+	default:
+		return "", false
+	}
+}
+
+func draftSummary(c *bot.Context, data domain.ConversationData) string {
+	bio, ok := data[string(domain.StepBio)]
+	if !ok {
+		bio = "—"
+	}
+
+	return c.T(
+		"onboarding.summary",
+		html.EscapeString(data[string(domain.StepRole)]),
+		html.EscapeString(data[string(domain.StepSeniority)]),
+		html.EscapeString(strings.ReplaceAll(data[string(domain.StepSkills)], ",", ", ")),
+		html.EscapeString(bio),
+	)
+}
+
+func summary(c *bot.Context, user *userDomain.User) string {
+	data := domain.ConversationData{
+		string(domain.StepSkills): strings.Join(user.Skills, ","),
+	}
+	if user.Role != nil {
+		data[string(domain.StepRole)] = *user.Role
+	}
+	if user.Seniority != nil {
+		data[string(domain.StepSeniority)] = *user.Seniority
+	}
+	if user.Bio != nil && *user.Bio != "" {
+		data[string(domain.StepBio)] = *user.Bio
+	}
+	return draftSummary(c, data)
+}
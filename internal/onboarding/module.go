@@ -0,0 +1,28 @@
+package onboarding
+
+import (
+	"github.com/merdernoty/job-hunter/internal/onboarding/domain"
+	"github.com/merdernoty/job-hunter/internal/onboarding/handler"
+	"github.com/merdernoty/job-hunter/internal/onboarding/repository"
+	"github.com/merdernoty/job-hunter/internal/onboarding/service"
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module("onboarding",
+	fx.Provide(
+		fx.Annotate(
+			repository.NewConversationRepository,
+			fx.As(new(domain.ConversationRepository)),
+		),
+		fx.Annotate(
+			service.NewOnboardingService,
+			fx.As(new(domain.OnboardingService)),
+		),
+	),
+	fx.Provide(
+		fx.Annotate(
+			handler.NewOnboardingHandlers,
+			fx.ResultTags(`group:"bot_handlers"`),
+		),
+	),
+)
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/merdernoty/job-hunter/internal/onboarding/domain"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+)
+
+type conversationRepository struct {
+	db     *sqlx.DB
+	logger logger.Logger
+}
+
+func NewConversationRepository(db *sqlx.DB, logger logger.Logger) domain.ConversationRepository {
+	return &conversationRepository{db: db, logger: logger}
+}
+
+func (r *conversationRepository) Get(ctx context.Context, telegramID int64) (*domain.Conversation, error) {
+	var conversation domain.Conversation
+	query := `
+		SELECT telegram_id, flow, step, data, updated_at
+		FROM bot_conversations
+		WHERE telegram_id = $1`
+
+	err := r.db.GetContext(ctx, &conversation, query, telegramID)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrConversationNotFound
+	}
+	if err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to get conversation for %d: %v", telegramID, err)
+		return nil, fmt.Errorf("database error")
+	}
+
+	return &conversation, nil
+}
+
+func (r *conversationRepository) Save(ctx context.Context, conversation *domain.Conversation) error {
+	query := `
+		INSERT INTO bot_conversations (telegram_id, flow, step, data, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (telegram_id) DO UPDATE
+		SET flow = EXCLUDED.flow, step = EXCLUDED.step, data = EXCLUDED.data, updated_at = NOW()
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(
+		ctx, query,
+		conversation.TelegramID, conversation.Flow, conversation.Step, conversation.Data,
+	).Scan(&conversation.UpdatedAt)
+	if err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to save conversation for %d: %v", conversation.TelegramID, err)
+		return fmt.Errorf("failed to save conversation")
+	}
+
+	return nil
+}
+
+func (r *conversationRepository) Delete(ctx context.Context, telegramID int64) error {
+	query := `DELETE FROM bot_conversations WHERE telegram_id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, telegramID); err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to delete conversation for %d: %v", telegramID, err)
+		return fmt.Errorf("failed to delete conversation")
+	}
+
+	return nil
+}
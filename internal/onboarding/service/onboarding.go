@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/merdernoty/job-hunter/internal/onboarding/domain"
+	userDomain "github.com/merdernoty/job-hunter/internal/users/domain"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+)
+
+type onboardingService struct {
+	conversationRepo domain.ConversationRepository
+	userService      userDomain.UserService
+	logger           logger.Logger
+}
+
+func NewOnboardingService(
+	conversationRepo domain.ConversationRepository,
+	userService userDomain.UserService,
+	logger logger.Logger,
+) domain.OnboardingService {
+	return &onboardingService{
+		conversationRepo: conversationRepo,
+		userService:      userService,
+		logger:           logger,
+	}
+}
+
+func (s *onboardingService) Start(ctx context.Context, telegramID int64) (*domain.Conversation, error) {
+	conversation := &domain.Conversation{
+		TelegramID: telegramID,
+		Flow:       domain.FlowOnboarding,
+		Step:       domain.Steps[0],
+		Data:       domain.ConversationData{},
+	}
+
+	if err := s.conversationRepo.Save(ctx, conversation); err != nil {
+		return nil, err
+	}
+
+	s.logger.FromContext(ctx).Infof("Started onboarding for %d", telegramID)
+	return conversation, nil
+}
+
+func (s *onboardingService) Current(ctx context.Context, telegramID int64) (*domain.Conversation, error) {
+	conversation, err := s.conversationRepo.Get(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation.Flow != domain.FlowOnboarding {
+		return nil, domain.ErrConversationNotFound
+	}
+
+	return conversation, nil
+}
+
+func (s *onboardingService) Answer(ctx context.Context, telegramID int64, answer string) (*domain.Conversation, error) {
+	conversation, err := s.Current(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	answer = strings.TrimSpace(answer)
+
+	switch conversation.Step {
+	case domain.StepRole:
+		if err := validateRole(answer); err != nil {
+			return conversation, err
+		}
+	case domain.StepSeniority:
+		answer = strings.ToLower(answer)
+		if !isSeniority(answer) {
+			return conversation, domain.ErrInvalidSeniority
+		}
+	case domain.StepSkills:
+		skills, err := parseSkills(answer)
+		if err != nil {
+			return conversation, err
+		}
+		answer = strings.Join(skills, ",")
+	case domain.StepBio:
+		if answer == "" || utf8.RuneCountInString(answer) > domain.MaxBioLength {
+			return conversation, domain.ErrInvalidBio
+		}
+	default:
+		return conversation, nil
+	}
+
+	conversation.Data[string(conversation.Step)] = answer
+	return s.advance(ctx, conversation, 1)
+}
+
+// Skip moves past the bio step without changing the stored bio. Other steps
+// are required.
+func (s *onboardingService) Skip(ctx context.Context, telegramID int64) (*domain.Conversation, error) {
+	conversation, err := s.Current(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation.Step != domain.StepBio {
+		return conversation, nil
+	}
+
+	delete(conversation.Data, string(domain.StepBio))
+	return s.advance(ctx, conversation, 1)
+}
+
+func (s *onboardingService) Back(ctx context.Context, telegramID int64) (*domain.Conversation, error) {
+	conversation, err := s.Current(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation.Step == domain.Steps[0] {
+		return conversation, domain.ErrNoPreviousStep
+	}
+
+	return s.advance(ctx, conversation, -1)
+}
+
+func (s *onboardingService) Cancel(ctx context.Context, telegramID int64) error {
+	if _, err := s.Current(ctx, telegramID); err != nil {
+		return err
+	}
+	if err := s.conversationRepo.Delete(ctx, telegramID); err != nil {
+		return err
+	}
+
+	s.logger.FromContext(ctx).Infof("Cancelled onboarding for %d", telegramID)
+	return nil
+}
+
+func (s *onboardingService) Finish(ctx context.Context, telegramID int64, username string) (*userDomain.User, error) {
+	conversation, err := s.Current(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation.Step != domain.StepConfirm {
+		return nil, domain.ErrNotConfirmable
+	}
+
+	user, err := s.userService.EnsureTelegramUser(ctx, telegramID, username)
+	if err != nil {
+		return nil, err
+	}
+
+	role := conversation.Data[string(domain.StepRole)]
+	seniority := conversation.Data[string(domain.StepSeniority)]
+	skills := strings.Split(conversation.Data[string(domain.StepSkills)], ",")
+	req := userDomain.UpdateUserRequest{
+		Role:      &role,
+		Seniority: &seniority,
+		Skills:    &skills,
+	}
+	if bio, ok := conversation.Data[string(domain.StepBio)]; ok {
+		req.Bio = &bio
+	}
+
+	updated, err := s.userService.UpdateUser(ctx, user.ID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.conversationRepo.Delete(ctx, telegramID); err != nil {
+		s.logger.FromContext(ctx).Errorf("Failed to clear finished onboarding for %d: %v", telegramID, err)
+	}
+
+	s.logger.FromContext(ctx).Infof("Finished onboarding for %d (user %s)", telegramID, updated.ID)
+	return updated, nil
+}
+
+func (s *onboardingService) advance(ctx context.Context, conversation *domain.Conversation, delta int) (*domain.Conversation, error) {
+	for i, step := range domain.Steps {
+		if step == conversation.Step {
+			next := i + delta
+			if next >= 0 && next < len(domain.Steps) {
+				conversation.Step = domain.Steps[next]
+			}
+			break
+		}
+	}
+
+	if err := s.conversationRepo.Save(ctx, conversation); err != nil {
+		return nil, err
+	}
+
+	return conversation, nil
+}
+
+func validateRole(role string) error {
+	length := utf8.RuneCountInString(role)
+	if length < 2 || length > domain.MaxRoleLength || strings.ContainsAny(role, "\r\n") {
+		return domain.ErrInvalidRole
+	}
+	return nil
+}
+
+func isSeniority(value string) bool {
+	for _, seniority := range userDomain.Seniorities {
+		if value == seniority {
+			return true
+		}
+	}
+	return false
+}
+
+// parseSkills splits a comma or newline separated list, dropping blanks and
+// case-insensitive duplicates.
+func parseSkills(answer string) ([]string, error) {
+	fields := strings.FieldsFunc(answer, func(r rune) bool {
+		return r == ',' || r == '\n' || r == ';'
+	})
+
+	seen := make(map[string]bool, len(fields))
+	skills := make([]string, 0, len(fields))
+	for _, field := range fields {
+		skill := strings.TrimSpace(field)
+		if skill == "" {
+			continue
+		}
+		if utf8.RuneCountInString(skill) > domain.MaxSkillLength {
+			return nil, domain.ErrInvalidSkills
+		}
+
+		key := strings.ToLower(skill)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		skills = append(skills, skill)
+	}
+
+	if len(skills) == 0 || len(skills) > domain.MaxSkills {
+		return nil, domain.ErrInvalidSkills
+	}
+
+	return skills, nil
+}
@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/merdernoty/job-hunter/internal/payments/domain"
+	"github.com/merdernoty/job-hunter/internal/users/middleware"
+	httpResponse "github.com/merdernoty/job-hunter/pkg/http"
+	"github.com/merdernoty/job-hunter/pkg/i18n"
+)
+
+type PaymentController struct {
+	paymentService     domain.PaymentService
+	entitlementService domain.EntitlementService
+}
+
+func NewPaymentController(paymentService domain.PaymentService, entitlementService domain.EntitlementService) *PaymentController {
+	return &PaymentController{
+		paymentService:     paymentService,
+		entitlementService: entitlementService,
+	}
+}
+
+// ProductResponse is a product with its title and description in the
+// request language.
+type ProductResponse struct {
+	domain.Product
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+func (ctrl *PaymentController) RegisterRoutes(rg *echo.Group, jwtMiddleware, adminMiddleware echo.MiddlewareFunc) {
+	payments := rg.Group("/payments", jwtMiddleware)
+	payments.GET("", ctrl.list)
+	payments.GET("/products", ctrl.products)
+	payments.POST("/invoice", ctrl.createInvoice)
+	payments.GET("/entitlements", ctrl.entitlements)
+
+	admin := rg.Group("/admin/payments", jwtMiddleware, adminMiddleware)
+	admin.POST("/:id/refund", ctrl.refund)
+}
+
+func (ctrl *PaymentController) products(c echo.Context) error {
+	products := ctrl.paymentService.Products()
+
+	response := make([]ProductResponse, 0, len(products))
+	for _, product := range products {
+		key := "premium.product." + product.ID
+		response = append(response, ProductResponse{
+			Product:     product,
+			Title:       i18n.Message(c, key+".title"),
+			Description: i18n.Message(c, key+".description"),
+		})
+	}
+
+	return httpResponse.SuccessResponse(c, response)
+}
+
+// createInvoice returns a link for Telegram.WebApp.openInvoice. The payment
+// itself is completed through the bot.
+func (ctrl *PaymentController) createInvoice(c echo.Context) error {
+	var req domain.CreateInvoiceRequest
+	if err := httpResponse.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	link, err := ctrl.paymentService.CreateInvoiceLink(c.Request().Context(), i18n.Language(c), req.Product)
+	if err != nil {
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.payments.invoice_failed"))
+	}
+
+	return httpResponse.CreatedResponse(c, map[string]string{"invoice_link": link})
+}
+
+func (ctrl *PaymentController) entitlements(c echo.Context) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return httpResponse.UnauthorizedResponse(c, i18n.Message(c, "api.auth.required"))
+	}
+
+	entitlements, err := ctrl.entitlementService.List(c.Request().Context(), userID)
+	if err != nil {
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.payments.entitlements_failed"))
+	}
+
+	return httpResponse.SuccessResponse(c, entitlements)
+}
+
+func (ctrl *PaymentController) list(c echo.Context) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return httpResponse.UnauthorizedResponse(c, i18n.Message(c, "api.auth.required"))
+	}
+
+	payments, err := ctrl.paymentService.ListPayments(c.Request().Context(), userID)
+	if err != nil {
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.payments.list_failed"))
+	}
+
+	return httpResponse.SuccessResponse(c, payments)
+}
+
+func (ctrl *PaymentController) refund(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return httpResponse.BadRequestResponse(c, i18n.Message(c, "api.payments.invalid_id"))
+	}
+
+	payment, err := ctrl.paymentService.Refund(c.Request().Context(), id)
+	if err != nil {
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.payments.refund_failed"))
+	}
+
+	return httpResponse.SuccessResponse(c, payment, i18n.Message(c, "api.payments.refunded"))
+}
@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/merdernoty/job-hunter/pkg/apperr"
+)
+
+// Feature is a premium capability that services check before serving it.
+type Feature string
+
+const (
+	FeatureExtraProfiles  Feature = "extra_profiles"
+	FeatureProfileViewers Feature = "profile_viewers"
+	FeatureVacancyBoost   Feature = "vacancy_boost"
+)
+
+// CurrencyStars is Telegram Stars, the only currency digital goods may be
+// sold for inside Telegram.
+const CurrencyStars = "XTR"
+
+type PaymentStatus string
+
+const (
+	PaymentStatusPaid     PaymentStatus = "paid"
+	PaymentStatusRefunded PaymentStatus = "refunded"
+)
+
+var (
+	ErrUnknownProduct   = apperr.Validation("UNKNOWN_PRODUCT", "unknown product")
+	ErrInvalidCheckout  = apperr.Validation("INVALID_CHECKOUT", "checkout does not match the product")
+	ErrDuplicatePayment = apperr.Conflict("DUPLICATE_PAYMENT", "payment already recorded")
+	ErrPaymentNotFound  = apperr.NotFound("PAYMENT_NOT_FOUND", "payment not found")
+	ErrAlreadyRefunded  = apperr.Conflict("ALREADY_REFUNDED", "payment already refunded")
+	ErrBotUnavailable   = apperr.Unavailable("BOT_UNAVAILABLE", "bot is not configured")
+)
+
+// Product is something sold for Stars. Its title and description are the
+// catalog keys "premium.product.<id>.title" and "premium.product.<id>.description".
+type Product struct {
+	ID      string  `json:"id"`
+	Feature Feature `json:"feature"`
+	Days    int     `json:"days"`
+	Price   int     `json:"price"`
+}
+
+// Products is the premium catalog. Vacancy boosts are granted already and
+// will be checked by vacancy listings once those exist.
+var Products = []Product{
+	{ID: "extra_profiles_30d", Feature: FeatureExtraProfiles, Days: 30, Price: 100},
+	{ID: "profile_viewers_30d", Feature: FeatureProfileViewers, Days: 30, Price: 150},
+	{ID: "vacancy_boost_7d", Feature: FeatureVacancyBoost, Days: 7, Price: 250},
+}
+
+func FindProduct(id string) (Product, bool) {
+	for _, product := range Products {
+		if product.ID == id {
+			return product, true
+		}
+	}
+	return Product{}, false
+}
+
+type Payment struct {
+	ID               uuid.UUID     `json:"id" db:"id"`
+	UserID           uuid.UUID     `json:"user_id" db:"user_id"`
+	Product          string        `json:"product" db:"product"`
+	Currency         string        `json:"currency" db:"currency"`
+	Amount           int           `json:"amount" db:"amount"`
+	TelegramChargeID string        `json:"telegram_charge_id" db:"telegram_charge_id"`
+	ProviderChargeID *string       `json:"-" db:"provider_charge_id"`
+	Status           PaymentStatus `json:"status" db:"status"`
+	CreatedAt        time.Time     `json:"created_at" db:"created_at"`
+	RefundedAt       *time.Time    `json:"refunded_at" db:"refunded_at"`
+}
+
+// Entitlement grants a feature for a period. Repeated purchases stack: each
+// one starts when the previous one expires.
+type Entitlement struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	UserID    uuid.UUID  `json:"user_id" db:"user_id"`
+	Feature   Feature    `json:"feature" db:"feature"`
+	PaymentID uuid.UUID  `json:"payment_id" db:"payment_id"`
+	StartsAt  time.Time  `json:"starts_at" db:"starts_at"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	RevokedAt *time.Time `json:"-" db:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Receipt is the successful_payment Telegram sends once the user paid.
+type Receipt struct {
+	Payload          string
+	Currency         string
+	Amount           int
+	TelegramChargeID string
+	ProviderChargeID string
+}
+
+type CreateInvoiceRequest struct {
+	Product string `json:"product" validate:"required,max=64"`
+}
+
+type PaymentRepository interface {
+	// Record stores the payment together with the entitlement it buys,
+	// stacked after any active entitlement to the same feature. It returns
+	// ErrDuplicatePayment when the charge was recorded before.
+	Record(ctx context.Context, payment *Payment, feature Feature, days int) (*Entitlement, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*Payment, error)
+	GetByChargeID(ctx context.Context, telegramChargeID string) (*Payment, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]Payment, error)
+	// Refund marks the payment refunded and revokes its entitlement. Later
+	// entitlements stacked after it move forward by the unused time.
+	Refund(ctx context.Context, id uuid.UUID) (*Payment, error)
+}
+
+type EntitlementRepository interface {
+	HasActive(ctx context.Context, userID uuid.UUID, feature Feature) (bool, error)
+	// ListCurrent returns entitlements that are active or start later.
+	ListCurrent(ctx context.Context, userID uuid.UUID) ([]Entitlement, error)
+}
+
+type EntitlementService interface {
+	// Has reports whether the user has the feature now. It fails closed:
+	// lookup errors are logged and reported as false.
+	Has(ctx context.Context, userID uuid.UUID, feature Feature) bool
+	List(ctx context.Context, userID uuid.UUID) ([]Entitlement, error)
+}
+
+type PaymentService interface {
+	Products() []Product
+	// SendInvoice sends a Stars invoice for the product to a chat.
+	SendInvoice(ctx context.Context, chatID int64, lang, productID string) error
+	// CreateInvoiceLink returns a link the mini app opens with openInvoice.
+	CreateInvoiceLink(ctx context.Context, lang, productID string) (string, error)
+	// ValidateCheckout decides the pre_checkout_query: nil lets the user pay.
+	ValidateCheckout(payload, currency string, amount int) error
+	// Complete records a successful payment and grants its entitlement.
+	Complete(ctx context.Context, userID uuid.UUID, receipt Receipt) (*Entitlement, error)
+	// Refund returns the Stars to the user and revokes the entitlement.
+	Refund(ctx context.Context, paymentID uuid.UUID) (*Payment, error)
+	// RecordRefund revokes the entitlement of a payment Telegram reports as
+	// refunded, e.g. by Telegram support. It returns ErrAlreadyRefunded for
+	// refunds the app made itself.
+	RecordRefund(ctx context.Context, telegramChargeID string) (*Payment, error)
+	ListPayments(ctx context.Context, userID uuid.UUID) ([]Payment, error)
+}
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"errors"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/merdernoty/job-hunter/internal/bot"
+	"github.com/merdernoty/job-hunter/internal/payments/domain"
+	userDomain "github.com/merdernoty/job-hunter/internal/users/domain"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+)
+
+const callbackPrefix = "premium"
+
+const dateLayout = "02.01.2006"
+
+// PremiumHandlers sell premium features for Telegram Stars: /premium lists
+// the products, a button sends the invoice, and Telegram reports back with a
+// pre_checkout_query and a successful_payment message.
+type PremiumHandlers struct {
+	paymentService     domain.PaymentService
+	entitlementService domain.EntitlementService
+	userService        userDomain.UserService
+	logger             logger.Logger
+}
+
+func NewPremiumHandlers(
+	paymentService domain.PaymentService,
+	entitlementService domain.EntitlementService,
+	userService userDomain.UserService,
+	logger logger.Logger,
+) bot.Handlers {
+	return &PremiumHandlers{
+		paymentService:     paymentService,
+		entitlementService: entitlementService,
+		userService:        userService,
+		logger:             logger,
+	}
+}
+
+func (h *PremiumHandlers) Register(r *bot.Router) {
+	r.Command("premium", "command.premium", h.premium)
+	r.Callback(callbackPrefix, h.buy)
+	r.On(bot.UpdatePreCheckoutQuery, h.preCheckout)
+	r.Message(h.successfulPayment)
+	r.Message(h.refundedPayment)
+}
+
+func (h *PremiumHandlers) premium(c *bot.Context) error {
+	var text strings.Builder
+	text.WriteString(c.T("premium.text"))
+	text.WriteString("\n")
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, product := range h.paymentService.Products() {
+		title := productTitle(c, product)
+		text.WriteString("\n" + c.T("premium.product_line", title, product.Days, product.Price))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(c.T("premium.buy", title, product.Price), callbackPrefix+":"+product.ID),
+		))
+	}
+
+	if user := c.CurrentUser(); user != nil {
+		entitlements, err := h.entitlementService.List(c, user.ID)
+		if err != nil {
+			h.logger.FromContext(c).Warnf("Failed to list entitlements of user %s: %v", user.ID, err)
+		}
+		if len(entitlements) > 0 {
+			text.WriteString("\n")
+		}
+
+		// Stacked purchases are listed by the date the last one ends.
+		for i, entitlement := range entitlements {
+			if i+1 < len(entitlements) && entitlements[i+1].Feature == entitlement.Feature {
+				continue
+			}
+			text.WriteString("\n" + c.T("premium.active", featureTitle(c, entitlement.Feature), entitlement.ExpiresAt.Format(dateLayout)))
+		}
+	}
+
+	reply := tgbotapi.NewMessage(c.ChatID(), text.String())
+	reply.ParseMode = tgbotapi.ModeHTML
+	reply.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	_, err := c.Send(reply)
+	return err
+}
+
+// buy handles "premium:<product>" by sending the Stars invoice.
+func (h *PremiumHandlers) buy(c *bot.Context) error {
+	err := h.paymentService.SendInvoice(c, c.ChatID(), c.Lang(), c.CallbackData())
+	if err != nil {
+		_ = c.AnswerCallback(c.T("premium.unavailable"))
+		if errors.Is(err, domain.ErrUnknownProduct) {
+			return nil
+		}
+		return err
+	}
+
+	return c.AnswerCallback("")
+}
+
+// preCheckout must be answered within ten seconds, or Telegram cancels the
+// payment.
+func (h *PremiumHandlers) preCheckout(c *bot.Context) error {
+	query := c.Update.PreCheckoutQuery
+
+	answer := tgbotapi.PreCheckoutConfig{PreCheckoutQueryID: query.ID, OK: true}
+	if err := h.paymentService.ValidateCheckout(query.InvoicePayload, query.Currency, query.TotalAmount); err != nil {
+		h.logger.FromContext(c).Warnf("Rejecting checkout of %q by %d: %v", query.InvoicePayload, query.From.ID, err)
+		answer.OK = false
+		answer.ErrorMessage = c.T("premium.checkout_failed")
+	}
+
+	_, err := c.Request(answer)
+	return err
+}
+
+func (h *PremiumHandlers) successfulPayment(c *bot.Context) error {
+	payment := c.Update.Message.SuccessfulPayment
+	sender := c.Sender()
+	if payment == nil || sender == nil {
+		return bot.ErrSkip
+	}
+
+	user := c.CurrentUser()
+	if user == nil {
+		var err error
+		if user, err = h.userService.EnsureTelegramUser(c, sender.ID, sender.UserName); err != nil {
+			return err
+		}
+	}
+
+	entitlement, err := h.paymentService.Complete(c, user.ID, domain.Receipt{
+		Payload:          payment.InvoicePayload,
+		Currency:         payment.Currency,
+		Amount:           payment.TotalAmount,
+		TelegramChargeID: payment.TelegramPaymentChargeID,
+		ProviderChargeID: payment.ProviderPaymentChargeID,
+	})
+	if errors.Is(err, domain.ErrDuplicatePayment) {
+		h.logger.FromContext(c).Infof("Ignoring repeated payment %s", payment.TelegramPaymentChargeID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.ReplyHTML(c.T("premium.activated", featureTitle(c, entitlement.Feature), entitlement.ExpiresAt.Format(dateLayout)))
+}
+
+// refundedPayment revokes what a refunded charge bought. Refunds the app
+// made itself were recorded already.
+func (h *PremiumHandlers) refundedPayment(c *bot.Context) error {
+	refund := c.RefundedPayment()
+	if refund == nil {
+		return bot.ErrSkip
+	}
+
+	_, err := h.paymentService.RecordRefund(c, refund.TelegramPaymentChargeID)
+	switch {
+	case errors.Is(err, domain.ErrAlreadyRefunded):
+		return nil
+	case errors.Is(err, domain.ErrPaymentNotFound):
+		h.logger.FromContext(c).Warnf("Refund of unknown payment %s", refund.TelegramPaymentChargeID)
+		return nil
+	case err != nil:
+		return err
+	}
+
+	return c.ReplyHTML(c.T("premium.refunded"))
+}
+
+func productTitle(c *bot.Context, product domain.Product) string {
+	return c.T("premium.product." + product.ID + ".title")
+}
+
+// featureTitle names a feature after the first product that sells it.
+func featureTitle(c *bot.Context, feature domain.Feature) string {
+	for _, product := range domain.Products {
+		if product.Feature == feature {
+			return productTitle(c, product)
+		}
+	}
+	return string(feature)
+}
@@ -0,0 +1,326 @@
+package handler_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/google/uuid"
+	"github.com/merdernoty/job-hunter/config"
+	"github.com/merdernoty/job-hunter/internal/bot"
+	"github.com/merdernoty/job-hunter/internal/payments/domain"
+	"github.com/merdernoty/job-hunter/internal/payments/handler"
+	"github.com/merdernoty/job-hunter/internal/payments/service"
+	userDomain "github.com/merdernoty/job-hunter/internal/users/domain"
+	"github.com/merdernoty/job-hunter/pkg/i18n"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+	"github.com/merdernoty/job-hunter/pkg/telegram/fakeapi"
+)
+
+const (
+	token  = "123:test"
+	userID = int64(42)
+)
+
+var product = domain.Products[0]
+
+func TestPaymentGrantsEntitlement(t *testing.T) {
+	h := newHarness(t)
+
+	h.api.InjectPreCheckout(userID, product.ID, domain.CurrencyStars, product.Price)
+	answers := h.waitForCalls(t, "answerPreCheckoutQuery", 1)
+	if answers[0].Params["ok"] != "true" {
+		t.Fatalf("checkout was rejected: %v", answers[0].Params)
+	}
+
+	h.api.InjectUpdate(successfulPayment("charge-1"))
+	h.waitForCalls(t, "sendMessage", 1)
+
+	if !h.entitlements.Has(context.Background(), h.users.id(userID), product.Feature) {
+		t.Fatalf("%s was not granted", product.Feature)
+	}
+}
+
+func TestDuplicatePaymentIsIgnored(t *testing.T) {
+	h := newHarness(t)
+
+	h.api.InjectUpdate(successfulPayment("charge-1"))
+	h.api.InjectUpdate(successfulPayment("charge-1"))
+	// Updates are handled in order, so the duplicate is done by the time
+	// the checkout after it is answered.
+	h.api.InjectPreCheckout(userID, product.ID, domain.CurrencyStars, product.Price)
+	h.waitForCalls(t, "answerPreCheckoutQuery", 1)
+
+	if sent := h.api.CallsTo("sendMessage"); len(sent) != 1 {
+		t.Fatalf("got %d confirmations, want 1", len(sent))
+	}
+	if granted := h.payments.entitlementCount(); granted != 1 {
+		t.Fatalf("got %d entitlements, want 1", granted)
+	}
+}
+
+func TestRefundRevokesEntitlement(t *testing.T) {
+	h := newHarness(t)
+
+	h.api.InjectUpdate(successfulPayment("charge-1"))
+	h.waitForCalls(t, "sendMessage", 1)
+
+	h.api.InjectRefundedPayment(userID, product.ID, domain.CurrencyStars, product.Price, "charge-1")
+	h.waitForCalls(t, "sendMessage", 2)
+
+	if h.entitlements.Has(context.Background(), h.users.id(userID), product.Feature) {
+		t.Fatalf("%s is still granted after the refund", product.Feature)
+	}
+	payment, err := h.payments.GetByChargeID(context.Background(), "charge-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payment.Status != domain.PaymentStatusRefunded {
+		t.Fatalf("payment status is %s, want %s", payment.Status, domain.PaymentStatusRefunded)
+	}
+}
+
+type harness struct {
+	api          *fakeapi.Server
+	payments     *memoryPayments
+	users        *telegramUsers
+	entitlements domain.EntitlementService
+}
+
+// newHarness runs a polling bot with the premium handlers against the fake
+// Bot API, with payments kept in memory.
+func newHarness(t *testing.T) *harness {
+	t.Helper()
+
+	api := fakeapi.NewServer(token)
+	server := httptest.NewServer(api)
+	t.Cleanup(func() {
+		// A getUpdates call may still be waiting for updates.
+		server.CloseClientConnections()
+		server.Close()
+	})
+
+	log := logger.NewLogger(&config.Config{Logger: config.Logger{Level: "error"}})
+	translator, err := i18n.NewTranslator()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := bot.NewRouter()
+	router.Use(bot.Localize(translator))
+	b, err := bot.NewBot(config.BotConfig{Token: token, APIEndpoint: fakeapi.Endpoint(server.URL)}, router, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := &harness{
+		api:      api,
+		payments: &memoryPayments{},
+		users:    &telegramUsers{ids: make(map[int64]uuid.UUID)},
+	}
+	h.entitlements = service.NewEntitlementService(h.payments, log)
+	payments := service.NewPaymentService(h.payments, h.users, translator, b, log)
+	handler.NewPremiumHandlers(payments, h.entitlements, h.users, log).Register(router)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = b.Start(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	return h
+}
+
+// waitForCalls waits until method was called n times and returns the calls.
+func (h *harness) waitForCalls(t *testing.T, method string, n int) []fakeapi.Call {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		calls := h.api.CallsTo(method)
+		if len(calls) >= n {
+			return calls
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d %s calls, want %d", len(calls), method, n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func successfulPayment(chargeID string) tgbotapi.Update {
+	return tgbotapi.Update{Message: &tgbotapi.Message{
+		MessageID: 1,
+		From:      &tgbotapi.User{ID: userID, FirstName: "User"},
+		Date:      int(time.Now().Unix()),
+		Chat:      &tgbotapi.Chat{ID: userID, Type: "private"},
+		SuccessfulPayment: &tgbotapi.SuccessfulPayment{
+			Currency:                domain.CurrencyStars,
+			TotalAmount:             product.Price,
+			InvoicePayload:          product.ID,
+			TelegramPaymentChargeID: chargeID,
+		},
+	}}
+}
+
+// telegramUsers registers Telegram users on first sight. Other methods of the
+// user service are not used by the premium handlers.
+type telegramUsers struct {
+	userDomain.UserService
+
+	mu  sync.Mutex
+	ids map[int64]uuid.UUID
+}
+
+func (u *telegramUsers) EnsureTelegramUser(ctx context.Context, telegramID int64, username string) (*userDomain.User, error) {
+	return &userDomain.User{ID: u.id(telegramID), TelegramID: telegramID, Username: username}, nil
+}
+
+func (u *telegramUsers) id(telegramID int64) uuid.UUID {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if _, ok := u.ids[telegramID]; !ok {
+		u.ids[telegramID] = uuid.New()
+	}
+	return u.ids[telegramID]
+}
+
+// memoryPayments keeps payments and entitlements in memory the way the
+// Postgres repositories do, without stacking.
+type memoryPayments struct {
+	mu           sync.Mutex
+	payments     []domain.Payment
+	entitlements []domain.Entitlement
+}
+
+func (m *memoryPayments) Record(ctx context.Context, payment *domain.Payment, feature domain.Feature, days int) (*domain.Entitlement, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, recorded := range m.payments {
+		if recorded.TelegramChargeID == payment.TelegramChargeID {
+			return nil, domain.ErrDuplicatePayment
+		}
+	}
+
+	now := time.Now()
+	payment.Status = domain.PaymentStatusPaid
+	payment.CreatedAt = now
+	m.payments = append(m.payments, *payment)
+
+	entitlement := domain.Entitlement{
+		ID:        uuid.New(),
+		UserID:    payment.UserID,
+		Feature:   feature,
+		PaymentID: payment.ID,
+		StartsAt:  now,
+		ExpiresAt: now.AddDate(0, 0, days),
+		CreatedAt: now,
+	}
+	m.entitlements = append(m.entitlements, entitlement)
+	return &entitlement, nil
+}
+
+func (m *memoryPayments) GetByID(ctx context.Context, id uuid.UUID) (*domain.Payment, error) {
+	return m.find(func(p domain.Payment) bool { return p.ID == id })
+}
+
+func (m *memoryPayments) GetByChargeID(ctx context.Context, telegramChargeID string) (*domain.Payment, error) {
+	return m.find(func(p domain.Payment) bool { return p.TelegramChargeID == telegramChargeID })
+}
+
+func (m *memoryPayments) ListByUser(ctx context.Context, userID uuid.UUID) ([]domain.Payment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var payments []domain.Payment
+	for _, payment := range m.payments {
+		if payment.UserID == userID {
+			payments = append(payments, payment)
+		}
+	}
+	return payments, nil
+}
+
+func (m *memoryPayments) Refund(ctx context.Context, id uuid.UUID) (*domain.Payment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for i := range m.payments {
+		payment := &m.payments[i]
+		if payment.ID != id {
+			continue
+		}
+		if payment.Status == domain.PaymentStatusRefunded {
+			return nil, domain.ErrAlreadyRefunded
+		}
+
+		payment.Status = domain.PaymentStatusRefunded
+		payment.RefundedAt = &now
+		for j := range m.entitlements {
+			if m.entitlements[j].PaymentID == id {
+				m.entitlements[j].RevokedAt = &now
+			}
+		}
+		refunded := *payment
+		return &refunded, nil
+	}
+	return nil, domain.ErrPaymentNotFound
+}
+
+func (m *memoryPayments) HasActive(ctx context.Context, userID uuid.UUID, feature domain.Feature) (bool, error) {
+	current, err := m.ListCurrent(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	for _, entitlement := range current {
+		if entitlement.Feature == feature && !entitlement.StartsAt.After(now) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *memoryPayments) ListCurrent(ctx context.Context, userID uuid.UUID) ([]domain.Entitlement, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	var current []domain.Entitlement
+	for _, entitlement := range m.entitlements {
+		if entitlement.UserID == userID && entitlement.RevokedAt == nil && entitlement.ExpiresAt.After(now) {
+			current = append(current, entitlement)
+		}
+	}
+	return current, nil
+}
+
+func (m *memoryPayments) entitlementCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.entitlements)
+}
+
+func (m *memoryPayments) find(match func(domain.Payment) bool) (*domain.Payment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, payment := range m.payments {
+		if match(payment) {
+			return &payment, nil
+		}
+	}
+	return nil, domain.ErrPaymentNotFound
+}
@@ -0,0 +1,36 @@
+package payments
+
+import (
+	"github.com/merdernoty/job-hunter/internal/payments/domain"
+	"github.com/merdernoty/job-hunter/internal/payments/handler"
+	"github.com/merdernoty/job-hunter/internal/payments/repository"
+	"github.com/merdernoty/job-hunter/internal/payments/service"
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module("payments",
+	fx.Provide(
+		fx.Annotate(
+			repository.NewPaymentRepository,
+			fx.As(new(domain.PaymentRepository)),
+		),
+		fx.Annotate(
+			repository.NewEntitlementRepository,
+			fx.As(new(domain.EntitlementRepository)),
+		),
+		fx.Annotate(
+			service.NewEntitlementService,
+			fx.As(new(domain.EntitlementService)),
+		),
+		fx.Annotate(
+			service.NewPaymentService,
+			fx.As(new(domain.PaymentService)),
+		),
+	),
+	fx.Provide(
+		fx.Annotate(
+			handler.NewPremiumHandlers,
+			fx.ResultTags(`group:"bot_handlers"`),
+		),
+	),
+)
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/merdernoty/job-hunter/internal/payments/domain"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+)
+
+type entitlementRepository struct {
+	db     *sqlx.DB
+	logger logger.Logger
+}
+
+func NewEntitlementRepository(db *sqlx.DB, logger logger.Logger) domain.EntitlementRepository {
+	return &entitlementRepository{db: db, logger: logger}
+}
+
+func (r *entitlementRepository) HasActive(ctx context.Context, userID uuid.UUID, feature domain.Feature) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM entitlements
+			WHERE user_id = $1 AND feature = $2 AND revoked_at IS NULL
+			  AND starts_at <= NOW() AND expires_at > NOW()
+		)`
+
+	var active bool
+	if err := r.db.GetContext(ctx, &active, query, userID, feature); err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to check %s for user %s: %v", feature, userID, err)
+		return false, fmt.Errorf("database error")
+	}
+
+	return active, nil
+}
+
+func (r *entitlementRepository) ListCurrent(ctx context.Context, userID uuid.UUID) ([]domain.Entitlement, error) {
+	entitlements := []domain.Entitlement{}
+	query := `
+		SELECT ` + entitlementColumns + `
+		FROM entitlements
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY feature, starts_at`
+
+	if err := r.db.SelectContext(ctx, &entitlements, query, userID); err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to list entitlements of user %s: %v", userID, err)
+		return nil, fmt.Errorf("database error")
+	}
+
+	return entitlements, nil
+}
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/merdernoty/job-hunter/internal/payments/domain"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+)
+
+const paymentColumns = `id, user_id, product, currency, amount, telegram_charge_id, provider_charge_id, status, created_at, refunded_at`
+
+const entitlementColumns = `id, user_id, feature, payment_id, starts_at, expires_at, revoked_at, created_at`
+
+type paymentRepository struct {
+	db     *sqlx.DB
+	logger logger.Logger
+}
+
+func NewPaymentRepository(db *sqlx.DB, logger logger.Logger) domain.PaymentRepository {
+	return &paymentRepository{db: db, logger: logger}
+}
+
+func (r *paymentRepository) Record(ctx context.Context, payment *domain.Payment, feature domain.Feature, days int) (*domain.Entitlement, error) {
+	if payment.ID == uuid.Nil {
+		payment.ID = uuid.New()
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	// Purchases of the same user are serialized so that each one stacks
+	// after all the others.
+	if _, err := tx.ExecContext(ctx, `SELECT 1 FROM users WHERE id = $1 FOR UPDATE`, payment.UserID); err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to lock user %s for payment: %v", payment.UserID, err)
+		return nil, fmt.Errorf("database error")
+	}
+
+	insert := `
+		INSERT INTO payments (id, user_id, product, currency, amount, telegram_charge_id, provider_charge_id, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (telegram_charge_id) DO NOTHING
+		RETURNING created_at`
+
+	err = tx.QueryRowContext(
+		ctx, insert,
+		payment.ID, payment.UserID, payment.Product, payment.Currency, payment.Amount,
+		payment.TelegramChargeID, payment.ProviderChargeID, domain.PaymentStatusPaid,
+	).Scan(&payment.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrDuplicatePayment
+	}
+	if err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to record payment %s: %v", payment.TelegramChargeID, err)
+		return nil, fmt.Errorf("failed to record payment")
+	}
+	payment.Status = domain.PaymentStatusPaid
+
+	entitlement := domain.Entitlement{
+		ID:        uuid.New(),
+		UserID:    payment.UserID,
+		Feature:   feature,
+		PaymentID: payment.ID,
+	}
+	grant := `
+		INSERT INTO entitlements (id, user_id, feature, payment_id, starts_at, expires_at)
+		SELECT $1, $2, $3, $4, start, start + make_interval(days => $5)
+		FROM (
+			SELECT GREATEST(NOW(), MAX(expires_at)) AS start
+			FROM entitlements
+			WHERE user_id = $2 AND feature = $3 AND revoked_at IS NULL
+		) latest
+		RETURNING starts_at, expires_at, created_at`
+
+	err = tx.QueryRowContext(ctx, grant, entitlement.ID, entitlement.UserID, entitlement.Feature, entitlement.PaymentID, days).
+		Scan(&entitlement.StartsAt, &entitlement.ExpiresAt, &entitlement.CreatedAt)
+	if err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to grant %s for payment %s: %v", feature, payment.ID, err)
+		return nil, fmt.Errorf("failed to grant entitlement")
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to commit payment %s: %v", payment.ID, err)
+		return nil, fmt.Errorf("database error")
+	}
+
+	return &entitlement, nil
+}
+
+func (r *paymentRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Payment, error) {
+	var payment domain.Payment
+	query := `SELECT ` + paymentColumns + ` FROM payments WHERE id = $1`
+
+	err := r.db.GetContext(ctx, &payment, query, id)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrPaymentNotFound
+	}
+	if err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to get payment %s: %v", id, err)
+		return nil, fmt.Errorf("database error")
+	}
+
+	return &payment, nil
+}
+
+func (r *paymentRepository) GetByChargeID(ctx context.Context, telegramChargeID string) (*domain.Payment, error) {
+	var payment domain.Payment
+	query := `SELECT ` + paymentColumns + ` FROM payments WHERE telegram_charge_id = $1`
+
+	err := r.db.GetContext(ctx, &payment, query, telegramChargeID)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrPaymentNotFound
+	}
+	if err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to get payment by charge %s: %v", telegramChargeID, err)
+		return nil, fmt.Errorf("database error")
+	}
+
+	return &payment, nil
+}
+
+func (r *paymentRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]domain.Payment, error) {
+	payments := []domain.Payment{}
+	query := `SELECT ` + paymentColumns + ` FROM payments WHERE user_id = $1 ORDER BY created_at DESC`
+
+	if err := r.db.SelectContext(ctx, &payments, query, userID); err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to list payments of user %s: %v", userID, err)
+		return nil, fmt.Errorf("database error")
+	}
+
+	return payments, nil
+}
+
+func (r *paymentRepository) Refund(ctx context.Context, id uuid.UUID) (*domain.Payment, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("database error")
+	}
+	defer tx.Rollback()
+
+	var payment domain.Payment
+	err = tx.GetContext(ctx, &payment, `SELECT `+paymentColumns+` FROM payments WHERE id = $1 FOR UPDATE`, id)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrPaymentNotFound
+	}
+	if err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to lock payment %s: %v", id, err)
+		return nil, fmt.Errorf("database error")
+	}
+	if payment.Status == domain.PaymentStatusRefunded {
+		return nil, domain.ErrAlreadyRefunded
+	}
+
+	err = tx.QueryRowContext(ctx, `UPDATE payments SET status = $2, refunded_at = NOW() WHERE id = $1 RETURNING refunded_at`, id, domain.PaymentStatusRefunded).
+		Scan(&payment.RefundedAt)
+	if err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to mark payment %s refunded: %v", id, err)
+		return nil, fmt.Errorf("database error")
+	}
+	payment.Status = domain.PaymentStatusRefunded
+
+	var revoked domain.Entitlement
+	revoke := `
+		UPDATE entitlements SET revoked_at = NOW()
+		WHERE payment_id = $1 AND revoked_at IS NULL
+		RETURNING ` + entitlementColumns
+	err = tx.GetContext(ctx, &revoked, revoke, id)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.FromContext(ctx).Errorf("Failed to revoke entitlement of payment %s: %v", id, err)
+		return nil, fmt.Errorf("database error")
+	}
+
+	if err == nil {
+		start := revoked.StartsAt
+		if now := time.Now(); now.After(start) {
+			start = now
+		}
+
+		if unused := revoked.ExpiresAt.Sub(start); unused > 0 {
+			shift := `
+				UPDATE entitlements
+				SET starts_at = starts_at - make_interval(secs => $4), expires_at = expires_at - make_interval(secs => $4)
+				WHERE user_id = $1 AND feature = $2 AND revoked_at IS NULL AND starts_at >= $3`
+			if _, err := tx.ExecContext(ctx, shift, revoked.UserID, revoked.Feature, revoked.ExpiresAt, unused.Seconds()); err != nil {
+				r.logger.FromContext(ctx).Errorf("Failed to move entitlements after payment %s: %v", id, err)
+				return nil, fmt.Errorf("database error")
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to commit refund of payment %s: %v", id, err)
+		return nil, fmt.Errorf("database error")
+	}
+
+	return &payment, nil
+}
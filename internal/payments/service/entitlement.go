@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/merdernoty/job-hunter/internal/payments/domain"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+)
+
+type entitlementService struct {
+	entitlementRepo domain.EntitlementRepository
+	logger          logger.Logger
+}
+
+func NewEntitlementService(entitlementRepo domain.EntitlementRepository, logger logger.Logger) domain.EntitlementService {
+	return &entitlementService{entitlementRepo: entitlementRepo, logger: logger}
+}
+
+func (s *entitlementService) Has(ctx context.Context, userID uuid.UUID, feature domain.Feature) bool {
+	active, err := s.entitlementRepo.HasActive(ctx, userID, feature)
+	if err != nil {
+		s.logger.FromContext(ctx).Warnf("Treating %s as missing for user %s: %v", feature, userID, err)
+		return false
+	}
+	return active
+}
+
+func (s *entitlementService) List(ctx context.Context, userID uuid.UUID) ([]domain.Entitlement, error) {
+	return s.entitlementRepo.ListCurrent(ctx, userID)
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/google/uuid"
+	"github.com/merdernoty/job-hunter/internal/bot"
+	"github.com/merdernoty/job-hunter/internal/payments/domain"
+	userDomain "github.com/merdernoty/job-hunter/internal/users/domain"
+	"github.com/merdernoty/job-hunter/pkg/i18n"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+)
+
+type paymentService struct {
+	paymentRepo domain.PaymentRepository
+	userService userDomain.UserService
+	translator  *i18n.Translator
+	bot         *bot.Bot
+	logger      logger.Logger
+}
+
+func NewPaymentService(
+	paymentRepo domain.PaymentRepository,
+	userService userDomain.UserService,
+	translator *i18n.Translator,
+	b *bot.Bot,
+	logger logger.Logger,
+) domain.PaymentService {
+	return &paymentService{
+		paymentRepo: paymentRepo,
+		userService: userService,
+		translator:  translator,
+		bot:         b,
+		logger:      logger,
+	}
+}
+
+func (s *paymentService) Products() []domain.Product {
+	return domain.Products
+}
+
+func (s *paymentService) SendInvoice(ctx context.Context, chatID int64, lang, productID string) error {
+	if s.bot == nil {
+		return domain.ErrBotUnavailable
+	}
+
+	invoice, err := s.invoice(lang, productID)
+	if err != nil {
+		return err
+	}
+	invoice.ChatID = chatID
+
+	_, err = s.bot.Send(invoice)
+	return err
+}
+
+func (s *paymentService) CreateInvoiceLink(ctx context.Context, lang, productID string) (string, error) {
+	if s.bot == nil {
+		return "", domain.ErrBotUnavailable
+	}
+
+	invoice, err := s.invoice(lang, productID)
+	if err != nil {
+		return "", err
+	}
+
+	return s.bot.CreateInvoiceLink(invoice)
+}
+
+// invoice describes a product for Stars: no provider token and the price in
+// whole Stars. The payload is the product ID; the payer is whoever Telegram
+// reports in the payment.
+func (s *paymentService) invoice(lang, productID string) (tgbotapi.InvoiceConfig, error) {
+	product, ok := domain.FindProduct(productID)
+	if !ok {
+		return tgbotapi.InvoiceConfig{}, domain.ErrUnknownProduct
+	}
+
+	key := "premium.product." + product.ID
+	title := s.translator.T(lang, key+".title")
+
+	return tgbotapi.InvoiceConfig{
+		Title:       title,
+		Description: s.translator.T(lang, key+".description"),
+		Payload:     product.ID,
+		Currency:    domain.CurrencyStars,
+		Prices:      []tgbotapi.LabeledPrice{{Label: title, Amount: product.Price}},
+		// The library sends nil as "null", which the Bot API rejects.
+		SuggestedTipAmounts: []int{},
+	}, nil
+}
+
+func (s *paymentService) ValidateCheckout(payload, currency string, amount int) error {
+	product, ok := domain.FindProduct(payload)
+	if !ok {
+		return domain.ErrUnknownProduct
+	}
+	if currency != domain.CurrencyStars || amount != product.Price {
+		return domain.ErrInvalidCheckout
+	}
+	return nil
+}
+
+// Complete grants the product even if its price changed after checkout: the
+// user has paid by then, and the amount is recorded as charged.
+func (s *paymentService) Complete(ctx context.Context, userID uuid.UUID, receipt domain.Receipt) (*domain.Entitlement, error) {
+	product, ok := domain.FindProduct(receipt.Payload)
+	if !ok {
+		s.logger.FromContext(ctx).Errorf("Payment %s is for unknown product %q", receipt.TelegramChargeID, receipt.Payload)
+		return nil, domain.ErrUnknownProduct
+	}
+
+	payment := &domain.Payment{
+		ID:               uuid.New(),
+		UserID:           userID,
+		Product:          product.ID,
+		Currency:         receipt.Currency,
+		Amount:           receipt.Amount,
+		TelegramChargeID: receipt.TelegramChargeID,
+	}
+	if receipt.ProviderChargeID != "" {
+		payment.ProviderChargeID = &receipt.ProviderChargeID
+	}
+
+	entitlement, err := s.paymentRepo.Record(ctx, payment, product.Feature, product.Days)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.FromContext(ctx).Infof("User %s paid %d %s for %s, %s active until %s",
+		userID, receipt.Amount, receipt.Currency, product.ID, product.Feature, entitlement.ExpiresAt)
+	return entitlement, nil
+}
+
+func (s *paymentService) Refund(ctx context.Context, paymentID uuid.UUID) (*domain.Payment, error) {
+	if s.bot == nil {
+		return nil, domain.ErrBotUnavailable
+	}
+
+	payment, err := s.paymentRepo.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status == domain.PaymentStatusRefunded {
+		return nil, domain.ErrAlreadyRefunded
+	}
+
+	user, err := s.userService.GetUser(ctx, payment.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A refund made outside of the app, e.g. by Telegram support, still has
+	// to revoke the entitlement here.
+	if err := s.bot.RefundStarPayment(user.TelegramID, payment.TelegramChargeID); err != nil && !isAlreadyRefunded(err) {
+		return nil, fmt.Errorf("failed to refund payment %s: %w", paymentID, err)
+	}
+
+	// Telegram's refunded_payment message may have been handled meanwhile.
+	refunded, err := s.paymentRepo.Refund(ctx, paymentID)
+	if errors.Is(err, domain.ErrAlreadyRefunded) {
+		return s.paymentRepo.GetByID(ctx, paymentID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.FromContext(ctx).Infof("Refunded payment %s of user %s for %s", paymentID, payment.UserID, payment.Product)
+	return refunded, nil
+}
+
+func (s *paymentService) RecordRefund(ctx context.Context, telegramChargeID string) (*domain.Payment, error) {
+	payment, err := s.paymentRepo.GetByChargeID(ctx, telegramChargeID)
+	if err != nil {
+		return nil, err
+	}
+
+	refunded, err := s.paymentRepo.Refund(ctx, payment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.FromContext(ctx).Infof("Telegram refunded payment %s of user %s for %s", payment.ID, payment.UserID, payment.Product)
+	return refunded, nil
+}
+
+func (s *paymentService) ListPayments(ctx context.Context, userID uuid.UUID) ([]domain.Payment, error) {
+	return s.paymentRepo.ListByUser(ctx, userID)
+}
+
+func isAlreadyRefunded(err error) bool {
+	var apiErr *tgbotapi.Error
+	return errors.As(err, &apiErr) && strings.Contains(apiErr.Message, "CHARGE_ALREADY_REFUNDED")
+}
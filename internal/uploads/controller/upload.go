@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/merdernoty/job-hunter/internal/uploads/domain"
+	"github.com/merdernoty/job-hunter/internal/users/middleware"
+	httpResponse "github.com/merdernoty/job-hunter/pkg/http"
+	"github.com/merdernoty/job-hunter/pkg/i18n"
+)
+
+type UploadController struct {
+	uploadService domain.UploadService
+}
+
+func NewUploadController(uploadService domain.UploadService) *UploadController {
+	return &UploadController{
+		uploadService: uploadService,
+	}
+}
+
+func (ctrl *UploadController) RegisterRoutes(rg *echo.Group, jwtMiddleware echo.MiddlewareFunc) {
+	uploads := rg.Group("/uploads", jwtMiddleware)
+	uploads.POST("", ctrl.create)
+	uploads.POST("/:id/complete", ctrl.complete)
+}
+
+func (ctrl *UploadController) create(c echo.Context) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return httpResponse.UnauthorizedResponse(c, i18n.Message(c, "api.auth.required"))
+	}
+
+	var req domain.CreateUploadRequest
+	if err := httpResponse.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	presigned, err := ctrl.uploadService.CreateUpload(c.Request().Context(), userID, req)
+	if err != nil {
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.uploads.create_failed"))
+	}
+
+	return httpResponse.CreatedResponse(c, presigned, i18n.Message(c, "api.uploads.created"))
+}
+
+func (ctrl *UploadController) complete(c echo.Context) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return httpResponse.UnauthorizedResponse(c, i18n.Message(c, "api.auth.required"))
+	}
+
+	uploadID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return httpResponse.BadRequestResponse(c, i18n.Message(c, "api.uploads.invalid_id"))
+	}
+
+	completed, err := ctrl.uploadService.CompleteUpload(c.Request().Context(), userID, uploadID)
+	if err != nil {
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.uploads.complete_failed"))
+	}
+
+	return httpResponse.SuccessResponse(c, completed, i18n.Message(c, "api.uploads.completed"))
+}
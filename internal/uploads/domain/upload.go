@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/merdernoty/job-hunter/pkg/apperr"
+)
+
+type UploadStatus string
+
+const (
+	UploadStatusPending UploadStatus = "pending"
+	// UploadStatusCompleting marks an upload claimed by a completion in
+	// progress, so that a concurrent or retried completion does not attach it
+	// again.
+	UploadStatusCompleting UploadStatus = "completing"
+	UploadStatusCompleted  UploadStatus = "completed"
+	UploadStatusRejected   UploadStatus = "rejected"
+	UploadStatusExpired    UploadStatus = "expired"
+)
+
+var (
+	ErrUploadNotFound   = apperr.NotFound("UPLOAD_NOT_FOUND", "upload not found")
+	ErrUploadExpired    = apperr.Gone("UPLOAD_EXPIRED", "upload expired")
+	ErrUploadNotPending = apperr.Conflict("UPLOAD_NOT_PENDING", "upload is not pending")
+	ErrUnknownPurpose   = apperr.Validation("UNKNOWN_PURPOSE", "unknown upload purpose")
+	// ErrUploadTooLarge and ErrUploadTypeNotAllowed reject the declared size
+	// and type. They share their codes with the storage errors that reject
+	// the uploaded content.
+	ErrUploadTooLarge       = apperr.TooLarge("FILE_TOO_LARGE", "upload exceeds maximum size")
+	ErrUploadTypeNotAllowed = apperr.Unsupported("UNSUPPORTED_FILE_TYPE", "upload content type not allowed")
+	ErrUploadObjectMissing  = apperr.Conflict("UPLOAD_MISSING", "uploaded object not found in storage")
+	ErrUploadSizeMismatch   = apperr.Validation("SIZE_MISMATCH", "uploaded object size does not match declared size")
+	ErrUploadQuotaExceeded  = apperr.TooLarge("QUOTA_EXCEEDED", "storage quota exceeded")
+)
+
+type Upload struct {
+	ID           uuid.UUID    `json:"id" db:"id"`
+	OwnerID      uuid.UUID    `json:"owner_id" db:"owner_id"`
+	Purpose      string       `json:"purpose" db:"purpose"`
+	FileName     *string      `json:"file_name" db:"file_name"`
+	ObjectKey    string       `json:"object_key" db:"object_key"`
+	ContentType  string       `json:"content_type" db:"content_type"`
+	DeclaredSize int64        `json:"declared_size" db:"declared_size"`
+	Size         *int64       `json:"size" db:"size"`
+	Checksum     *string      `json:"checksum" db:"checksum"`
+	Status       UploadStatus `json:"status" db:"status"`
+	ExpiresAt    time.Time    `json:"expires_at" db:"expires_at"`
+	CompletedAt  *time.Time   `json:"completed_at" db:"completed_at"`
+	CreatedAt    time.Time    `json:"created_at" db:"created_at"`
+}
+
+type CreateUploadRequest struct {
+	Purpose     string `json:"purpose" validate:"required,max=50"`
+	ContentType string `json:"content_type" validate:"required,max=100"`
+	Size        int64  `json:"size" validate:"required,min=1"`
+	FileName    string `json:"file_name,omitempty" validate:"omitempty,max=255"`
+}
+
+type PresignedUpload struct {
+	UploadID  uuid.UUID         `json:"upload_id"`
+	Method    string            `json:"method"`
+	URL       string            `json:"url"`
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+type CompletedUpload struct {
+	Upload   *Upload     `json:"upload"`
+	Attached interface{} `json:"attached,omitempty"`
+}
+
+// UploadRules constrain what may be uploaded for a purpose.
+type UploadRules struct {
+	KeyPrefix    string
+	MaxSize      int64
+	AllowedTypes []string
+}
+
+// UploadAttacher links a verified upload to the entity that owns it. Modules
+// register attachers into the "upload_attachers" fx group, one per purpose.
+type UploadAttacher interface {
+	Purpose() string
+	Rules() UploadRules
+	Attach(ctx context.Context, upload *Upload) (interface{}, error)
+}
+
+// UploadPrechecker is implemented by attachers that can refuse an upload
+// before a URL is issued, e.g. when the owner is out of quota.
+type UploadPrechecker interface {
+	Precheck(ctx context.Context, ownerID uuid.UUID, size int64) error
+}
+
+type UploadRepository interface {
+	Create(ctx context.Context, upload *Upload) error
+	GetByID(ctx context.Context, id uuid.UUID) (*Upload, error)
+	// Claim moves a pending upload to completing. It fails with
+	// ErrUploadNotPending when the upload is no longer pending.
+	Claim(ctx context.Context, id uuid.UUID) error
+	MarkCompleted(ctx context.Context, id uuid.UUID, size int64, contentType, checksum string) error
+	// MarkStatus moves the upload from one status to another and reports
+	// whether it was still in the from status.
+	MarkStatus(ctx context.Context, id uuid.UUID, from, to UploadStatus) (bool, error)
+	// GetExpired returns pending uploads that expired before before, and
+	// uploads claimed before staleBefore whose completion never finished.
+	GetExpired(ctx context.Context, before, staleBefore time.Time, limit int) ([]Upload, error)
+	// GetPendingObjectKeys returns which of keys belong to uploads that are
+	// pending or being completed.
+	GetPendingObjectKeys(ctx context.Context, keys []string) ([]string, error)
+}
+
+type UploadService interface {
+	CreateUpload(ctx context.Context, ownerID uuid.UUID, req CreateUploadRequest) (*PresignedUpload, error)
+	CompleteUpload(ctx context.Context, ownerID, uploadID uuid.UUID) (*CompletedUpload, error)
+	ExpirePendingUploads(ctx context.Context) (int, error)
+}
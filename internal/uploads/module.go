@@ -0,0 +1,83 @@
+package uploads
+
+import (
+	"context"
+	"time"
+
+	"github.com/merdernoty/job-hunter/config"
+	"github.com/merdernoty/job-hunter/internal/uploads/domain"
+	"github.com/merdernoty/job-hunter/internal/uploads/repository"
+	"github.com/merdernoty/job-hunter/internal/uploads/service"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module("uploads",
+	fx.Provide(
+		fx.Annotate(
+			repository.NewUploadRepository,
+			fx.As(new(domain.UploadRepository)),
+		),
+	),
+	fx.Provide(
+		fx.Annotate(
+			service.NewUploadService,
+			fx.ParamTags(``, ``, ``, ``, `group:"upload_attachers"`),
+			fx.As(new(domain.UploadService)),
+		),
+		fx.Annotate(
+			service.NewUploadReferenceSource,
+			fx.ParamTags(``, `group:"upload_attachers"`),
+			fx.ResultTags(`group:"storage_references"`),
+		),
+	),
+	fx.Invoke(RunExpiryJob),
+)
+
+// RunExpiryJob periodically expires pending uploads that were never completed
+// and removes whatever was written to storage for them.
+func RunExpiryJob(lc fx.Lifecycle, uploadService domain.UploadService, cfg *config.Config, log logger.Logger) {
+	interval := cfg.Uploads.CleanupInterval
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+
+	var cancel context.CancelFunc
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			jobCtx, jobCancel := context.WithCancel(context.Background())
+			cancel = jobCancel
+
+			go func() {
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-jobCtx.Done():
+						return
+					case <-ticker.C:
+						expired, err := uploadService.ExpirePendingUploads(jobCtx)
+						if err != nil {
+							log.Errorf("Failed to expire pending uploads: %v", err)
+							continue
+						}
+						if expired > 0 {
+							log.Infof("Expired %d pending uploads", expired)
+						}
+					}
+				}
+			}()
+
+			log.Infof("Upload expiry job started (interval: %v)", interval)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			if cancel != nil {
+				cancel()
+			}
+			return nil
+		},
+	})
+}
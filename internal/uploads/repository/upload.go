@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/merdernoty/job-hunter/internal/uploads/domain"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+)
+
+type uploadRepository struct {
+	db     *sqlx.DB
+	logger logger.Logger
+}
+
+func NewUploadRepository(db *sqlx.DB, logger logger.Logger) domain.UploadRepository {
+	return &uploadRepository{db: db, logger: logger}
+}
+
+func (r *uploadRepository) Create(ctx context.Context, upload *domain.Upload) error {
+	if upload.ID == uuid.Nil {
+		upload.ID = uuid.New()
+	}
+
+	query := `
+		INSERT INTO uploads (id, owner_id, purpose, file_name, object_key, content_type, declared_size, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING created_at`
+
+	err := r.db.QueryRowContext(
+		ctx, query,
+		upload.ID, upload.OwnerID, upload.Purpose, upload.FileName, upload.ObjectKey,
+		upload.ContentType, upload.DeclaredSize, upload.Status, upload.ExpiresAt,
+	).Scan(&upload.CreatedAt)
+	if err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to create upload: %v", err)
+		return fmt.Errorf("failed to create upload")
+	}
+
+	return nil
+}
+
+func (r *uploadRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Upload, error) {
+	var upload domain.Upload
+	query := `
+		SELECT id, owner_id, purpose, file_name, object_key, content_type, declared_size, size, checksum, status, expires_at, completed_at, created_at
+		FROM uploads
+		WHERE id = $1`
+
+	err := r.db.GetContext(ctx, &upload, query, id)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrUploadNotFound
+	}
+	if err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to get upload %s: %v", id, err)
+		return nil, fmt.Errorf("database error")
+	}
+
+	return &upload, nil
+}
+
+func (r *uploadRepository) Claim(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE uploads
+		SET status = $1, claimed_at = NOW()
+		WHERE id = $2 AND status = $3
+		RETURNING id`
+
+	var claimed uuid.UUID
+	err := r.db.QueryRowContext(ctx, query, domain.UploadStatusCompleting, id, domain.UploadStatusPending).Scan(&claimed)
+	if err == sql.ErrNoRows {
+		return domain.ErrUploadNotPending
+	}
+	if err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to claim upload %s: %v", id, err)
+		return fmt.Errorf("database error")
+	}
+
+	return nil
+}
+
+func (r *uploadRepository) MarkCompleted(ctx context.Context, id uuid.UUID, size int64, contentType, checksum string) error {
+	query := `
+		UPDATE uploads
+		SET status = $1, size = $2, content_type = $3, checksum = $4, completed_at = NOW()
+		WHERE id = $5 AND status = $6`
+
+	result, err := r.db.ExecContext(ctx, query, domain.UploadStatusCompleted, size, contentType, checksum, id, domain.UploadStatusCompleting)
+	if err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to complete upload %s: %v", id, err)
+		return fmt.Errorf("database error")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("database error")
+	}
+	if rowsAffected == 0 {
+		return domain.ErrUploadNotPending
+	}
+
+	return nil
+}
+
+func (r *uploadRepository) MarkStatus(ctx context.Context, id uuid.UUID, from, to domain.UploadStatus) (bool, error) {
+	query := `UPDATE uploads SET status = $1 WHERE id = $2 AND status = $3`
+
+	result, err := r.db.ExecContext(ctx, query, to, id, from)
+	if err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to move upload %s from %s to %s: %v", id, from, to, err)
+		return false, fmt.Errorf("database error")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("database error")
+	}
+
+	return rowsAffected > 0, nil
+}
+
+func (r *uploadRepository) GetExpired(ctx context.Context, before, staleBefore time.Time, limit int) ([]domain.Upload, error) {
+	var uploads []domain.Upload
+	query := `
+		SELECT id, owner_id, purpose, file_name, object_key, content_type, declared_size, size, checksum, status, expires_at, completed_at, created_at
+		FROM uploads
+		WHERE (status = $1 AND expires_at < $2)
+		   OR (status = $3 AND claimed_at < $4)
+		ORDER BY expires_at ASC
+		LIMIT $5`
+
+	err := r.db.SelectContext(ctx, &uploads, query, domain.UploadStatusPending, before, domain.UploadStatusCompleting, staleBefore, limit)
+	if err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to get expired uploads: %v", err)
+		return nil, fmt.Errorf("database error")
+	}
+
+	return uploads, nil
+}
+
+func (r *uploadRepository) GetPendingObjectKeys(ctx context.Context, keys []string) ([]string, error) {
+	var pending []string
+	query := `
+		SELECT object_key
+		FROM uploads
+		WHERE status IN ($1, $2) AND object_key = ANY($3)`
+
+	err := r.db.SelectContext(ctx, &pending, query, domain.UploadStatusPending, domain.UploadStatusCompleting, pq.Array(keys))
+	if err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to get pending upload keys: %v", err)
+		return nil, fmt.Errorf("database error")
+	}
+
+	return pending, nil
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"github.com/merdernoty/job-hunter/internal/uploads/domain"
+	"github.com/merdernoty/job-hunter/pkg/storage"
+)
+
+// uploadReferenceSource keeps objects of pending uploads alive until they are
+// completed or expired, and owns the prefixes of every upload purpose.
+type uploadReferenceSource struct {
+	uploadRepo domain.UploadRepository
+	attachers  []domain.UploadAttacher
+}
+
+func NewUploadReferenceSource(uploadRepo domain.UploadRepository, attachers []domain.UploadAttacher) storage.ReferenceSource {
+	return &uploadReferenceSource{
+		uploadRepo: uploadRepo,
+		attachers:  attachers,
+	}
+}
+
+func (s *uploadReferenceSource) Name() string {
+	return "uploads.pending"
+}
+
+func (s *uploadReferenceSource) Prefixes() []string {
+	prefixes := make([]string, 0, len(s.attachers))
+	for _, attacher := range s.attachers {
+		prefixes = append(prefixes, attacher.Rules().KeyPrefix)
+	}
+	return prefixes
+}
+
+func (s *uploadReferenceSource) ReferencedKeys(ctx context.Context, keys []string) (map[string]bool, error) {
+	pending, err := s.uploadRepo.GetPendingObjectKeys(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]bool, len(pending))
+	for _, key := range pending {
+		result[key] = true
+	}
+	return result, nil
+}
@@ -0,0 +1,308 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/merdernoty/job-hunter/config"
+	"github.com/merdernoty/job-hunter/internal/uploads/domain"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+	"github.com/merdernoty/job-hunter/pkg/storage"
+)
+
+const expireBatchSize = 100
+
+const (
+	// completionGrace keeps an upload completable for a while after its URL
+	// expires, so that a PUT finishing right at the deadline still counts.
+	completionGrace = 5 * time.Minute
+	// claimTimeout is how long a completion may hold its claim before the
+	// upload is considered abandoned, e.g. after a crash.
+	claimTimeout = 10 * time.Minute
+)
+
+type uploadService struct {
+	uploadRepo  domain.UploadRepository
+	minioClient *storage.MinIOClient
+	attachers   map[string]domain.UploadAttacher
+	presignTTL  time.Duration
+	logger      logger.Logger
+}
+
+func NewUploadService(
+	uploadRepo domain.UploadRepository,
+	minioClient *storage.MinIOClient,
+	cfg *config.Config,
+	logger logger.Logger,
+	attachers []domain.UploadAttacher,
+) domain.UploadService {
+	byPurpose := make(map[string]domain.UploadAttacher, len(attachers))
+	for _, attacher := range attachers {
+		byPurpose[attacher.Purpose()] = attacher
+	}
+
+	return &uploadService{
+		uploadRepo:  uploadRepo,
+		minioClient: minioClient,
+		attachers:   byPurpose,
+		presignTTL:  cfg.Uploads.PresignTTL,
+		logger:      logger,
+	}
+}
+
+func (s *uploadService) CreateUpload(ctx context.Context, ownerID uuid.UUID, req domain.CreateUploadRequest) (*domain.PresignedUpload, error) {
+	attacher, ok := s.attachers[req.Purpose]
+	if !ok {
+		return nil, domain.ErrUnknownPurpose
+	}
+	rules := attacher.Rules()
+
+	if req.Size > rules.MaxSize {
+		return nil, domain.ErrUploadTooLarge.WithDetails(fmt.Sprintf("maximum size is %d bytes", rules.MaxSize))
+	}
+
+	contentType := storage.MediaType(req.ContentType)
+	ext := storage.ExtensionForType(contentType)
+	if ext == "" || !isAllowedType(rules.AllowedTypes, contentType) {
+		return nil, domain.ErrUploadTypeNotAllowed.WithDetails("allowed types are " + strings.Join(rules.AllowedTypes, ", "))
+	}
+
+	if prechecker, ok := attacher.(domain.UploadPrechecker); ok {
+		if err := prechecker.Precheck(ctx, ownerID, req.Size); err != nil {
+			return nil, err
+		}
+	}
+
+	upload := &domain.Upload{
+		ID:           uuid.New(),
+		OwnerID:      ownerID,
+		Purpose:      req.Purpose,
+		FileName:     optionalString(req.FileName),
+		ContentType:  contentType,
+		DeclaredSize: req.Size,
+		Status:       domain.UploadStatusPending,
+		ExpiresAt:    time.Now().Add(s.presignTTL + completionGrace),
+	}
+	upload.ObjectKey = fmt.Sprintf("%s/%s/%d_%s%s", rules.KeyPrefix, ownerID, time.Now().Unix(), upload.ID, ext)
+
+	url, headers, err := s.minioClient.PresignPut(upload.ObjectKey, upload.ContentType, upload.DeclaredSize, s.presignTTL)
+	if err != nil {
+		s.logger.FromContext(ctx).Errorf("Failed to presign upload for user %s: %v", ownerID, err)
+		return nil, err
+	}
+
+	if err := s.uploadRepo.Create(ctx, upload); err != nil {
+		return nil, err
+	}
+
+	s.logger.FromContext(ctx).Infof("Issued %s upload %s for user %s", upload.Purpose, upload.ID, ownerID)
+	return &domain.PresignedUpload{
+		UploadID:  upload.ID,
+		Method:    http.MethodPut,
+		URL:       url,
+		Headers:   headersToMap(headers),
+		ExpiresAt: upload.ExpiresAt.Add(-completionGrace),
+	}, nil
+}
+
+func (s *uploadService) CompleteUpload(ctx context.Context, ownerID, uploadID uuid.UUID) (*domain.CompletedUpload, error) {
+	upload, err := s.uploadRepo.GetByID(ctx, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	if upload.OwnerID != ownerID {
+		return nil, domain.ErrUploadNotFound
+	}
+	if upload.Status != domain.UploadStatusPending {
+		return nil, domain.ErrUploadNotPending
+	}
+	if time.Now().After(upload.ExpiresAt) {
+		return nil, domain.ErrUploadExpired
+	}
+
+	attacher, ok := s.attachers[upload.Purpose]
+	if !ok {
+		return nil, domain.ErrUnknownPurpose
+	}
+
+	// Only the completion that wins the claim attaches the upload; failures
+	// the client can retry hand the claim back.
+	if err := s.uploadRepo.Claim(ctx, upload.ID); err != nil {
+		return nil, err
+	}
+
+	info, err := s.minioClient.StatObject(upload.ObjectKey)
+	if err != nil {
+		s.release(ctx, upload)
+		if storage.IsNotFound(err) {
+			return nil, domain.ErrUploadObjectMissing
+		}
+		s.logger.FromContext(ctx).Errorf("Failed to stat upload %s: %v", upload.ID, err)
+		return nil, fmt.Errorf("failed to verify upload")
+	}
+
+	if info.Size != upload.DeclaredSize {
+		s.reject(ctx, upload)
+		return nil, domain.ErrUploadSizeMismatch
+	}
+
+	sniffed, err := s.verifyContent(ctx, upload, attacher.Rules())
+	if err != nil {
+		if isContentRejection(err) {
+			s.reject(ctx, upload)
+		} else {
+			s.release(ctx, upload)
+		}
+		return nil, err
+	}
+
+	checksum := sniffed.Checksum()
+	upload.Size = &sniffed.Size
+	upload.ContentType = sniffed.ContentType
+	upload.Checksum = &checksum
+
+	attached, err := attacher.Attach(ctx, upload)
+	if err != nil {
+		s.logger.FromContext(ctx).Errorf("Failed to attach upload %s: %v", upload.ID, err)
+		s.release(ctx, upload)
+		return nil, err
+	}
+
+	// The upload is attached by now, so the client hanging up must not keep
+	// it from being marked completed.
+	if err := s.uploadRepo.MarkCompleted(context.WithoutCancel(ctx), upload.ID, sniffed.Size, sniffed.ContentType, checksum); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	upload.Status = domain.UploadStatusCompleted
+	upload.CompletedAt = &now
+
+	s.logger.FromContext(ctx).Infof("Completed %s upload %s for user %s", upload.Purpose, upload.ID, ownerID)
+	return &domain.CompletedUpload{
+		Upload:   upload,
+		Attached: attached,
+	}, nil
+}
+
+func (s *uploadService) ExpirePendingUploads(ctx context.Context) (int, error) {
+	expired := 0
+
+	for {
+		now := time.Now()
+		uploads, err := s.uploadRepo.GetExpired(ctx, now, now.Add(-claimTimeout), expireBatchSize)
+		if err != nil {
+			return expired, err
+		}
+
+		for i := range uploads {
+			upload := &uploads[i]
+			// A completion that claimed the upload meanwhile keeps it.
+			ok, err := s.uploadRepo.MarkStatus(ctx, upload.ID, upload.Status, domain.UploadStatusExpired)
+			if err != nil {
+				return expired, err
+			}
+			if !ok {
+				continue
+			}
+			expired++
+
+			// An abandoned completion may have attached the object already,
+			// so it is left to storage GC, which checks references.
+			if upload.Status == domain.UploadStatusCompleting {
+				continue
+			}
+			if err := s.minioClient.RemoveObject(upload.ObjectKey); err != nil && !storage.IsNotFound(err) {
+				s.logger.FromContext(ctx).Warnf("Failed to remove expired upload object %s: %v", upload.ObjectKey, err)
+			}
+		}
+
+		if len(uploads) < expireBatchSize {
+			return expired, nil
+		}
+	}
+}
+
+func (s *uploadService) verifyContent(ctx context.Context, upload *domain.Upload, rules domain.UploadRules) (*storage.SniffedFile, error) {
+	object, err := s.minioClient.GetObject(upload.ObjectKey)
+	if err != nil {
+		s.logger.FromContext(ctx).Errorf("Failed to read upload %s: %v", upload.ID, err)
+		return nil, fmt.Errorf("failed to verify upload")
+	}
+	defer object.Close()
+
+	sniffed, err := storage.ReadAndSniff(object, rules.MaxSize, upload.ContentType, "", rules.AllowedTypes)
+	if err != nil {
+		s.logger.FromContext(ctx).Warnf("Rejected upload %s: %v", upload.ID, err)
+		return nil, err
+	}
+
+	return sniffed, nil
+}
+
+// reject removes the object of a claimed upload that failed verification.
+func (s *uploadService) reject(ctx context.Context, upload *domain.Upload) {
+	ok, err := s.uploadRepo.MarkStatus(context.WithoutCancel(ctx), upload.ID, domain.UploadStatusCompleting, domain.UploadStatusRejected)
+	if err != nil {
+		s.logger.FromContext(ctx).Warnf("Failed to mark upload %s as rejected: %v", upload.ID, err)
+		return
+	}
+	if !ok {
+		s.logger.FromContext(ctx).Warnf("Upload %s was no longer claimed when rejected", upload.ID)
+		return
+	}
+	if err := s.minioClient.RemoveObject(upload.ObjectKey); err != nil {
+		s.logger.FromContext(ctx).Warnf("Failed to remove rejected upload object %s: %v", upload.ObjectKey, err)
+	}
+}
+
+// release returns a claimed upload to pending so that it can be completed
+// again, also when the request was cancelled. Uploads expired meanwhile
+// stay expired.
+func (s *uploadService) release(ctx context.Context, upload *domain.Upload) {
+	ok, err := s.uploadRepo.MarkStatus(context.WithoutCancel(ctx), upload.ID, domain.UploadStatusCompleting, domain.UploadStatusPending)
+	if err != nil {
+		s.logger.FromContext(ctx).Warnf("Failed to release upload %s: %v", upload.ID, err)
+		return
+	}
+	if !ok {
+		s.logger.FromContext(ctx).Warnf("Upload %s was no longer claimed when released", upload.ID)
+	}
+}
+
+func isContentRejection(err error) bool {
+	return errors.Is(err, storage.ErrEmptyFile) ||
+		errors.Is(err, storage.ErrFileTooLarge) ||
+		errors.Is(err, storage.ErrUnsupportedFileType) ||
+		errors.Is(err, storage.ErrContentTypeMismatch) ||
+		errors.Is(err, storage.ErrPolyglotFile)
+}
+
+func isAllowedType(allowed []string, contentType string) bool {
+	for _, t := range allowed {
+		if t == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func headersToMap(h http.Header) map[string]string {
+	m := make(map[string]string, len(h))
+	for key := range h {
+		m[key] = h.Get(key)
+	}
+	return m
+}
@@ -1,22 +1,51 @@
 package controller
 
 import (
-	"strings"
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
+	notificationDomain "github.com/merdernoty/job-hunter/internal/notifications/domain"
 	"github.com/merdernoty/job-hunter/internal/users/domain"
 	"github.com/merdernoty/job-hunter/internal/users/middleware"
+	"github.com/merdernoty/job-hunter/internal/users/service"
+	"github.com/merdernoty/job-hunter/pkg/deeplink"
 	httpResponse "github.com/merdernoty/job-hunter/pkg/http"
+	"github.com/merdernoty/job-hunter/pkg/http/pagination"
+	"github.com/merdernoty/job-hunter/pkg/i18n"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+	"github.com/merdernoty/job-hunter/pkg/storage"
 )
 
 type UserController struct {
 	userService domain.UserService
+	urlResolver storage.URLResolver
+	notifier    notificationDomain.Notifier
+	deepLinks   *deeplink.Codec
+	matches     *service.MatchNotifier
+	logger      logger.Logger
 }
 
-func NewUserController(userService domain.UserService) *UserController {
+func NewUserController(
+	userService domain.UserService,
+	urlResolver storage.URLResolver,
+	notifier notificationDomain.Notifier,
+	deepLinks *deeplink.Codec,
+	matches *service.MatchNotifier,
+	logger logger.Logger,
+) *UserController {
 	return &UserController{
 		userService: userService,
+		urlResolver: urlResolver,
+		notifier:    notifier,
+		deepLinks:   deepLinks,
+		matches:     matches,
+		logger:      logger,
 	}
 }
 
@@ -30,12 +59,15 @@ func (ctrl *UserController) RegisterRoutes(rg *echo.Group, jwtMiddleware echo.Mi
 	users.GET("", ctrl.getUsers)
 	users.GET("/:id", ctrl.getByID)
 	users.PUT("/:id", ctrl.update)
+	users.POST("/:id/swipe", ctrl.swipe)
 
 	// Profile routes
 	users.GET("/me", ctrl.getProfile)
 	users.PUT("/me", ctrl.updateProfile)
+	users.PATCH("/me", ctrl.patchProfile)
 	users.PUT("/me/avatar", ctrl.updateAvatar)
 	users.DELETE("/me/avatar", ctrl.deleteAvatar)
+	users.GET("/me/viewers", ctrl.getViewers)
 
 	// Match routes
 	users.GET("/random", ctrl.getRandomUser)
@@ -48,55 +80,79 @@ func (ctrl *UserController) authTelegram(c echo.Context) error {
 		return err
 	}
 
-	user, token, err := ctrl.userService.AuthFromTelegram(req.InitData)
+	result, err := ctrl.userService.AuthFromTelegram(c.Request().Context(), req)
 	if err != nil {
-		switch err.Error() {
-		case "invalid telegram data":
-			return httpResponse.BadRequestResponse(c, "Invalid Telegram data")
-		case "database error":
-			return httpResponse.InternalServerErrorResponse(c, "Database error")
-		default:
-			return httpResponse.UnauthorizedResponse(c, "Authentication failed")
-		}
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.auth.failed"))
 	}
 
-	return httpResponse.SuccessResponse(c, map[string]interface{}{
-		"user":  user,
-		"token": token,
-	})
+	response := map[string]interface{}{
+		"user":  ctrl.withAvatarURL(c.Request().Context(), result.User),
+		"token": result.Token,
+	}
+	if result.Target != nil {
+		response["navigate"] = result.Target
+	}
+
+	return httpResponse.SuccessResponse(c, response)
 }
 
 func (ctrl *UserController) getByID(c echo.Context) error {
 	idParam := c.Param("id")
 	if idParam == "" {
-		return httpResponse.BadRequestResponse(c, "User ID is required")
+		return httpResponse.BadRequestResponse(c, i18n.Message(c, "api.users.id_required"))
 	}
 
 	userID, err := uuid.Parse(idParam)
 	if err != nil {
-		return httpResponse.BadRequestResponse(c, "Invalid user ID format")
+		return httpResponse.BadRequestResponse(c, i18n.Message(c, "api.users.invalid_id"))
 	}
 
-	user, err := ctrl.userService.GetUser(userID)
+	user, err := ctrl.userService.GetUser(c.Request().Context(), userID)
 	if err != nil {
-		if err.Error() == "user not found" {
-			return httpResponse.NotFoundResponse(c, "User not found")
-		}
-		return httpResponse.InternalServerErrorResponse(c, "Failed to retrieve user")
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.users.get_failed"))
 	}
 
-	return httpResponse.SuccessResponse(c, user)
+	if viewerID, ok := middleware.GetUserID(c); ok && viewerID != user.ID {
+		ctrl.userService.RecordProfileView(c.Request().Context(), viewerID, user.ID)
+		ctrl.notifyProfileViewed(c.Request().Context(), viewerID, user.ID)
+	}
+
+	return httpResponse.SuccessResponse(c, ctrl.withAvatarURL(c.Request().Context(), user))
+}
+
+// notifyProfileViewed tells the owner who looked at their profile, at most
+// once per viewer and day. It is best effort and never fails the request.
+func (ctrl *UserController) notifyProfileViewed(ctx context.Context, viewerID, ownerID uuid.UUID) {
+	viewer, err := ctrl.userService.GetUser(ctx, viewerID)
+	if err != nil {
+		return
+	}
+
+	name := viewer.Username
+	if name == "" {
+		name = viewer.TelegramHandle
+	}
+
+	startParam, _ := ctrl.deepLinks.Encode(deeplink.Link{Type: deeplink.Profile, ID: viewer.ID})
+
+	_, _ = ctrl.notifier.Notify(ctx, ownerID, notificationDomain.Message{
+		Kind:       notificationDomain.KindProfileViewed,
+		Key:        "notifications.profile_viewed",
+		Args:       []interface{}{html.EscapeString(name)},
+		StartParam: startParam,
+		DedupeKey:  fmt.Sprintf("profile_viewed:%s:%s:%s", viewerID, ownerID, time.Now().UTC().Format("2006-01-02")),
+	})
 }
 
 func (ctrl *UserController) update(c echo.Context) error {
 	idParam := c.Param("id")
 	if idParam == "" {
-		return httpResponse.BadRequestResponse(c, "User ID is required")
+		return httpResponse.BadRequestResponse(c, i18n.Message(c, "api.users.id_required"))
 	}
 
 	userID, err := uuid.Parse(idParam)
 	if err != nil {
-		return httpResponse.BadRequestResponse(c, "Invalid user ID format")
+		return httpResponse.BadRequestResponse(c, i18n.Message(c, "api.users.invalid_id"))
 	}
 
 	var req domain.UpdateUserRequest
@@ -104,41 +160,32 @@ func (ctrl *UserController) update(c echo.Context) error {
 		return err
 	}
 
-	user, err := ctrl.userService.UpdateUser(userID, req)
+	user, err := ctrl.userService.UpdateUser(c.Request().Context(), userID, req)
 	if err != nil {
-		if err.Error() == "user not found" {
-			return httpResponse.NotFoundResponse(c, "User not found")
-		}
-		if err.Error() == "no fields to update" {
-			return httpResponse.BadRequestResponse(c, "No fields to update")
-		}
-		return httpResponse.InternalServerErrorResponse(c, "Failed to update user")
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.users.update_failed"))
 	}
 
-	return httpResponse.SuccessResponse(c, user)
+	return httpResponse.SuccessResponse(c, ctrl.withAvatarURL(c.Request().Context(), user))
 }
 
 func (ctrl *UserController) getProfile(c echo.Context) error {
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		return httpResponse.UnauthorizedResponse(c, "Authentication required")
+		return httpResponse.UnauthorizedResponse(c, i18n.Message(c, "api.auth.required"))
 	}
 
-	user, err := ctrl.userService.GetUser(userID)
+	user, err := ctrl.userService.GetUser(c.Request().Context(), userID)
 	if err != nil {
-		if err.Error() == "user not found" {
-			return httpResponse.NotFoundResponse(c, "User not found")
-		}
-		return httpResponse.InternalServerErrorResponse(c, "Failed to retrieve profile")
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.users.profile_failed"))
 	}
 
-	return httpResponse.SuccessResponse(c, user)
+	return httpResponse.SuccessResponse(c, ctrl.withAvatarURL(c.Request().Context(), user))
 }
 
 func (ctrl *UserController) updateProfile(c echo.Context) error {
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		return httpResponse.UnauthorizedResponse(c, "Authentication required")
+		return httpResponse.UnauthorizedResponse(c, i18n.Message(c, "api.auth.required"))
 	}
 
 	var req domain.UpdateUserRequest
@@ -146,77 +193,77 @@ func (ctrl *UserController) updateProfile(c echo.Context) error {
 		return err
 	}
 
-	user, err := ctrl.userService.UpdateUser(userID, req)
+	user, err := ctrl.userService.UpdateUser(c.Request().Context(), userID, req)
 	if err != nil {
-		if err.Error() == "user not found" {
-			return httpResponse.NotFoundResponse(c, "User not found")
-		}
-		if err.Error() == "no fields to update" {
-			return httpResponse.BadRequestResponse(c, "No fields to update")
-		}
-		return httpResponse.InternalServerErrorResponse(c, "Failed to update profile")
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.users.profile_update_failed"))
 	}
 
-	return httpResponse.SuccessResponse(c, user)
+	return httpResponse.SuccessResponse(c, ctrl.withAvatarURL(c.Request().Context(), user))
 }
+
+// patchProfile applies a JSON Merge Patch to the current user's profile,
+// where null clears a field.
+func (ctrl *UserController) patchProfile(c echo.Context) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return httpResponse.UnauthorizedResponse(c, i18n.Message(c, "api.auth.required"))
+	}
+
+	var patch domain.ProfilePatch
+	nulls, err := httpResponse.BindMergePatch(c, &patch)
+	if err != nil {
+		return err
+	}
+
+	req, err := patch.Update(nulls)
+	if err != nil {
+		return err
+	}
+
+	user, err := ctrl.userService.UpdateUser(c.Request().Context(), userID, req)
+	if err != nil {
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.users.profile_update_failed"))
+	}
+
+	return httpResponse.SuccessResponse(c, ctrl.withAvatarURL(c.Request().Context(), user))
+}
+
 func (ctrl *UserController) updateAvatar(c echo.Context) error {
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		return httpResponse.UnauthorizedResponse(c, "Authentication required")
+		return httpResponse.UnauthorizedResponse(c, i18n.Message(c, "api.auth.required"))
 	}
 
 	file, header, err := c.Request().FormFile("avatar")
 	if err != nil {
-		return httpResponse.BadRequestResponse(c, "No avatar file provided")
+		return httpResponse.BadRequestResponse(c, i18n.Message(c, "api.avatar.missing"))
 	}
 	defer file.Close()
 
-	contentType := header.Header.Get("Content-Type")
-	if contentType == "" {
-		if strings.HasSuffix(strings.ToLower(header.Filename), ".png") {
-			contentType = "image/png"
-		} else if strings.HasSuffix(strings.ToLower(header.Filename), ".gif") {
-			contentType = "image/gif"
-		} else {
-			contentType = "image/jpeg"
-		}
+	avatarKey, err := ctrl.userService.UpdateUserAvatar(c.Request().Context(), userID, file, header.Filename, header.Header.Get("Content-Type"))
+	if err != nil {
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.avatar.update_failed"))
 	}
 
-	avatarURL, err := ctrl.userService.UpdateUserAvatar(userID, file, header.Filename, header.Size, contentType)
+	avatarURL, err := ctrl.urlResolver.ResolveURL(avatarKey)
 	if err != nil {
-		switch {
-		case strings.Contains(err.Error(), "user not found"):
-			return httpResponse.NotFoundResponse(c, "User not found")
-		case strings.Contains(err.Error(), "invalid file type"):
-			return httpResponse.BadRequestResponse(c, "Invalid file type: only images are allowed")
-		case strings.Contains(err.Error(), "file too large"):
-			return httpResponse.BadRequestResponse(c, "Avatar file too large: maximum size is 2MB")
-		default:
-			return httpResponse.InternalServerErrorResponse(c, "Failed to update avatar")
-		}
+		return httpResponse.InternalServerErrorResponse(c, i18n.Message(c, "api.avatar.resolve_failed"))
 	}
 
 	return httpResponse.SuccessResponse(c, map[string]interface{}{
 		"avatar_url": avatarURL,
-	}, "Avatar updated successfully")
+	}, i18n.Message(c, "api.avatar.updated"))
 }
 
 func (ctrl *UserController) deleteAvatar(c echo.Context) error {
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		return httpResponse.UnauthorizedResponse(c, "Authentication required")
+		return httpResponse.UnauthorizedResponse(c, i18n.Message(c, "api.auth.required"))
 	}
 
-	err := ctrl.userService.DeleteUserAvatar(userID)
+	err := ctrl.userService.DeleteUserAvatar(c.Request().Context(), userID)
 	if err != nil {
-		switch {
-		case strings.Contains(err.Error(), "user not found"):
-			return httpResponse.NotFoundResponse(c, "User not found")
-		case strings.Contains(err.Error(), "has no avatar"):
-			return httpResponse.BadRequestResponse(c, "User has no avatar to delete")
-		default:
-			return httpResponse.InternalServerErrorResponse(c, "Failed to delete avatar")
-		}
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.avatar.delete_failed"))
 	}
 
 	return httpResponse.SuccessResponse(c, nil)
@@ -225,24 +272,125 @@ func (ctrl *UserController) deleteAvatar(c echo.Context) error {
 func (ctrl *UserController) getRandomUser(c echo.Context) error {
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		return httpResponse.UnauthorizedResponse(c, "Authentication required")
+		return httpResponse.UnauthorizedResponse(c, i18n.Message(c, "api.auth.required"))
 	}
 
-	randomUser, err := ctrl.userService.GetRandomUser(userID)
+	randomUser, err := ctrl.userService.GetRandomUser(c.Request().Context(), userID)
 	if err != nil {
-		if err.Error() == "no more users available today" {
-			return httpResponse.SuccessResponse(c, nil, "На сегодня все пользователи просмотрены")
+		// Running out of profiles is an expected end of the day, not a
+		// failure, so the client only gets a message.
+		switch {
+		case errors.Is(err, domain.ErrNoUsersAvailable):
+			return httpResponse.SuccessResponse(c, nil, i18n.Message(c, "api.users.all_viewed"))
+		case errors.Is(err, domain.ErrDailyLimitReached):
+			return httpResponse.SuccessResponse(c, nil, i18n.Message(c, "api.users.daily_limit"))
 		}
-		return httpResponse.InternalServerErrorResponse(c, "Failed to get random user")
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.users.random_failed"))
+	}
+
+	return httpResponse.SuccessResponse(c, ctrl.withAvatarURL(c.Request().Context(), randomUser))
+}
+
+func (ctrl *UserController) getViewers(c echo.Context) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return httpResponse.UnauthorizedResponse(c, i18n.Message(c, "api.auth.required"))
 	}
 
-	return httpResponse.SuccessResponse(c, randomUser)
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+	viewers, err := ctrl.userService.GetProfileViewers(c.Request().Context(), userID, limit)
+	if err != nil {
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.users.viewers_failed"))
+	}
+	for i := range viewers {
+		ctrl.withAvatarURL(c.Request().Context(), &viewers[i].User)
+	}
+
+	return httpResponse.SuccessResponse(c, viewers)
+}
+
+func (ctrl *UserController) swipe(c echo.Context) error {
+	viewerID, ok := middleware.GetUserID(c)
+	if !ok {
+		return httpResponse.UnauthorizedResponse(c, i18n.Message(c, "api.auth.required"))
+	}
+
+	targetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return httpResponse.BadRequestResponse(c, i18n.Message(c, "api.users.invalid_id"))
+	}
+
+	var req domain.SwipeRequest
+	if err := httpResponse.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	result, err := ctrl.userService.Swipe(c.Request().Context(), viewerID, targetID, req.Action)
+	if err != nil {
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.users.swipe_failed"))
+	}
+
+	if result.Matched {
+		ctrl.notifyMatch(c.Request().Context(), viewerID, targetID)
+	}
+
+	return httpResponse.SuccessResponse(c, result)
+}
+
+func (ctrl *UserController) notifyMatch(ctx context.Context, viewerID, targetID uuid.UUID) {
+	viewer, err := ctrl.userService.GetUser(ctx, viewerID)
+	if err != nil {
+		return
+	}
+	target, err := ctrl.userService.GetUser(ctx, targetID)
+	if err != nil {
+		return
+	}
+
+	ctrl.matches.Notify(ctx, viewer, target)
+}
+
+// listUsersPage is what GET /users accepts in its query.
+var listUsersPage = pagination.Options{
+	DefaultLimit: 20,
+	MaxLimit:     100,
+	Sorts:        []string{"created_at", "last_seen_at", "username"},
+	DefaultSort:  "-created_at",
+	Filters:      []string{domain.FilterCreatedAfter, domain.FilterHasAvatar, domain.FilterHandlePrefix},
 }
 
 func (ctrl *UserController) getUsers(c echo.Context) error {
-	users, err := ctrl.userService.GetAllUsers()
+	page, err := pagination.Parse(c, listUsersPage)
+	if err != nil {
+		return err
+	}
+
+	users, next, err := ctrl.userService.ListUsers(c.Request().Context(), page)
+	if err != nil {
+		return httpResponse.ServiceErrorResponse(c, err, i18n.Message(c, "api.users.list_failed"))
+	}
+
+	summaries := make([]domain.UserSummary, len(users))
+	for i := range users {
+		summaries[i] = ctrl.withAvatarURL(c.Request().Context(), &users[i]).Summary()
+	}
+	return httpResponse.PageResponse(c, summaries, next)
+}
+
+// withAvatarURL resolves the stored avatar key into a signed, short-lived URL.
+// The user is still returned without one when that fails.
+func (ctrl *UserController) withAvatarURL(ctx context.Context, user *domain.User) *domain.User {
+	if user == nil || user.AvatarKey == nil || *user.AvatarKey == "" {
+		return user
+	}
+
+	avatarURL, err := ctrl.urlResolver.ResolveURL(*user.AvatarKey)
 	if err != nil {
-		return httpResponse.InternalServerErrorResponse(c, "Failed to get users")
+		ctrl.logger.FromContext(ctx).Warnf("Failed to resolve avatar URL of user %s: %v", user.ID, err)
+		return user
 	}
-	return httpResponse.SuccessResponse(c, users)
+
+	user.AvatarURL = &avatarURL
+	return user
 }
@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProfileViewer is a user who opened someone's profile, with the time of
+// their latest visit.
+type ProfileViewer struct {
+	User
+	ViewedAt time.Time `json:"viewed_at" db:"viewed_at"`
+}
+
+type ProfileViewRepository interface {
+	// Record stores that viewerID opened ownerID's profile just now.
+	Record(ctx context.Context, ownerID, viewerID uuid.UUID) error
+	ListViewers(ctx context.Context, ownerID uuid.UUID, limit int) ([]ProfileViewer, error)
+}
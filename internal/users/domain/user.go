@@ -1,48 +1,237 @@
 package domain
 
 import (
+	"context"
 	"io"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/merdernoty/job-hunter/pkg/apperr"
+	"github.com/merdernoty/job-hunter/pkg/deeplink"
+	"github.com/merdernoty/job-hunter/pkg/http/pagination"
+)
+
+var (
+	ErrUserNotFound        = apperr.NotFound("USER_NOT_FOUND", "user not found")
+	ErrNoFieldsToUpdate    = apperr.Validation("NO_FIELDS_TO_UPDATE", "no fields to update")
+	ErrInvalidTelegramData = apperr.Validation("INVALID_TELEGRAM_DATA", "invalid telegram data")
+	ErrNoAvatar            = apperr.NotFound("AVATAR_NOT_FOUND", "user has no avatar")
+	ErrInvalidAvatarKey    = apperr.Validation("INVALID_AVATAR_KEY", "invalid avatar key")
+	// ErrNoUsersAvailable means the viewer has been shown everyone today.
+	ErrNoUsersAvailable  = apperr.NotFound("NO_USERS_AVAILABLE", "no more users available today")
+	ErrNoDailyUser       = apperr.NotFound("DAILY_USER_NOT_FOUND", "no daily user found for today")
+	ErrDailyLimitReached = apperr.RateLimited("DAILY_PROFILE_LIMIT", "daily profile limit reached", 0)
+	ErrPremiumRequired   = apperr.Forbidden("PREMIUM_REQUIRED", "premium feature required")
+	ErrInvalidSwipe      = apperr.Validation("INVALID_SWIPE_ACTION", "invalid swipe action")
+	ErrSwipeSelf         = apperr.Validation("SWIPE_SELF", "cannot swipe yourself")
+	ErrSwipeNotShown     = apperr.Validation("SWIPE_NOT_SHOWN", "profile was not shown to you")
+	ErrFieldNotNullable  = apperr.Validation("FIELD_NOT_NULLABLE", "field cannot be cleared")
+	ErrAvatarURLReadOnly = apperr.Validation("AVATAR_URL_READ_ONLY", "avatar_url can only be cleared, upload a new avatar instead")
 )
 
 type User struct {
-	ID             uuid.UUID `json:"id" db:"id"`
-	TelegramID     int64     `json:"telegram_id" db:"telegram_id"`
-	Username       string    `json:"username" db:"username"`
-	AvatarURL      *string   `json:"avatar_url" db:"avatar_url"`
-	TelegramHandle string    `json:"telegram_handle" db:"telegram_handle"`
-	Bio            *string   `json:"bio" db:"bio"`
-	CreatedAt      time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+	ID             uuid.UUID      `json:"id" db:"id"`
+	TelegramID     int64          `json:"telegram_id" db:"telegram_id"`
+	Username       string         `json:"username" db:"username"`
+	AvatarKey      *string        `json:"-" db:"avatar_key"`
+	AvatarURL      *string        `json:"avatar_url" db:"-"`
+	TelegramHandle string         `json:"telegram_handle" db:"telegram_handle"`
+	Bio            *string        `json:"bio" db:"bio"`
+	Role           *string        `json:"role" db:"role"`
+	Seniority      *string        `json:"seniority" db:"seniority"`
+	Skills         pq.StringArray `json:"skills" db:"skills"`
+	Language       *string        `json:"language" db:"language"`
+	LastSeenAt     time.Time      `json:"last_seen_at" db:"last_seen_at"`
+	// BotChatID is the private chat with the bot, known once the user has
+	// started it.
+	BotChatID *int64    `json:"-" db:"bot_chat_id"`
+	BotStatus string    `json:"-" db:"bot_status"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// UserSummary is how users appear in listings: the public profile without
+// the Telegram ID and bot state.
+type UserSummary struct {
+	ID             uuid.UUID `json:"id"`
+	Username       string    `json:"username"`
+	TelegramHandle string    `json:"telegram_handle"`
+	AvatarURL      *string   `json:"avatar_url"`
+	Bio            *string   `json:"bio"`
+	Role           *string   `json:"role"`
+	Seniority      *string   `json:"seniority"`
+	Skills         []string  `json:"skills"`
+	LastSeenAt     time.Time `json:"last_seen_at"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func (u *User) Summary() UserSummary {
+	return UserSummary{
+		ID:             u.ID,
+		Username:       u.Username,
+		TelegramHandle: u.TelegramHandle,
+		AvatarURL:      u.AvatarURL,
+		Bio:            u.Bio,
+		Role:           u.Role,
+		Seniority:      u.Seniority,
+		Skills:         u.Skills,
+		LastSeenAt:     u.LastSeenAt,
+		CreatedAt:      u.CreatedAt,
+	}
+}
+
+// UserFilter narrows the user listing. Zero fields do not filter.
+type UserFilter struct {
+	CreatedAfter *time.Time
+	HasAvatar    *bool
+	// HandlePrefix matches the start of the Telegram handle, case
+	// insensitively.
+	HandlePrefix string
+}
+
+// Query parameters that filter the user listing.
+const (
+	FilterCreatedAfter = "created_after"
+	FilterHasAvatar    = "has_avatar"
+	FilterHandlePrefix = "handle_prefix"
+)
+
+// Bot statuses tell whether the bot can message the user. Telegram reports a
+// user blocking the bot as the bot being kicked from their private chat.
+const (
+	BotStatusNone    = "none"
+	BotStatusStarted = "started"
+	BotStatusBlocked = "blocked"
+)
+
+// CanMessage reports whether the bot has a private chat with the user that
+// it is allowed to write to.
+func (u *User) CanMessage() bool {
+	return u.BotStatus == BotStatusStarted && u.BotChatID != nil
 }
 
+const (
+	SeniorityIntern = "intern"
+	SeniorityJunior = "junior"
+	SeniorityMiddle = "middle"
+	SenioritySenior = "senior"
+	SeniorityLead   = "lead"
+)
+
+// Seniorities lists the accepted seniority levels, from least to most senior.
+var Seniorities = []string{SeniorityIntern, SeniorityJunior, SeniorityMiddle, SenioritySenior, SeniorityLead}
+
 type TelegramAuthRequest struct {
 	InitData string `json:"initData" validate:"required"`
+	// StartParam is the deep link the mini app was opened with when it did
+	// not come through initData, e.g. from a web_app button URL.
+	StartParam string `json:"startParam,omitempty" validate:"omitempty,max=64"`
+}
+
+type AuthResult struct {
+	User  *User
+	Token string // TODO: change token to struct with expiry
+	// Target is where the mini app should navigate, set when it was opened
+	// with a valid deep link.
+	Target *deeplink.Target
 }
 
 type UpdateUserRequest struct {
-	AvatarURL *string `json:"avatar_url,omitempty" validate:"omitempty,url"`
-	Username  *string `json:"username,omitempty" validate:"omitempty,max=50"`
-	Bio       *string `json:"bio,omitempty" validate:"omitempty,max=500"`
+	AvatarKey *string   `json:"-"`
+	Username  *string   `json:"username,omitempty" validate:"omitempty,max=50"`
+	Bio       *string   `json:"bio,omitempty" validate:"omitempty,max=500"`
+	Role      *string   `json:"role,omitempty" validate:"omitempty,max=100"`
+	Seniority *string   `json:"seniority,omitempty" validate:"omitempty,oneof=intern junior middle senior lead"`
+	Skills    *[]string `json:"skills,omitempty" validate:"omitempty,max=20,dive,min=1,max=30"`
+	Language  *string   `json:"language,omitempty" validate:"omitempty,oneof=ru en"`
+	// Clear lists the profile fields to reset, see ClearableFields.
+	Clear []string `json:"-"`
+}
+
+// Profile fields that can be cleared, named as in the JSON of User.
+const (
+	FieldBio       = "bio"
+	FieldRole      = "role"
+	FieldSeniority = "seniority"
+	FieldSkills    = "skills"
+	FieldLanguage  = "language"
+	FieldAvatarURL = "avatar_url"
+)
+
+// ClearableFields are the fields a profile patch can set to null. Skills
+// are cleared to an empty list and the avatar is removed from storage.
+var ClearableFields = []string{FieldBio, FieldRole, FieldSeniority, FieldSkills, FieldLanguage, FieldAvatarURL}
+
+// ProfilePatch is the body of PATCH /users/me, a JSON Merge Patch of the
+// profile: members left out stay unchanged and members set to null are
+// cleared.
+type ProfilePatch struct {
+	UpdateUserRequest
+	// AvatarURL only accepts null, which removes the avatar. New avatars are
+	// uploaded.
+	AvatarURL *string `json:"avatar_url,omitempty"`
+}
+
+// Update turns the patch and the names of its null members into an update.
+func (p *ProfilePatch) Update(nulls []string) (UpdateUserRequest, error) {
+	if p.AvatarURL != nil {
+		return UpdateUserRequest{}, ErrAvatarURLReadOnly
+	}
+	for _, name := range nulls {
+		if !slices.Contains(ClearableFields, name) {
+			return UpdateUserRequest{}, ErrFieldNotNullable
+		}
+	}
+
+	req := p.UpdateUserRequest
+	req.Clear = nulls
+	return req, nil
 }
 
 type UserRepository interface {
-	GetByID(id uuid.UUID) (*User, error)
-	GetByTelegramID(telegramID int64) (*User, error)
-	GetRandomUser(excludeUserIDs []uuid.UUID) (*User, error)
-	Create(user *User) error
-	Update(id uuid.UUID, updates UpdateUserRequest) error
-	GetAllUsers() ([]User, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*User, error)
+	GetByTelegramID(ctx context.Context, telegramID int64) (*User, error)
+	GetRandomUser(ctx context.Context, excludeUserIDs []uuid.UUID) (*User, error)
+	Create(ctx context.Context, user *User) error
+	Update(ctx context.Context, id uuid.UUID, updates UpdateUserRequest) error
+	// List returns a page of users matching filter and the cursor of the
+	// next page, empty on the last one.
+	List(ctx context.Context, filter UserFilter, page *pagination.Request) ([]User, string, error)
+	Search(ctx context.Context, terms []string, limit, offset int) ([]User, error)
+	TouchLastSeen(ctx context.Context, id uuid.UUID) error
+	SetBotChat(ctx context.Context, telegramID, chatID int64, status string) error
+	SetBotStatus(ctx context.Context, telegramID int64, status string) error
+	// SetReferrer records who invited the user unless that is already known.
+	SetReferrer(ctx context.Context, id, referrerID uuid.UUID) error
+	GetReferencedAvatarKeys(ctx context.Context, keys []string) ([]string, error)
 }
 
 type UserService interface {
-	AuthFromTelegram(initData string) (*User, string, error) // user, token, error TODO: change token to struct with expiry
-	GetUser(id uuid.UUID) (*User, error)
-	UpdateUser(id uuid.UUID, req UpdateUserRequest) (*User, error)
-	GetRandomUser(viewerID uuid.UUID) (*User, error)
-	GetAllUsers() ([]User, error)
-	UpdateUserAvatar(userID uuid.UUID, file io.Reader, fileName string, fileSize int64, contentType string) (string, error)
-	DeleteUserAvatar(userID uuid.UUID) error
+	AuthFromTelegram(ctx context.Context, req TelegramAuthRequest) (*AuthResult, error)
+	GetUser(ctx context.Context, id uuid.UUID) (*User, error)
+	GetUserByTelegramID(ctx context.Context, telegramID int64) (*User, error)
+	EnsureTelegramUser(ctx context.Context, telegramID int64, username string) (*User, error)
+	UpdateUser(ctx context.Context, id uuid.UUID, req UpdateUserRequest) (*User, error)
+	GetRandomUser(ctx context.Context, viewerID uuid.UUID) (*User, error)
+	Swipe(ctx context.Context, viewerID, targetID uuid.UUID, action string) (*SwipeResult, error)
+	// ListUsers returns a page of users filtered by the page's filters and
+	// the cursor of the next page.
+	ListUsers(ctx context.Context, page *pagination.Request) ([]User, string, error)
+	SearchUsers(ctx context.Context, query string, limit, offset int) ([]User, error)
+	TouchLastSeen(ctx context.Context, id uuid.UUID) error
+	// LinkBotChat finds or creates the user and records that they started
+	// the bot in chatID.
+	LinkBotChat(ctx context.Context, telegramID int64, username string, chatID int64) (*User, error)
+	UpdateBotChat(ctx context.Context, telegramID, chatID int64, status string) error
+	MarkBotBlocked(ctx context.Context, telegramID int64) error
+	ApplyReferral(ctx context.Context, userID, referrerID uuid.UUID) error
+	UpdateUserAvatar(ctx context.Context, userID uuid.UUID, file io.Reader, fileName string, contentType string) (string, error)
+	AttachAvatarObject(ctx context.Context, userID uuid.UUID, objectKey string) (string, error)
+	DeleteUserAvatar(ctx context.Context, userID uuid.UUID) error
+	RecordProfileView(ctx context.Context, viewerID, ownerID uuid.UUID)
+	// GetProfileViewers lists who opened the owner's profile, most recent
+	// first. It requires the profile viewers premium feature.
+	GetProfileViewers(ctx context.Context, ownerID uuid.UUID, limit int) ([]ProfileViewer, error)
 }
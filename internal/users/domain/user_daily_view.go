@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"context"
 	"github.com/google/uuid"
 	"time"
 )
@@ -13,9 +14,9 @@ type UserDailyView struct {
 }
 
 type UserDailyViewRepository interface {
-	Create(view *UserDailyView) error
-	GetTodaysDailyUser(viewerID uuid.UUID) (*User, error)
-	GetTodaysShownUsers(viewerID uuid.UUID) ([]uuid.UUID, error)
-	IsUserShownToday(viewerID, shownUserID uuid.UUID) (bool, error)
-	CleanupOldRecords(daysToKeep int) error
+	Create(ctx context.Context, view *UserDailyView) error
+	GetTodaysDailyUser(ctx context.Context, viewerID uuid.UUID) (*User, error)
+	GetTodaysShownUsers(ctx context.Context, viewerID uuid.UUID) ([]uuid.UUID, error)
+	IsUserShownToday(ctx context.Context, viewerID, shownUserID uuid.UUID) (bool, error)
+	CleanupOldRecords(ctx context.Context, daysToKeep int) error
 }
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	SwipeLike = "like"
+	SwipeSkip = "skip"
+)
+
+type UserSwipe struct {
+	ViewerID  uuid.UUID `json:"viewer_id" db:"viewer_id"`
+	TargetID  uuid.UUID `json:"target_id" db:"target_id"`
+	Action    string    `json:"action" db:"action"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type SwipeRequest struct {
+	Action string `json:"action" validate:"required,oneof=like skip"`
+}
+
+type SwipeResult struct {
+	Action string `json:"action"`
+	// Matched is true when both users liked each other.
+	Matched bool `json:"matched"`
+}
+
+type UserSwipeRepository interface {
+	// Save records the viewer's latest action on the target.
+	Save(ctx context.Context, swipe *UserSwipe) error
+	HasLiked(ctx context.Context, viewerID, targetID uuid.UUID) (bool, error)
+	// HasSwiped reports whether the viewer has any action on the target.
+	HasSwiped(ctx context.Context, viewerID, targetID uuid.UUID) (bool, error)
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/merdernoty/job-hunter/internal/bot"
+	"github.com/merdernoty/job-hunter/internal/users/domain"
+	"github.com/merdernoty/job-hunter/pkg/deeplink"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+	"github.com/merdernoty/job-hunter/pkg/storage"
+)
+
+const (
+	inlinePageSize  = 20
+	inlineCacheTime = 30
+	// maxInlineOffset keeps clients from paging arbitrarily deep.
+	maxInlineOffset = 1000
+)
+
+// InlineSearchHandlers answers @bot inline queries with matching profiles.
+type InlineSearchHandlers struct {
+	userService domain.UserService
+	urlResolver storage.URLResolver
+	deepLinks   *deeplink.Codec
+	logger      logger.Logger
+}
+
+func NewInlineSearchHandlers(
+	userService domain.UserService,
+	urlResolver storage.URLResolver,
+	deepLinks *deeplink.Codec,
+	logger logger.Logger,
+) bot.Handlers {
+	return &InlineSearchHandlers{
+		userService: userService,
+		urlResolver: urlResolver,
+		deepLinks:   deepLinks,
+		logger:      logger,
+	}
+}
+
+func (h *InlineSearchHandlers) Register(r *bot.Router) {
+	r.On(bot.UpdateInlineQuery, h.search)
+}
+
+func (h *InlineSearchHandlers) search(c *bot.Context) error {
+	query := c.Update.InlineQuery
+
+	offset, err := strconv.Atoi(query.Offset)
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	if offset >= maxInlineOffset {
+		return h.answer(c, tgbotapi.InlineConfig{InlineQueryID: query.ID, Results: []interface{}{}})
+	}
+
+	users, err := h.userService.SearchUsers(c, query.Query, inlinePageSize, offset)
+	if err != nil {
+		h.logger.FromContext(c).Errorf("Inline search for %q failed: %v", query.Query, err)
+		return h.answer(c, tgbotapi.InlineConfig{
+			InlineQueryID:     query.ID,
+			Results:           []interface{}{},
+			IsPersonal:        true,
+			SwitchPMText:      c.T("inline.search_error"),
+			SwitchPMParameter: "search_error",
+		})
+	}
+
+	results := make([]interface{}, 0, len(users))
+	for i := range users {
+		results = append(results, h.profileResult(c, &users[i]))
+	}
+
+	inline := tgbotapi.InlineConfig{
+		InlineQueryID: query.ID,
+		Results:       results,
+		CacheTime:     inlineCacheTime,
+		// Result texts are localized per user, so they must not be shared.
+		IsPersonal: true,
+	}
+	if len(users) == inlinePageSize {
+		inline.NextOffset = strconv.Itoa(offset + len(users))
+	}
+	if len(users) == 0 && offset == 0 {
+		inline.SwitchPMText = c.T("inline.no_results")
+		inline.SwitchPMParameter = "profile"
+	}
+
+	return h.answer(c, inline)
+}
+
+func (h *InlineSearchHandlers) answer(c *bot.Context, inline tgbotapi.InlineConfig) error {
+	_, err := c.Request(inline)
+	return err
+}
+
+func (h *InlineSearchHandlers) profileResult(c *bot.Context, user *domain.User) tgbotapi.InlineQueryResultArticle {
+	title := profileTitle(user)
+	result := tgbotapi.NewInlineQueryResultArticleHTML("user:"+user.ID.String(), title, profileCard(user))
+	result.Description = strings.Join(profileDetails(user), " · ")
+
+	// Encode only fails for unknown link types.
+	startParam, _ := h.deepLinks.Encode(deeplink.Link{Type: deeplink.Profile, ID: user.ID})
+	markup := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonURL(c.T("inline.open_profile"), c.Bot.MiniAppLink(startParam)),
+		),
+	)
+	result.ReplyMarkup = &markup
+
+	if user.AvatarKey != nil && *user.AvatarKey != "" {
+		if thumb, err := h.urlResolver.ResolveURL(*user.AvatarKey); err == nil {
+			result.ThumbURL = thumb
+		} else {
+			h.logger.FromContext(c).Warnf("Failed to resolve avatar for inline result %s: %v", user.ID, err)
+		}
+	}
+
+	return result
+}
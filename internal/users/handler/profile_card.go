@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/merdernoty/job-hunter/internal/users/domain"
+)
+
+func profileTitle(user *domain.User) string {
+	if user.Username != "" {
+		return user.Username
+	}
+	return user.TelegramHandle
+}
+
+// profileDetails lists role, seniority and skills, whichever are filled in.
+func profileDetails(user *domain.User) []string {
+	var details []string
+	if user.Role != nil && *user.Role != "" {
+		details = append(details, *user.Role)
+	}
+	if user.Seniority != nil && *user.Seniority != "" {
+		details = append(details, *user.Seniority)
+	}
+	if len(user.Skills) > 0 {
+		details = append(details, strings.Join(user.Skills, ", "))
+	}
+	return details
+}
+
+// profileCard renders a user as an HTML message.
+func profileCard(user *domain.User) string {
+	text := fmt.Sprintf("👤 <b>%s</b> (%s)", html.EscapeString(profileTitle(user)), html.EscapeString(user.TelegramHandle))
+	if details := profileDetails(user); len(details) > 0 {
+		text += "\n" + html.EscapeString(strings.Join(details, " · "))
+	}
+	if user.Bio != nil && *user.Bio != "" {
+		text += "\n\n" + html.EscapeString(*user.Bio)
+	}
+	return text
+}
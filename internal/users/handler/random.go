@@ -0,0 +1,250 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/google/uuid"
+	"github.com/merdernoty/job-hunter/internal/bot"
+	"github.com/merdernoty/job-hunter/internal/users/domain"
+	"github.com/merdernoty/job-hunter/internal/users/service"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+	"github.com/merdernoty/job-hunter/pkg/storage"
+)
+
+const randomCallbackPrefix = "random"
+
+// RandomHandlers let users swipe through profiles in the chat, the same way
+// GET /users/random and POST /users/:id/swipe do in the mini app.
+type RandomHandlers struct {
+	userService domain.UserService
+	urlResolver storage.URLResolver
+	matches     *service.MatchNotifier
+	logger      logger.Logger
+}
+
+func NewRandomHandlers(
+	userService domain.UserService,
+	urlResolver storage.URLResolver,
+	matches *service.MatchNotifier,
+	logger logger.Logger,
+) bot.Handlers {
+	return &RandomHandlers{
+		userService: userService,
+		urlResolver: urlResolver,
+		matches:     matches,
+		logger:      logger,
+	}
+}
+
+func (h *RandomHandlers) Register(r *bot.Router) {
+	r.Command("random", "command.random", h.random)
+	r.Callback(randomCallbackPrefix, h.callback)
+}
+
+func (h *RandomHandlers) random(c *bot.Context) error {
+	viewer, err := h.viewer(c)
+	if err != nil || viewer == nil {
+		return err
+	}
+
+	next, emptyKey, err := h.nextUser(c, viewer)
+	if err != nil {
+		return err
+	}
+	if next == nil {
+		return c.ReplyHTML(c.T(emptyKey))
+	}
+
+	return h.sendCard(c, next)
+}
+
+// callback handles "random:like:<id>", "random:skip:<id>" and "random:next",
+// then replaces the card with the next profile.
+func (h *RandomHandlers) callback(c *bot.Context) error {
+	viewer, err := h.viewer(c)
+	if err != nil || viewer == nil {
+		_ = c.AnswerCallback("")
+		return err
+	}
+
+	action, rawID, _ := strings.Cut(c.CallbackData(), ":")
+	toast := ""
+
+	switch action {
+	case domain.SwipeLike, domain.SwipeSkip:
+		targetID, err := uuid.Parse(rawID)
+		if err != nil {
+			return c.AnswerCallback("")
+		}
+
+		result, err := h.userService.Swipe(c, viewer.ID, targetID, action)
+		if err != nil {
+			// A card left open since an earlier day is just replaced.
+			if errors.Is(err, domain.ErrUserNotFound) || errors.Is(err, domain.ErrSwipeNotShown) {
+				break
+			}
+			_ = c.AnswerCallback(c.T("random.failed"))
+			return err
+		}
+
+		if result.Matched {
+			if target, err := h.userService.GetUser(c, targetID); err == nil {
+				toast = c.T("random.matched", profileTitle(target))
+				h.matches.Notify(c, viewer, target)
+			}
+		}
+	case "next":
+	default:
+		return c.AnswerCallback("")
+	}
+
+	if err := c.AnswerCallback(toast); err != nil {
+		h.logger.FromContext(c).Warnf("Failed to answer callback: %v", err)
+	}
+
+	next, emptyKey, err := h.nextUser(c, viewer)
+	if err != nil {
+		return err
+	}
+	return h.replaceCard(c, next, emptyKey)
+}
+
+func (h *RandomHandlers) viewer(c *bot.Context) (*domain.User, error) {
+	if user := c.CurrentUser(); user != nil {
+		return user, nil
+	}
+
+	sender := c.Sender()
+	if sender == nil {
+		return nil, nil
+	}
+	return h.userService.EnsureTelegramUser(c, sender.ID, sender.UserName)
+}
+
+// nextUser returns nil when there is nobody left to show today, together
+// with the key of the message explaining why.
+func (h *RandomHandlers) nextUser(ctx context.Context, viewer *domain.User) (*domain.User, string, error) {
+	user, err := h.userService.GetRandomUser(ctx, viewer.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNoUsersAvailable):
+			return nil, "random.empty", nil
+		case errors.Is(err, domain.ErrDailyLimitReached):
+			return nil, "random.limit", nil
+		}
+		return nil, "", err
+	}
+	return user, "", nil
+}
+
+func (h *RandomHandlers) sendCard(c *bot.Context, user *domain.User) error {
+	keyboard := h.keyboard(c, user)
+
+	if photoURL := h.avatarURL(c, user); photoURL != "" {
+		photo := tgbotapi.NewPhoto(c.ChatID(), tgbotapi.FileURL(photoURL))
+		photo.Caption = profileCard(user)
+		photo.ParseMode = tgbotapi.ModeHTML
+		photo.ReplyMarkup = keyboard
+
+		_, err := c.Send(photo)
+		if err == nil {
+			return nil
+		}
+		h.logger.FromContext(c).Warnf("Failed to send avatar of user %s, sending text card: %v", user.ID, err)
+	}
+
+	reply := tgbotapi.NewMessage(c.ChatID(), profileCard(user))
+	reply.ParseMode = tgbotapi.ModeHTML
+	reply.ReplyMarkup = keyboard
+
+	_, err := c.Send(reply)
+	return err
+}
+
+// replaceCard edits the card message in place. Telegram cannot turn a text
+// message into a photo or back, so in that case the card is sent anew.
+func (h *RandomHandlers) replaceCard(c *bot.Context, user *domain.User, emptyKey string) error {
+	msg := c.Message()
+	if msg == nil {
+		return nil
+	}
+	hasPhoto := len(msg.Photo) > 0
+
+	if user == nil {
+		if hasPhoto {
+			h.deleteCard(c, msg)
+			return c.ReplyHTML(c.T(emptyKey))
+		}
+		edit := tgbotapi.NewEditMessageText(msg.Chat.ID, msg.MessageID, c.T(emptyKey))
+		edit.ParseMode = tgbotapi.ModeHTML
+		_, err := c.Request(edit)
+		return err
+	}
+
+	keyboard := h.keyboard(c, user)
+	photoURL := h.avatarURL(c, user)
+
+	switch {
+	case hasPhoto && photoURL != "":
+		media := tgbotapi.NewInputMediaPhoto(tgbotapi.FileURL(photoURL))
+		media.Caption = profileCard(user)
+		media.ParseMode = tgbotapi.ModeHTML
+
+		edit := tgbotapi.EditMessageMediaConfig{
+			BaseEdit: tgbotapi.BaseEdit{
+				ChatID:      msg.Chat.ID,
+				MessageID:   msg.MessageID,
+				ReplyMarkup: &keyboard,
+			},
+			Media: media,
+		}
+		_, err := c.Request(edit)
+		if err == nil {
+			return nil
+		}
+		h.logger.FromContext(c).Warnf("Failed to edit avatar card for user %s: %v", user.ID, err)
+	case !hasPhoto && photoURL == "":
+		edit := tgbotapi.NewEditMessageTextAndMarkup(msg.Chat.ID, msg.MessageID, profileCard(user), keyboard)
+		edit.ParseMode = tgbotapi.ModeHTML
+		_, err := c.Request(edit)
+		return err
+	}
+
+	h.deleteCard(c, msg)
+	return h.sendCard(c, user)
+}
+
+func (h *RandomHandlers) deleteCard(c *bot.Context, msg *tgbotapi.Message) {
+	if _, err := c.Request(tgbotapi.NewDeleteMessage(msg.Chat.ID, msg.MessageID)); err != nil {
+		h.logger.FromContext(c).Warnf("Failed to delete profile card: %v", err)
+	}
+}
+
+func (h *RandomHandlers) keyboard(c *bot.Context, user *domain.User) tgbotapi.InlineKeyboardMarkup {
+	id := user.ID.String()
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(c.T("random.like"), randomCallbackPrefix+":"+domain.SwipeLike+":"+id),
+			tgbotapi.NewInlineKeyboardButtonData(c.T("random.skip"), randomCallbackPrefix+":"+domain.SwipeSkip+":"+id),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(c.T("random.next"), randomCallbackPrefix+":next"),
+		),
+	)
+}
+
+func (h *RandomHandlers) avatarURL(c *bot.Context, user *domain.User) string {
+	if user.AvatarKey == nil || *user.AvatarKey == "" {
+		return ""
+	}
+
+	url, err := h.urlResolver.ResolveURL(*user.AvatarKey)
+	if err != nil {
+		h.logger.FromContext(c).Warnf("Failed to resolve avatar for user %s: %v", user.ID, err)
+		return ""
+	}
+	return url
+}
@@ -6,7 +6,10 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
+	"github.com/merdernoty/job-hunter/internal/users/domain"
 	"github.com/merdernoty/job-hunter/pkg/jwt"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+	"github.com/merdernoty/job-hunter/pkg/ratelimit"
 )
 
 func JWTAuth(jwtService *jwt.JWTService) echo.MiddlewareFunc {
@@ -17,24 +20,74 @@ func JWTAuth(jwtService *jwt.JWTService) echo.MiddlewareFunc {
 				return echo.NewHTTPError(http.StatusUnauthorized, "missing authorization header")
 			}
 
-			parts := strings.SplitN(authHeader, " ", 2)
-			if len(parts) != 2 || parts[0] != "Bearer" {
+			token, ok := bearerToken(authHeader)
+			if !ok {
 				return echo.NewHTTPError(http.StatusUnauthorized, "invalid authorization header format")
 			}
 
-			userID, err := jwtService.VerifyToken(parts[1])
+			userID, err := jwtService.VerifyToken(token)
 			if err != nil {
 				return echo.NewHTTPError(http.StatusUnauthorized, "invalid or expired token")
 			}
 
 			c.Set("userID", userID)
+			req := c.Request()
+			c.SetRequest(req.WithContext(logger.ContextWith(req.Context(), "user_id", userID.String())))
 
 			return next(c)
 		}
 	}
 }
 
+func bearerToken(authHeader string) (string, bool) {
+	parts := strings.SplitN(authHeader, " ", 2)
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// RateLimitKey counts requests with a valid token against the user, so that
+// users behind one address do not share a limit, and all other requests
+// against the client IP. It runs before JWTAuth and only identifies the
+// client; JWTAuth still decides whether the request is authenticated.
+func RateLimitKey(jwtService *jwt.JWTService) ratelimit.KeyFunc {
+	return func(c echo.Context) string {
+		if token, ok := bearerToken(c.Request().Header.Get("Authorization")); ok {
+			if userID, err := jwtService.VerifyToken(token); err == nil {
+				return "user:" + userID.String()
+			}
+		}
+		return "ip:" + c.RealIP()
+	}
+}
+
 func GetUserID(c echo.Context) (uuid.UUID, bool) {
 	id, ok := c.Get("userID").(uuid.UUID)
 	return id, ok
 }
+
+// AdminOnly lets through authenticated users whose Telegram ID is listed in
+// adminIDs. It must run after JWTAuth.
+func AdminOnly(userService domain.UserService, adminIDs []int64) echo.MiddlewareFunc {
+	admins := make(map[int64]bool, len(adminIDs))
+	for _, id := range adminIDs {
+		admins[id] = true
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			userID, ok := GetUserID(c)
+			if !ok {
+				return echo.NewHTTPError(http.StatusUnauthorized, "authentication required")
+			}
+
+			user, err := userService.GetUser(c.Request().Context(), userID)
+			if err != nil || !admins[user.TelegramID] {
+				return echo.NewHTTPError(http.StatusForbidden, "admin access required")
+			}
+
+			return next(c)
+		}
+	}
+}
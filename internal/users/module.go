@@ -2,6 +2,7 @@ package user
 
 import (
 	"github.com/merdernoty/job-hunter/internal/users/domain"
+	"github.com/merdernoty/job-hunter/internal/users/handler"
 	"github.com/merdernoty/job-hunter/internal/users/repository"
 	"github.com/merdernoty/job-hunter/internal/users/service"
 	"go.uber.org/fx"
@@ -17,6 +18,14 @@ var Module = fx.Module("user",
 			repository.NewUserDailyViewRepository,
 			fx.As(new(domain.UserDailyViewRepository)),
 		),
+		fx.Annotate(
+			repository.NewUserSwipeRepository,
+			fx.As(new(domain.UserSwipeRepository)),
+		),
+		fx.Annotate(
+			repository.NewProfileViewRepository,
+			fx.As(new(domain.ProfileViewRepository)),
+		),
 	),
 	fx.Provide(
 		fx.Annotate(
@@ -24,5 +33,24 @@ var Module = fx.Module("user",
 			fx.As(new(domain.UserService)),
 		),
 		service.NewAvatarService,
+		service.NewMatchNotifier,
+	),
+	fx.Provide(
+		fx.Annotate(
+			service.NewAvatarUploadAttacher,
+			fx.ResultTags(`group:"upload_attachers"`),
+		),
+		fx.Annotate(
+			service.NewAvatarReferenceSource,
+			fx.ResultTags(`group:"storage_references"`),
+		),
+		fx.Annotate(
+			handler.NewInlineSearchHandlers,
+			fx.ResultTags(`group:"bot_handlers"`),
+		),
+		fx.Annotate(
+			handler.NewRandomHandlers,
+			fx.ResultTags(`group:"bot_handlers"`),
+		),
 	),
 )
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/merdernoty/job-hunter/internal/users/domain"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+)
+
+type profileViewRepository struct {
+	db     *sqlx.DB
+	logger logger.Logger
+}
+
+func NewProfileViewRepository(db *sqlx.DB, logger logger.Logger) domain.ProfileViewRepository {
+	return &profileViewRepository{db: db, logger: logger}
+}
+
+func (r *profileViewRepository) Record(ctx context.Context, ownerID, viewerID uuid.UUID) error {
+	query := `
+		INSERT INTO profile_views (owner_id, viewer_id)
+		VALUES ($1, $2)
+		ON CONFLICT (owner_id, viewer_id) DO UPDATE
+		SET viewed_at = NOW()`
+
+	if _, err := r.db.ExecContext(ctx, query, ownerID, viewerID); err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to record profile view %s -> %s: %v", viewerID, ownerID, err)
+		return fmt.Errorf("database error")
+	}
+
+	return nil
+}
+
+func (r *profileViewRepository) ListViewers(ctx context.Context, ownerID uuid.UUID, limit int) ([]domain.ProfileViewer, error) {
+	var viewers []domain.ProfileViewer
+	query := `
+		SELECT u.id, u.telegram_id, u.username, u.telegram_handle, u.avatar_key, u.bio, u.role, u.seniority, u.skills, u.language, u.last_seen_at, u.bot_chat_id, u.bot_status, u.created_at, u.updated_at, pv.viewed_at
+		FROM profile_views pv
+		JOIN users u ON u.id = pv.viewer_id
+		WHERE pv.owner_id = $1
+		ORDER BY pv.viewed_at DESC
+		LIMIT $2`
+
+	if err := r.db.SelectContext(ctx, &viewers, query, ownerID, limit); err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to list profile viewers for %s: %v", ownerID, err)
+		return nil, fmt.Errorf("database error")
+	}
+
+	return viewers, nil
+}
@@ -1,13 +1,17 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/merdernoty/job-hunter/internal/users/domain"
+	"github.com/merdernoty/job-hunter/pkg/http/pagination"
 	"github.com/merdernoty/job-hunter/pkg/logger"
 )
 
@@ -20,68 +24,78 @@ func NewUserRepository(db *sqlx.DB, logger logger.Logger) domain.UserRepository
 	return &userRepository{db: db, logger: logger}
 }
 
-func (r *userRepository) GetByID(id uuid.UUID) (*domain.User, error) {
+func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
 	var user domain.User
 	query := `
-		SELECT id, telegram_id, avatar_url, telegram_handle, username, bio, created_at, updated_at
+		SELECT id, telegram_id, avatar_key, telegram_handle, username, bio, role, seniority, skills, language, last_seen_at, bot_chat_id, bot_status, created_at, updated_at
 		FROM users 
 		WHERE id = $1`
-	err := r.db.Get(&user, query, id)
+	err := r.db.GetContext(ctx, &user, query, id)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("user not found")
+		return nil, domain.ErrUserNotFound
 	}
 	if err != nil {
-		r.logger.Errorf("Failed to get user by ID %s: %v", id, err)
+		r.logger.FromContext(ctx).Errorf("Failed to get user by ID %s: %v", id, err)
 		return nil, fmt.Errorf("database error")
 	}
 	return &user, nil
 }
 
-func (r *userRepository) GetByTelegramID(telegramID int64) (*domain.User, error) {
+func (r *userRepository) GetByTelegramID(ctx context.Context, telegramID int64) (*domain.User, error) {
 	var user domain.User
 
 	query := `
-		SELECT id, telegram_id, avatar_url, telegram_handle, username, bio, created_at, updated_at
+		SELECT id, telegram_id, avatar_key, telegram_handle, username, bio, role, seniority, skills, language, last_seen_at, bot_chat_id, bot_status, created_at, updated_at
 		FROM users 
 		WHERE telegram_id = $1`
 
-	err := r.db.Get(&user, query, telegramID)
+	err := r.db.GetContext(ctx, &user, query, telegramID)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("user not found")
+		return nil, domain.ErrUserNotFound
 	}
 	if err != nil {
-		r.logger.Errorf("Failed to get user by telegram_id %d: %v", telegramID, err)
+		r.logger.FromContext(ctx).Errorf("Failed to get user by telegram_id %d: %v", telegramID, err)
 		return nil, fmt.Errorf("database error")
 	}
 
 	return &user, nil
 }
 
-func (r *userRepository) Create(user *domain.User) error {
+func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
 	if user.ID == uuid.Nil {
 		user.ID = uuid.New()
 	}
 
 	query := `
-		INSERT INTO users (id, telegram_id, username, telegram_handle, avatar_url, bio)
+		INSERT INTO users (id, telegram_id, username, telegram_handle, avatar_key, bio)
 		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING created_at, updated_at`
+		RETURNING bot_status, created_at, updated_at`
 
-	err := r.db.QueryRow(
-		query,
-		user.ID, user.TelegramID, user.Username, user.TelegramHandle, user.AvatarURL, user.Bio,
-	).Scan(&user.CreatedAt, &user.UpdatedAt)
+	err := r.db.QueryRowContext(
+		ctx, query,
+		user.ID, user.TelegramID, user.Username, user.TelegramHandle, user.AvatarKey, user.Bio,
+	).Scan(&user.BotStatus, &user.CreatedAt, &user.UpdatedAt)
 
 	if err != nil {
-		r.logger.Errorf("Failed to create user: %v", err)
+		r.logger.FromContext(ctx).Errorf("Failed to create user: %v", err)
 		return fmt.Errorf("failed to create user")
 	}
 
-	r.logger.Infof("Created user: %s (telegram_id: %d)", user.Username, user.TelegramID)
+	r.logger.FromContext(ctx).Infof("Created user: %s (telegram_id: %d)", user.Username, user.TelegramID)
 	return nil
 }
 
-func (r *userRepository) Update(id uuid.UUID, updates domain.UpdateUserRequest) error {
+// clearedColumns resets the column behind each of domain.ClearableFields.
+var clearedColumns = map[string]string{
+	domain.FieldBio:       "bio = NULL",
+	domain.FieldRole:      "role = NULL",
+	domain.FieldSeniority: "seniority = NULL",
+	domain.FieldSkills:    "skills = '{}'",
+	domain.FieldLanguage:  "language = NULL",
+	domain.FieldAvatarURL: "avatar_key = NULL",
+}
+
+func (r *userRepository) Update(ctx context.Context, id uuid.UUID, updates domain.UpdateUserRequest) error {
 	setParts := []string{}
 	args := []interface{}{}
 	argIndex := 1
@@ -91,9 +105,9 @@ func (r *userRepository) Update(id uuid.UUID, updates domain.UpdateUserRequest)
 		args = append(args, *updates.Username)
 		argIndex++
 	}
-	if updates.AvatarURL != nil {
-		setParts = append(setParts, fmt.Sprintf("avatar_url = $%d", argIndex))
-		args = append(args, *updates.AvatarURL)
+	if updates.AvatarKey != nil {
+		setParts = append(setParts, fmt.Sprintf("avatar_key = $%d", argIndex))
+		args = append(args, *updates.AvatarKey)
 		argIndex++
 	}
 	if updates.Bio != nil {
@@ -101,9 +115,36 @@ func (r *userRepository) Update(id uuid.UUID, updates domain.UpdateUserRequest)
 		args = append(args, *updates.Bio)
 		argIndex++
 	}
+	if updates.Role != nil {
+		setParts = append(setParts, fmt.Sprintf("role = $%d", argIndex))
+		args = append(args, *updates.Role)
+		argIndex++
+	}
+	if updates.Seniority != nil {
+		setParts = append(setParts, fmt.Sprintf("seniority = $%d", argIndex))
+		args = append(args, *updates.Seniority)
+		argIndex++
+	}
+	if updates.Skills != nil {
+		setParts = append(setParts, fmt.Sprintf("skills = $%d", argIndex))
+		args = append(args, pq.Array(*updates.Skills))
+		argIndex++
+	}
+	if updates.Language != nil {
+		setParts = append(setParts, fmt.Sprintf("language = $%d", argIndex))
+		args = append(args, *updates.Language)
+		argIndex++
+	}
+	for _, field := range updates.Clear {
+		clear, ok := clearedColumns[field]
+		if !ok {
+			return domain.ErrFieldNotNullable
+		}
+		setParts = append(setParts, clear)
+	}
 
 	if len(setParts) == 0 {
-		return fmt.Errorf("no fields to update")
+		return domain.ErrNoFieldsToUpdate
 	}
 
 	setParts = append(setParts, "updated_at = NOW()")
@@ -115,9 +156,9 @@ func (r *userRepository) Update(id uuid.UUID, updates domain.UpdateUserRequest)
 		WHERE id = $%d`,
 		strings.Join(setParts, ", "), argIndex)
 
-	result, err := r.db.Exec(query, args...)
+	result, err := r.db.ExecContext(ctx, query, args...)
 	if err != nil {
-		r.logger.Errorf("Failed to update user %s: %v", id, err)
+		r.logger.FromContext(ctx).Errorf("Failed to update user %s: %v", id, err)
 		return fmt.Errorf("failed to update user")
 	}
 
@@ -127,27 +168,27 @@ func (r *userRepository) Update(id uuid.UUID, updates domain.UpdateUserRequest)
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("user not found")
+		return domain.ErrUserNotFound
 	}
 
-	r.logger.Infof("Updated user: %s", id)
+	r.logger.FromContext(ctx).Infof("Updated user: %s", id)
 	return nil
 }
 
-func (r *userRepository) GetRandomUser(excludeUserIDs []uuid.UUID) (*domain.User, error) {
+func (r *userRepository) GetRandomUser(ctx context.Context, excludeUserIDs []uuid.UUID) (*domain.User, error) {
 	var user domain.User
 	var query string
 	var args []interface{}
 
-	r.logger.Infof("GetRandomUser called with %d exclusions: %v", len(excludeUserIDs), excludeUserIDs)
+	r.logger.FromContext(ctx).Infof("GetRandomUser called with %d exclusions: %v", len(excludeUserIDs), excludeUserIDs)
 
 	if len(excludeUserIDs) == 0 {
 		query = `
-			SELECT id, telegram_id, username, telegram_handle, avatar_url, bio, created_at, updated_at
+			SELECT id, telegram_id, username, telegram_handle, avatar_key, bio, role, seniority, skills, language, last_seen_at, bot_chat_id, bot_status, created_at, updated_at
 			FROM users
 			ORDER BY RANDOM()
 			LIMIT 1`
-		r.logger.Info("Using query without exclusions")
+		r.logger.FromContext(ctx).Info("Using query without exclusions")
 	} else {
 		placeholders := ""
 		for i := range excludeUserIDs {
@@ -159,103 +200,295 @@ func (r *userRepository) GetRandomUser(excludeUserIDs []uuid.UUID) (*domain.User
 		}
 
 		query = fmt.Sprintf(`
-			SELECT id, telegram_id, username, telegram_handle, avatar_url, bio, created_at, updated_at
+			SELECT id, telegram_id, username, telegram_handle, avatar_key, bio, role, seniority, skills, language, last_seen_at, bot_chat_id, bot_status, created_at, updated_at
 			FROM users
 			WHERE id NOT IN (%s)
 			ORDER BY RANDOM()
 			LIMIT 1`, placeholders)
 
-		r.logger.Infof("Using query with exclusions: %s", query)
-		r.logger.Infof("Query args: %v", args)
+		r.logger.FromContext(ctx).Infof("Using query with exclusions: %s", query)
+		r.logger.FromContext(ctx).Infof("Query args: %v", args)
 	}
 
-	err := r.db.Get(&user, query, args...)
+	err := r.db.GetContext(ctx, &user, query, args...)
 	if err == sql.ErrNoRows {
-		r.logger.Warn("No available users found after exclusions")
-		return nil, fmt.Errorf("no available users found")
+		r.logger.FromContext(ctx).Warn("No available users found after exclusions")
+		return nil, domain.ErrNoUsersAvailable
 	}
 	if err != nil {
-		r.logger.Errorf("Failed to get random user: %v", err)
+		r.logger.FromContext(ctx).Errorf("Failed to get random user: %v", err)
 		return nil, fmt.Errorf("database error")
 	}
 
-	r.logger.Infof("Retrieved random user: %s (ID: %s)", user.Username, user.ID)
+	r.logger.FromContext(ctx).Infof("Retrieved random user: %s (ID: %s)", user.Username, user.ID)
 	for _, excludeID := range excludeUserIDs {
 		if user.ID == excludeID {
-			r.logger.Errorf("CRITICAL: Selected user %s is in exclusion list! This should not happen!", user.ID)
-			return nil, fmt.Errorf("no available users found")
+			r.logger.FromContext(ctx).Errorf("CRITICAL: Selected user %s is in exclusion list! This should not happen!", user.ID)
+			return nil, domain.ErrNoUsersAvailable
 		}
 	}
 
 	return &user, nil
 }
-func (r *userRepository) GetTodaysDailyUser(viewerID uuid.UUID) (*domain.User, error) {
+func (r *userRepository) GetTodaysDailyUser(ctx context.Context, viewerID uuid.UUID) (*domain.User, error) {
 	var user domain.User
 	query := `
-		SELECT u.id, u.telegram_id, u.username, u.telegram_handle, u.avatar_url, u.bio, u.created_at, u.updated_at
+		SELECT u.id, u.telegram_id, u.username, u.telegram_handle, u.avatar_key, u.bio, u.role, u.seniority, u.skills, u.language, u.last_seen_at, u.bot_chat_id, u.bot_status, u.created_at, u.updated_at
 		FROM users u
 		JOIN user_daily_views udv ON u.id = udv.shown_user_id
 		WHERE udv.viewer_id = $1 AND udv.view_date = CURRENT_DATE
 		ORDER BY udv.created_at ASC
 		LIMIT 1`
 
-	err := r.db.Get(&user, query, viewerID)
+	err := r.db.GetContext(ctx, &user, query, viewerID)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("no daily user found for today")
+		return nil, domain.ErrNoDailyUser
 	}
 	if err != nil {
-		r.logger.Errorf("Failed to get today's daily user for viewer %s: %v", viewerID, err)
+		r.logger.FromContext(ctx).Errorf("Failed to get today's daily user for viewer %s: %v", viewerID, err)
 		return nil, fmt.Errorf("database error")
 	}
 
 	return &user, nil
 }
 
-func (r *userRepository) GetTodaysShownUsers(viewerID uuid.UUID) ([]uuid.UUID, error) {
+func (r *userRepository) GetTodaysShownUsers(ctx context.Context, viewerID uuid.UUID) ([]uuid.UUID, error) {
 	var userIDs []uuid.UUID
 	query := `
 		SELECT shown_user_id 
 		FROM user_daily_views 
 		WHERE viewer_id = $1 AND view_date = CURRENT_DATE`
 
-	err := r.db.Select(&userIDs, query, viewerID)
+	err := r.db.SelectContext(ctx, &userIDs, query, viewerID)
 	if err != nil {
-		r.logger.Errorf("Failed to get today's shown users for viewer %s: %v", viewerID, err)
+		r.logger.FromContext(ctx).Errorf("Failed to get today's shown users for viewer %s: %v", viewerID, err)
 		return nil, fmt.Errorf("database error")
 	}
 
 	return userIDs, nil
 }
 
-func (r *userRepository) MarkUserAsShownToday(viewerID, shownUserID uuid.UUID) error {
+func (r *userRepository) MarkUserAsShownToday(ctx context.Context, viewerID, shownUserID uuid.UUID) error {
 	query := `
 		INSERT INTO user_daily_views (viewer_id, shown_user_id, view_date)
 		VALUES ($1, $2, CURRENT_DATE)
 		ON CONFLICT (viewer_id, shown_user_id, view_date) DO NOTHING`
 
-	_, err := r.db.Exec(query, viewerID, shownUserID)
+	_, err := r.db.ExecContext(ctx, query, viewerID, shownUserID)
 	if err != nil {
-		r.logger.Errorf("Failed to mark user %s as shown to viewer %s: %v", shownUserID, viewerID, err)
+		r.logger.FromContext(ctx).Errorf("Failed to mark user %s as shown to viewer %s: %v", shownUserID, viewerID, err)
 		return fmt.Errorf("failed to mark user as shown")
 	}
 
-	r.logger.Infof("Marked user %s as shown to viewer %s for today", shownUserID, viewerID)
+	r.logger.FromContext(ctx).Infof("Marked user %s as shown to viewer %s for today", shownUserID, viewerID)
 	return nil
 }
 
-func (r *userRepository) GetAllUsers() ([]domain.User, error) {
-	var users []domain.User
+// userSorts are the sort fields of the user listing. value renders a user's
+// sort value into a cursor and parse reads it back as a query argument.
+var userSorts = map[string]struct {
+	column string
+	value  func(u *domain.User) string
+	parse  func(value string) (interface{}, error)
+}{
+	"created_at": {
+		column: "created_at",
+		value:  func(u *domain.User) string { return u.CreatedAt.Format(time.RFC3339Nano) },
+		parse:  parseCursorTime,
+	},
+	"last_seen_at": {
+		column: "last_seen_at",
+		value:  func(u *domain.User) string { return u.LastSeenAt.Format(time.RFC3339Nano) },
+		parse:  parseCursorTime,
+	},
+	"username": {
+		column: "username",
+		value:  func(u *domain.User) string { return u.Username },
+		parse:  func(value string) (interface{}, error) { return value, nil },
+	},
+}
+
+func parseCursorTime(value string) (interface{}, error) {
+	return time.Parse(time.RFC3339Nano, value)
+}
+
+// List pages through users with keyset pagination on the sort column and
+// the ID, so pages stay stable while users sign up.
+func (r *userRepository) List(ctx context.Context, filter domain.UserFilter, page *pagination.Request) ([]domain.User, string, error) {
+	sort, ok := userSorts[page.Sort]
+	if !ok {
+		return nil, "", pagination.ErrInvalidSort
+	}
+
+	users := []domain.User{}
+	conditions := []string{}
+	args := []interface{}{}
+
+	if filter.CreatedAfter != nil {
+		args = append(args, *filter.CreatedAfter)
+		conditions = append(conditions, fmt.Sprintf("created_at > $%d", len(args)))
+	}
+	if filter.HasAvatar != nil {
+		if *filter.HasAvatar {
+			conditions = append(conditions, "avatar_key IS NOT NULL")
+		} else {
+			conditions = append(conditions, "avatar_key IS NULL")
+		}
+	}
+	if filter.HandlePrefix != "" {
+		args = append(args, escapeLike(filter.HandlePrefix)+"%")
+		conditions = append(conditions, fmt.Sprintf("telegram_handle ILIKE $%d", len(args)))
+	}
+
+	order, compare := "ASC", ">"
+	if page.Desc {
+		order, compare = "DESC", "<"
+	}
+
+	if page.After != nil {
+		value, err := sort.parse(page.After.Value)
+		if err != nil {
+			return nil, "", pagination.ErrInvalidCursor
+		}
+		id, err := uuid.Parse(page.After.ID)
+		if err != nil {
+			return nil, "", pagination.ErrInvalidCursor
+		}
+		args = append(args, value, id)
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s ($%d, $%d)", sort.column, compare, len(args)-1, len(args)))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// Fetch one extra row to learn whether there is a next page.
+	args = append(args, page.Limit+1)
+	query := fmt.Sprintf(`
+		SELECT id, telegram_id, username, telegram_handle, avatar_key, bio, role, seniority, skills, language, last_seen_at, bot_chat_id, bot_status, created_at, updated_at
+		FROM users
+		%s
+		ORDER BY %[2]s %[3]s, id %[3]s
+		LIMIT $%[4]d`,
+		where, sort.column, order, len(args))
+
+	if err := r.db.SelectContext(ctx, &users, query, args...); err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to list users: %v", err)
+		return nil, "", fmt.Errorf("database error")
+	}
+
+	next := ""
+	if len(users) > page.Limit {
+		users = users[:page.Limit]
+		last := &users[len(users)-1]
+		next = page.Next(sort.value(last), last.ID.String())
+	}
+
+	return users, next, nil
+}
+
+// Search returns users matching every term, where a term matches the
+// username, role, bio, seniority or any skill.
+func (r *userRepository) Search(ctx context.Context, terms []string, limit, offset int) ([]domain.User, error) {
+	users := []domain.User{}
+	conditions := []string{}
+	args := []interface{}{}
+
+	for _, term := range terms {
+		args = append(args, "%"+escapeLike(term)+"%", term)
+		pattern, exact := len(args)-1, len(args)
+		conditions = append(conditions, fmt.Sprintf(
+			`(username ILIKE $%[1]d OR role ILIKE $%[1]d OR bio ILIKE $%[1]d OR seniority = $%[2]d
+			OR EXISTS (SELECT 1 FROM unnest(skills) AS skill WHERE skill ILIKE $%[1]d))`,
+			pattern, exact))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	args = append(args, limit, offset)
+	query := fmt.Sprintf(`
+		SELECT id, telegram_id, username, telegram_handle, avatar_key, bio, role, seniority, skills, language, last_seen_at, bot_chat_id, bot_status, created_at, updated_at
+		FROM users
+		%s
+		ORDER BY updated_at DESC, id
+		LIMIT $%d OFFSET $%d`,
+		where, len(args)-1, len(args))
+
+	if err := r.db.SelectContext(ctx, &users, query, args...); err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to search users for %v: %v", terms, err)
+		return nil, fmt.Errorf("database error")
+	}
+
+	return users, nil
+}
+
+func (r *userRepository) TouchLastSeen(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE users SET last_seen_at = NOW() WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to touch last seen for user %s: %v", id, err)
+		return fmt.Errorf("database error")
+	}
+
+	return nil
+}
+
+func (r *userRepository) SetBotChat(ctx context.Context, telegramID, chatID int64, status string) error {
+	query := `
+		UPDATE users
+		SET bot_chat_id = $2, bot_status = $3, bot_status_at = NOW()
+		WHERE telegram_id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, telegramID, chatID, status); err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to set bot chat %d (%s) for telegram_id %d: %v", chatID, status, telegramID, err)
+		return fmt.Errorf("database error")
+	}
+
+	return nil
+}
+
+func (r *userRepository) SetBotStatus(ctx context.Context, telegramID int64, status string) error {
+	query := `UPDATE users SET bot_status = $2, bot_status_at = NOW() WHERE telegram_id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, telegramID, status); err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to set bot status %s for telegram_id %d: %v", status, telegramID, err)
+		return fmt.Errorf("database error")
+	}
+
+	return nil
+}
+
+func (r *userRepository) SetReferrer(ctx context.Context, id, referrerID uuid.UUID) error {
+	query := `UPDATE users SET referred_by = $2 WHERE id = $1 AND referred_by IS NULL AND id <> $2`
+
+	if _, err := r.db.ExecContext(ctx, query, id, referrerID); err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to set referrer %s for user %s: %v", referrerID, id, err)
+		return fmt.Errorf("database error")
+	}
+
+	return nil
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+func (r *userRepository) GetReferencedAvatarKeys(ctx context.Context, keys []string) ([]string, error) {
+	var referenced []string
 	query := `
-		SELECT id, telegram_id, username, telegram_handle, avatar_url, bio, created_at, updated_at
+		SELECT avatar_key
 		FROM users
-	`
+		WHERE avatar_key = ANY($1)`
 
-	err := r.db.Select(&users, query)
+	err := r.db.SelectContext(ctx, &referenced, query, pq.Array(keys))
 	if err != nil {
-		r.logger.Errorf("Failed to get all users: %v", err)
+		r.logger.FromContext(ctx).Errorf("Failed to get referenced avatar keys: %v", err)
 		return nil, fmt.Errorf("database error")
 	}
 
-	r.logger.Info("Retrieved all users")
-	return users, nil
+	return referenced, nil
 }
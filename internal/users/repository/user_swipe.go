@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/merdernoty/job-hunter/internal/users/domain"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+)
+
+type userSwipeRepository struct {
+	db     *sqlx.DB
+	logger logger.Logger
+}
+
+func NewUserSwipeRepository(db *sqlx.DB, logger logger.Logger) domain.UserSwipeRepository {
+	return &userSwipeRepository{db: db, logger: logger}
+}
+
+func (r *userSwipeRepository) Save(ctx context.Context, swipe *domain.UserSwipe) error {
+	query := `
+		INSERT INTO user_swipes (viewer_id, target_id, action)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (viewer_id, target_id) DO UPDATE
+		SET action = EXCLUDED.action, updated_at = NOW()
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query, swipe.ViewerID, swipe.TargetID, swipe.Action).Scan(&swipe.CreatedAt, &swipe.UpdatedAt)
+	if err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to save swipe %s -> %s: %v", swipe.ViewerID, swipe.TargetID, err)
+		return fmt.Errorf("database error")
+	}
+
+	return nil
+}
+
+func (r *userSwipeRepository) HasLiked(ctx context.Context, viewerID, targetID uuid.UUID) (bool, error) {
+	var liked bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM user_swipes
+			WHERE viewer_id = $1 AND target_id = $2 AND action = $3
+		)`
+
+	if err := r.db.GetContext(ctx, &liked, query, viewerID, targetID, domain.SwipeLike); err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to check like %s -> %s: %v", viewerID, targetID, err)
+		return false, fmt.Errorf("database error")
+	}
+
+	return liked, nil
+}
+
+func (r *userSwipeRepository) HasSwiped(ctx context.Context, viewerID, targetID uuid.UUID) (bool, error) {
+	var swiped bool
+	query := `SELECT EXISTS (SELECT 1 FROM user_swipes WHERE viewer_id = $1 AND target_id = $2)`
+
+	if err := r.db.GetContext(ctx, &swiped, query, viewerID, targetID); err != nil {
+		r.logger.FromContext(ctx).Errorf("Failed to check swipe %s -> %s: %v", viewerID, targetID, err)
+		return false, fmt.Errorf("database error")
+	}
+
+	return swiped, nil
+}
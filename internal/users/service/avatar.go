@@ -4,12 +4,10 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"os"
-	"path/filepath"
-	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/merdernoty/job-hunter/internal/users/domain"
 	"github.com/merdernoty/job-hunter/pkg/logger"
 	minioClient "github.com/merdernoty/job-hunter/pkg/storage"
 	"github.com/minio/minio-go/v7"
@@ -27,88 +25,71 @@ func NewAvatarService(minioClient *minioClient.MinIOClient, logger logger.Logger
 	}
 }
 
+const maxAvatarSize = int64(2 * 1024 * 1024) // 2MB
+
+var allowedAvatarTypes = []string{
+	"image/jpeg",
+	"image/png",
+	"image/gif",
+	"image/webp",
+}
+
 type UploadAvatarRequest struct {
 	UserID      uuid.UUID
 	File        io.Reader
 	FileName    string
-	FileSize    int64
 	ContentType string
 }
 
-func (s *AvatarService) UploadAvatar(req UploadAvatarRequest) (string, error) {
-	if err := s.validateAvatarRequest(req); err != nil {
+func (s *AvatarService) UploadAvatar(ctx context.Context, req UploadAvatarRequest) (string, error) {
+	sniffed, err := s.validateAvatarRequest(ctx, req)
+	if err != nil {
 		return "", err
 	}
 
-	objectName := s.generateAvatarPath(req.UserID, req.FileName)
+	objectName := s.generateAvatarPath(req.UserID, sniffed.Extension)
 
-	if err := s.uploadToMinio(objectName, req.File, req.FileSize, req.ContentType); err != nil {
+	if err := s.uploadToMinio(ctx, objectName, sniffed.Reader(), sniffed.Size, sniffed.ContentType); err != nil {
 		return "", fmt.Errorf("failed to upload avatar: %w", err)
 	}
 
-	avatarURL := s.generatePublicURL(objectName)
-
-	s.logger.Infof("Successfully uploaded avatar for user %s: %s", req.UserID, avatarURL)
-	return avatarURL, nil
+	s.logger.FromContext(ctx).Infof("Successfully uploaded avatar for user %s: %s", req.UserID, objectName)
+	return objectName, nil
 }
 
-func (s *AvatarService) DeleteAvatar(avatarURL string) error {
-	objectName := s.extractObjectNameFromURL(avatarURL)
+func (s *AvatarService) DeleteAvatar(ctx context.Context, objectName string) error {
 	if objectName == "" {
-		return fmt.Errorf("invalid avatar URL")
+		return domain.ErrInvalidAvatarKey
 	}
 
-	ctx := context.Background()
 	err := s.minioClient.GetClient().RemoveObject(ctx, s.minioClient.GetBucketName(), objectName, minio.RemoveObjectOptions{})
 	if err != nil {
-		s.logger.Errorf("Failed to delete avatar %s: %v", objectName, err)
+		s.logger.FromContext(ctx).Errorf("Failed to delete avatar %s: %v", objectName, err)
 		return fmt.Errorf("failed to delete avatar from storage")
 	}
 
-	s.logger.Infof("Successfully deleted avatar: %s", objectName)
+	s.logger.FromContext(ctx).Infof("Successfully deleted avatar: %s", objectName)
 	return nil
 }
 
-func (s *AvatarService) validateAvatarRequest(req UploadAvatarRequest) error {
-	maxSize := int64(2 * 1024 * 1024) // 2MB
-	if req.FileSize > maxSize {
-		return fmt.Errorf("avatar file too large: maximum size is 2MB")
-	}
-
-	if req.FileSize <= 0 {
-		return fmt.Errorf("invalid file size")
-	}
-
-	allowedTypes := map[string]bool{
-		"image/jpeg": true,
-		"image/jpg":  true,
-		"image/png":  true,
-		"image/gif":  true,
-		"image/webp": true,
-	}
-
-	if !allowedTypes[strings.ToLower(req.ContentType)] {
-		return fmt.Errorf("invalid file type: only images are allowed (jpeg, png, gif, webp)")
+func (s *AvatarService) validateAvatarRequest(ctx context.Context, req UploadAvatarRequest) (*minioClient.SniffedFile, error) {
+	sniffed, err := minioClient.ReadAndSniff(req.File, maxAvatarSize, req.ContentType, req.FileName, allowedAvatarTypes)
+	if err != nil {
+		s.logger.FromContext(ctx).Warnf("Rejected avatar upload for user %s: %v", req.UserID, err)
+		return nil, err
 	}
 
-	return nil
+	return sniffed, nil
 }
 
-func (s *AvatarService) generateAvatarPath(userID uuid.UUID, originalFileName string) string {
-	ext := filepath.Ext(originalFileName)
-	if ext == "" {
-		ext = ".jpg"
-	}
-	ext = strings.ToLower(ext)
-
+func (s *AvatarService) generateAvatarPath(userID uuid.UUID, ext string) string {
 	timestamp := time.Now().Unix()
 	uniqueID := uuid.New().String()
 
 	return fmt.Sprintf("avatars/%s/%d_%s%s", userID.String(), timestamp, uniqueID, ext)
 }
 
-func (s *AvatarService) uploadToMinio(objectName string, file io.Reader, fileSize int64, contentType string) error {
-	ctx := context.Background()
+func (s *AvatarService) uploadToMinio(ctx context.Context, objectName string, file io.Reader, fileSize int64, contentType string) error {
 
 	opts := minio.PutObjectOptions{
 		ContentType:  contentType,
@@ -125,45 +106,9 @@ func (s *AvatarService) uploadToMinio(objectName string, file io.Reader, fileSiz
 	)
 
 	if err != nil {
-		s.logger.Errorf("Failed to upload to MinIO: %v", err)
+		s.logger.FromContext(ctx).Errorf("Failed to upload to MinIO: %v", err)
 		return err
 	}
 
 	return nil
 }
-
-func (s *AvatarService) generatePublicURL(objectName string) string {
-	endpoint := os.Getenv("MINIO_HOST")
-	bucketName := s.minioClient.GetBucketName()
-
-	if endpoint == "" {
-		endpoint = strings.TrimPrefix(s.minioClient.GetEndpoint(), "http://")
-	}
-
-	return fmt.Sprintf("http://%s/%s/%s", endpoint, bucketName, objectName)
-}
-
-func (s *AvatarService) extractObjectNameFromURL(avatarURL string) string {
-	parts := strings.Split(avatarURL, "/")
-	if len(parts) < 3 {
-		return ""
-	}
-
-	bucketName := s.minioClient.GetBucketName()
-	foundBucket := false
-	var objectParts []string
-
-	for _, part := range parts {
-		if foundBucket {
-			objectParts = append(objectParts, part)
-		} else if part == bucketName {
-			foundBucket = true
-		}
-	}
-
-	if len(objectParts) == 0 {
-		return ""
-	}
-
-	return strings.Join(objectParts, "/")
-}
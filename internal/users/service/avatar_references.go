@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"github.com/merdernoty/job-hunter/internal/users/domain"
+	"github.com/merdernoty/job-hunter/pkg/storage"
+)
+
+type avatarReferenceSource struct {
+	userRepo domain.UserRepository
+}
+
+func NewAvatarReferenceSource(userRepo domain.UserRepository) storage.ReferenceSource {
+	return &avatarReferenceSource{userRepo: userRepo}
+}
+
+func (s *avatarReferenceSource) Name() string {
+	return "users.avatar_key"
+}
+
+func (s *avatarReferenceSource) Prefixes() []string {
+	return []string{"avatars"}
+}
+
+func (s *avatarReferenceSource) ReferencedKeys(ctx context.Context, keys []string) (map[string]bool, error) {
+	referenced, err := s.userRepo.GetReferencedAvatarKeys(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]bool, len(referenced))
+	for _, key := range referenced {
+		result[key] = true
+	}
+	return result, nil
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	uploadDomain "github.com/merdernoty/job-hunter/internal/uploads/domain"
+	"github.com/merdernoty/job-hunter/internal/users/domain"
+	"github.com/merdernoty/job-hunter/pkg/storage"
+)
+
+const AvatarUploadPurpose = "avatar"
+
+type avatarUploadAttacher struct {
+	userService domain.UserService
+	urlResolver storage.URLResolver
+}
+
+func NewAvatarUploadAttacher(userService domain.UserService, urlResolver storage.URLResolver) uploadDomain.UploadAttacher {
+	return &avatarUploadAttacher{
+		userService: userService,
+		urlResolver: urlResolver,
+	}
+}
+
+func (a *avatarUploadAttacher) Purpose() string {
+	return AvatarUploadPurpose
+}
+
+func (a *avatarUploadAttacher) Rules() uploadDomain.UploadRules {
+	return uploadDomain.UploadRules{
+		KeyPrefix:    "avatars",
+		MaxSize:      maxAvatarSize,
+		AllowedTypes: allowedAvatarTypes,
+	}
+}
+
+func (a *avatarUploadAttacher) Attach(ctx context.Context, upload *uploadDomain.Upload) (interface{}, error) {
+	avatarKey, err := a.userService.AttachAvatarObject(ctx, upload.OwnerID, upload.ObjectKey)
+	if err != nil {
+		return nil, err
+	}
+
+	avatarURL, err := a.urlResolver.ResolveURL(avatarKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"avatar_url": avatarURL,
+	}, nil
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+
+	notificationDomain "github.com/merdernoty/job-hunter/internal/notifications/domain"
+	"github.com/merdernoty/job-hunter/internal/users/domain"
+	"github.com/merdernoty/job-hunter/pkg/deeplink"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+)
+
+// MatchNotifier tells both users about a mutual like. It lives outside
+// userService because the notifier itself depends on the user service.
+type MatchNotifier struct {
+	notifier  notificationDomain.Notifier
+	deepLinks *deeplink.Codec
+	logger    logger.Logger
+}
+
+func NewMatchNotifier(notifier notificationDomain.Notifier, deepLinks *deeplink.Codec, logger logger.Logger) *MatchNotifier {
+	return &MatchNotifier{
+		notifier:  notifier,
+		deepLinks: deepLinks,
+		logger:    logger,
+	}
+}
+
+// Notify is best effort: failures are logged and never returned.
+func (m *MatchNotifier) Notify(ctx context.Context, a, b *domain.User) {
+	m.notify(ctx, a, b)
+	m.notify(ctx, b, a)
+}
+
+func (m *MatchNotifier) notify(ctx context.Context, recipient, match *domain.User) {
+	name := match.Username
+	if name == "" {
+		name = match.TelegramHandle
+	}
+
+	startParam, _ := m.deepLinks.Encode(deeplink.Link{Type: deeplink.Profile, ID: match.ID})
+
+	_, err := m.notifier.Notify(ctx, recipient.ID, notificationDomain.Message{
+		Kind:       notificationDomain.KindNewMatch,
+		Key:        "notifications.new_match",
+		Args:       []interface{}{html.EscapeString(name)},
+		StartParam: startParam,
+		DedupeKey:  fmt.Sprintf("new_match:%s:%s", recipient.ID, match.ID),
+	})
+	if err != nil && !errors.Is(err, notificationDomain.ErrDuplicateNotification) && !errors.Is(err, notificationDomain.ErrUnreachable) {
+		m.logger.FromContext(ctx).Warnf("Failed to notify user %s about match with %s: %v", recipient.ID, match.ID, err)
+	}
+}
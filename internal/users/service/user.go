@@ -1,12 +1,20 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/merdernoty/job-hunter/config"
+	paymentsDomain "github.com/merdernoty/job-hunter/internal/payments/domain"
 	"github.com/merdernoty/job-hunter/internal/users/domain"
+	"github.com/merdernoty/job-hunter/pkg/deeplink"
+	"github.com/merdernoty/job-hunter/pkg/http/pagination"
 	"github.com/merdernoty/job-hunter/pkg/jwt"
 	"github.com/merdernoty/job-hunter/pkg/logger"
 	"github.com/merdernoty/job-hunter/pkg/telegram"
@@ -16,8 +24,13 @@ type userService struct {
 	userRepo      domain.UserRepository
 	jwtService    *jwt.JWTService
 	dailyViewRepo domain.UserDailyViewRepository
+	swipeRepo     domain.UserSwipeRepository
+	viewRepo      domain.ProfileViewRepository
 	telegramAuth  *telegram.TelegramAuth
 	avatarService *AvatarService
+	deepLinks     *deeplink.Codec
+	entitlements  paymentsDomain.EntitlementService
+	premium       config.PremiumConfig
 	logger        logger.Logger
 }
 
@@ -26,7 +39,12 @@ func NewUserService(
 	telegramAuth *telegram.TelegramAuth,
 	jwtService *jwt.JWTService,
 	dailyViewRepo domain.UserDailyViewRepository,
+	swipeRepo domain.UserSwipeRepository,
+	viewRepo domain.ProfileViewRepository,
 	avatarService *AvatarService,
+	deepLinks *deeplink.Codec,
+	entitlements paymentsDomain.EntitlementService,
+	cfg *config.Config,
 	logger logger.Logger,
 ) domain.UserService {
 	return &userService{
@@ -35,160 +53,275 @@ func NewUserService(
 		telegramAuth:  telegramAuth,
 		avatarService: avatarService,
 		dailyViewRepo: dailyViewRepo,
+		swipeRepo:     swipeRepo,
+		viewRepo:      viewRepo,
+		deepLinks:     deepLinks,
+		entitlements:  entitlements,
+		premium:       cfg.Premium,
 		logger:        logger,
 	}
 }
 
-func (s *userService) AuthFromTelegram(initData string) (*domain.User, string, error) {
-	webAppData, err := s.telegramAuth.ValidateWebAppData(initData)
+func (s *userService) AuthFromTelegram(ctx context.Context, req domain.TelegramAuthRequest) (*domain.AuthResult, error) {
+	webAppData, err := s.telegramAuth.ValidateWebAppData(req.InitData)
 	if err != nil {
-		s.logger.Errorf("Invalid telegram data: %v", err)
-		return nil, "", fmt.Errorf("invalid telegram data")
+		s.logger.FromContext(ctx).Errorf("Invalid telegram data: %v", err)
+		return nil, domain.ErrInvalidTelegramData
 	}
 
-	user, err := s.userRepo.GetByTelegramID(webAppData.User.ID)
-	if err != nil && err.Error() == "user not found" {
-		username := webAppData.User.Username
-		handle := ""
-		if username != "" {
-			handle = "@" + username
-		} else {
-			handle = fmt.Sprintf("id%d", webAppData.User.ID)
+	created := false
+	user, err := s.userRepo.GetByTelegramID(ctx, webAppData.User.ID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		user, err = s.createTelegramUser(ctx, webAppData.User.ID, webAppData.User.Username)
+		if err != nil {
+			return nil, err
 		}
-
-		user = &domain.User{
-			ID:             uuid.New(),
-			TelegramID:     webAppData.User.ID,
-			Username:       webAppData.User.Username,
-			TelegramHandle: handle,
-			CreatedAt:      time.Now(),
-			UpdatedAt:      time.Now(),
-		}
-
-		if err := s.userRepo.Create(user); err != nil {
-			s.logger.Errorf("Failed to create user: %v", err)
-			return nil, "", fmt.Errorf("failed to create user")
-		}
-
-		s.logger.Infof("Created new user from Telegram: %s (%d)", user.Username, user.TelegramID)
+		created = true
 	} else if err != nil {
-		s.logger.Errorf("Database error getting user: %v", err)
-		return nil, "", fmt.Errorf("database error")
+		s.logger.FromContext(ctx).Errorf("Database error getting user: %v", err)
+		return nil, fmt.Errorf("database error")
+	}
+
+	if err := s.userRepo.TouchLastSeen(ctx, user.ID); err != nil {
+		s.logger.FromContext(ctx).Warnf("Failed to record activity for user %s: %v", user.ID, err)
 	}
 
 	token, err := s.jwtService.GenerateToken(user.ID)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed generate jwt token: %w", err)
+		return nil, fmt.Errorf("failed generate jwt token: %w", err)
 	}
 
-	s.logger.Infof("User authenticated: %s (%d)", user.Username, user.TelegramID)
-	return user, token, nil
+	// initData is signed by Telegram, so its start_param wins over the one
+	// the client passed alongside.
+	startParam := webAppData.StartParam
+	if startParam == "" {
+		startParam = req.StartParam
+	}
+
+	s.logger.FromContext(ctx).Infof("User authenticated: %s (%d)", user.Username, user.TelegramID)
+	return &domain.AuthResult{
+		User:   user,
+		Token:  token,
+		Target: s.resolveStartParam(ctx, user, startParam, created),
+	}, nil
 }
 
-func (s *userService) GetUser(id uuid.UUID) (*domain.User, error) {
-	user, err := s.userRepo.GetByID(id)
+// resolveStartParam turns a deep link into a navigation target. Invalid
+// links are ignored so that a stale or tampered link never blocks login.
+func (s *userService) resolveStartParam(ctx context.Context, user *domain.User, startParam string, created bool) *deeplink.Target {
+	if startParam == "" {
+		return nil
+	}
+
+	link, err := s.deepLinks.Decode(startParam)
 	if err != nil {
-		s.logger.Errorf("Failed to get user %s: %v", id, err)
+		s.logger.FromContext(ctx).Warnf("Ignoring invalid start_param %q for user %s", startParam, user.ID)
+		return nil
+	}
+
+	if link.Type == deeplink.Referral && created {
+		if err := s.ApplyReferral(ctx, user.ID, link.ID); err != nil {
+			s.logger.FromContext(ctx).Warnf("Failed to apply referral for user %s: %v", user.ID, err)
+		}
+	}
+
+	target := link.Target()
+	return &target
+}
+
+func (s *userService) GetUser(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	user, err := s.userRepo.GetByID(ctx, id)
+	if errors.Is(err, domain.ErrUserNotFound) {
 		return nil, err
 	}
+	if err != nil {
+		s.logger.FromContext(ctx).Errorf("Failed to get user %s: %v", id, err)
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
 
 	return user, nil
 }
 
-func (s *userService) UpdateUser(id uuid.UUID, req domain.UpdateUserRequest) (*domain.User, error) {
-	existingUser, err := s.userRepo.GetByID(id)
+func (s *userService) GetUserByTelegramID(ctx context.Context, telegramID int64) (*domain.User, error) {
+	return s.userRepo.GetByTelegramID(ctx, telegramID)
+}
+
+// EnsureTelegramUser returns the user with the given Telegram ID, creating it
+// the same way the web app login does if it does not exist yet.
+func (s *userService) EnsureTelegramUser(ctx context.Context, telegramID int64, username string) (*domain.User, error) {
+	user, err := s.userRepo.GetByTelegramID(ctx, telegramID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return s.createTelegramUser(ctx, telegramID, username)
+	}
+	if err != nil {
+		s.logger.FromContext(ctx).Errorf("Database error getting user: %v", err)
+		return nil, fmt.Errorf("database error")
+	}
+
+	return user, nil
+}
+
+// ApplyReferral credits referrerID with inviting the user. Only the first
+// referral counts and users cannot refer themselves.
+func (s *userService) ApplyReferral(ctx context.Context, userID, referrerID uuid.UUID) error {
+	if userID == referrerID {
+		return nil
+	}
+
+	if _, err := s.userRepo.GetByID(ctx, referrerID); err != nil {
+		return err
+	}
+
+	return s.userRepo.SetReferrer(ctx, userID, referrerID)
+}
+
+func (s *userService) createTelegramUser(ctx context.Context, telegramID int64, username string) (*domain.User, error) {
+	handle := ""
+	if username != "" {
+		handle = "@" + username
+	} else {
+		handle = fmt.Sprintf("id%d", telegramID)
+	}
+
+	user := &domain.User{
+		ID:             uuid.New(),
+		TelegramID:     telegramID,
+		Username:       username,
+		TelegramHandle: handle,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		s.logger.FromContext(ctx).Errorf("Failed to create user: %v", err)
+		return nil, fmt.Errorf("failed to create user")
+	}
+
+	s.logger.FromContext(ctx).Infof("Created new user from Telegram: %s (%d)", user.Username, user.TelegramID)
+	return user, nil
+}
+
+func (s *userService) UpdateUser(ctx context.Context, id uuid.UUID, req domain.UpdateUserRequest) (*domain.User, error) {
+	existingUser, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := s.userRepo.Update(id, req); err != nil {
-		s.logger.Errorf("Failed to update user %s: %v", id, err)
+	if err := s.userRepo.Update(ctx, id, req); err != nil {
+		if errors.Is(err, domain.ErrNoFieldsToUpdate) || errors.Is(err, domain.ErrUserNotFound) || errors.Is(err, domain.ErrFieldNotNullable) {
+			return nil, err
+		}
+		s.logger.FromContext(ctx).Errorf("Failed to update user %s: %v", id, err)
 		return nil, fmt.Errorf("failed to update user")
 	}
 
-	updatedUser, err := s.userRepo.GetByID(id)
+	// The avatar object is removed once nothing references it; if that
+	// fails the storage garbage collector picks it up later.
+	if slices.Contains(req.Clear, domain.FieldAvatarURL) && existingUser.AvatarKey != nil && *existingUser.AvatarKey != "" {
+		if err := s.avatarService.DeleteAvatar(ctx, *existingUser.AvatarKey); err != nil {
+			s.logger.FromContext(ctx).Warnf("Failed to delete cleared avatar of user %s: %v", id, err)
+		}
+	}
+
+	updatedUser, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
-		s.logger.Errorf("Failed to get updated user %s: %v", id, err)
+		s.logger.FromContext(ctx).Errorf("Failed to get updated user %s: %v", id, err)
 		return existingUser, nil
 	}
 
-	s.logger.Infof("Updated user: %s", id)
+	s.logger.FromContext(ctx).Infof("Updated user: %s", id)
 	return updatedUser, nil
 }
 
-func (s *userService) UpdateUserAvatar(userID uuid.UUID, file io.Reader, fileName string, fileSize int64, contentType string) (string, error) {
-	existingUser, err := s.userRepo.GetByID(userID)
+func (s *userService) UpdateUserAvatar(ctx context.Context, userID uuid.UUID, file io.Reader, fileName string, contentType string) (string, error) {
+	existingUser, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return "", err
 	}
 
-	if existingUser.AvatarURL != nil && *existingUser.AvatarURL != "" {
-		if err := s.avatarService.DeleteAvatar(*existingUser.AvatarURL); err != nil {
-			s.logger.Warnf("Failed to delete old avatar for user %s: %v", userID, err)
-		}
-	}
-
 	uploadReq := UploadAvatarRequest{
 		UserID:      userID,
 		File:        file,
 		FileName:    fileName,
-		FileSize:    fileSize,
 		ContentType: contentType,
 	}
 
-	avatarURL, err := s.avatarService.UploadAvatar(uploadReq)
+	avatarKey, err := s.avatarService.UploadAvatar(ctx, uploadReq)
+	if err != nil {
+		s.logger.FromContext(ctx).Errorf("Failed to upload avatar for user %s: %v", userID, err)
+		return "", err
+	}
+
+	return s.setAvatar(ctx, existingUser, avatarKey)
+}
+
+func (s *userService) AttachAvatarObject(ctx context.Context, userID uuid.UUID, objectKey string) (string, error) {
+	existingUser, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		s.logger.Errorf("Failed to upload avatar for user %s: %v", userID, err)
 		return "", err
 	}
 
+	return s.setAvatar(ctx, existingUser, objectKey)
+}
+
+func (s *userService) setAvatar(ctx context.Context, existingUser *domain.User, avatarKey string) (string, error) {
 	updateReq := domain.UpdateUserRequest{
-		AvatarURL: &avatarURL,
+		AvatarKey: &avatarKey,
 	}
 
-	updatedUser, err := s.UpdateUser(userID, updateReq)
-	if err != nil {
-		if delErr := s.avatarService.DeleteAvatar(avatarURL); delErr != nil {
-			s.logger.Errorf("Failed to cleanup avatar after DB error: %v", delErr)
+	// Completing the same upload twice sets the key the user already has,
+	// which must never be deleted as the old or the failed avatar.
+	current := existingUser.AvatarKey != nil && *existingUser.AvatarKey == avatarKey
+
+	if _, err := s.UpdateUser(ctx, existingUser.ID, updateReq); err != nil {
+		if !current {
+			if delErr := s.avatarService.DeleteAvatar(ctx, avatarKey); delErr != nil {
+				s.logger.FromContext(ctx).Errorf("Failed to cleanup avatar after DB error: %v", delErr)
+			}
 		}
 		return "", fmt.Errorf("failed to update user avatar in database: %w", err)
 	}
 
-	s.logger.Infof("Successfully updated avatar for user %s: %s", userID, avatarURL)
-	return *updatedUser.AvatarURL, nil
+	if existingUser.AvatarKey != nil && *existingUser.AvatarKey != "" && !current {
+		if err := s.avatarService.DeleteAvatar(ctx, *existingUser.AvatarKey); err != nil {
+			s.logger.FromContext(ctx).Warnf("Failed to delete old avatar for user %s: %v", existingUser.ID, err)
+		}
+	}
+
+	s.logger.FromContext(ctx).Infof("Successfully updated avatar for user %s: %s", existingUser.ID, avatarKey)
+	return avatarKey, nil
 }
 
-func (s *userService) DeleteUserAvatar(userID uuid.UUID) error {
-	user, err := s.userRepo.GetByID(userID)
+func (s *userService) DeleteUserAvatar(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return err
 	}
 
-	if user.AvatarURL == nil || *user.AvatarURL == "" {
-		return fmt.Errorf("user has no avatar")
+	if user.AvatarKey == nil || *user.AvatarKey == "" {
+		return domain.ErrNoAvatar
 	}
 
-	if err := s.avatarService.DeleteAvatar(*user.AvatarURL); err != nil {
-		s.logger.Errorf("Failed to delete avatar file: %v", err)
-	}
-
-	emptyURL := ""
 	updateReq := domain.UpdateUserRequest{
-		AvatarURL: &emptyURL,
+		Clear: []string{domain.FieldAvatarURL},
 	}
 
-	if _, err := s.UpdateUser(userID, updateReq); err != nil {
+	if _, err := s.UpdateUser(ctx, userID, updateReq); err != nil {
 		return fmt.Errorf("failed to update user avatar in database: %w", err)
 	}
 
-	s.logger.Infof("Successfully deleted avatar for user %s", userID)
+	s.logger.FromContext(ctx).Infof("Successfully deleted avatar for user %s", userID)
 	return nil
 }
-func (s *userService) GetRandomUser(viewerID uuid.UUID) (*domain.User, error) {
-	shownToday, err := s.dailyViewRepo.GetTodaysShownUsers(viewerID)
+func (s *userService) GetRandomUser(ctx context.Context, viewerID uuid.UUID) (*domain.User, error) {
+	shownToday, err := s.dailyViewRepo.GetTodaysShownUsers(ctx, viewerID)
 	if err != nil {
-		s.logger.Warnf("Failed to get today's shown users: %v", err)
-		shownToday = []uuid.UUID{}
+		return nil, fmt.Errorf("failed to get today's shown users: %w", err)
+	}
+
+	if limit := s.dailyProfileLimit(ctx, viewerID); limit > 0 && len(shownToday) >= limit {
+		s.logger.FromContext(ctx).Infof("Viewer %s reached the daily limit of %d profiles", viewerID, limit)
+		return nil, domain.ErrDailyLimitReached.WithRetryAfter(untilTomorrow())
 	}
 
 	excludeMap := make(map[uuid.UUID]bool)
@@ -203,17 +336,16 @@ func (s *userService) GetRandomUser(viewerID uuid.UUID) (*domain.User, error) {
 		excludeUserIDs = append(excludeUserIDs, userID)
 	}
 
-	s.logger.Infof("Excluding %d unique users for viewer %s", len(excludeUserIDs)-1, viewerID)
+	s.logger.FromContext(ctx).Infof("Excluding %d unique users for viewer %s", len(excludeUserIDs)-1, viewerID)
 
-	user, err := s.userRepo.GetRandomUser(excludeUserIDs)
+	user, err := s.userRepo.GetRandomUser(ctx, excludeUserIDs)
+	if errors.Is(err, domain.ErrNoUsersAvailable) {
+		s.logger.FromContext(ctx).Infof("All users shown to viewer %s today - no more users available", viewerID)
+		return nil, err
+	}
 	if err != nil {
-		if err.Error() == "no available users found" {
-			s.logger.Infof("All users shown to viewer %s today - no more users available", viewerID)
-			return nil, fmt.Errorf("no more users available today")
-		} else {
-			s.logger.Errorf("Database error getting random user: %v", err)
-			return nil, fmt.Errorf("database error")
-		}
+		s.logger.FromContext(ctx).Errorf("Database error getting random user: %v", err)
+		return nil, fmt.Errorf("database error")
 	}
 
 	dailyView := &domain.UserDailyView{
@@ -223,20 +355,196 @@ func (s *userService) GetRandomUser(viewerID uuid.UUID) (*domain.User, error) {
 		CreatedAt:   time.Now(),
 	}
 
-	if err := s.dailyViewRepo.Create(dailyView); err != nil {
-		s.logger.Warnf("Failed to create daily view record: %v", err)
+	if err := s.dailyViewRepo.Create(ctx, dailyView); err != nil {
+		s.logger.FromContext(ctx).Warnf("Failed to create daily view record: %v", err)
+	}
+
+	s.logger.FromContext(ctx).Infof("Selected user %s (%s) for viewer %s", user.Username, user.ID, viewerID)
+	return user, nil
+}
+
+// untilTomorrow is how long until the daily views, kept per UTC date, reset.
+func untilTomorrow() time.Duration {
+	now := time.Now().UTC()
+	return now.Truncate(24 * time.Hour).Add(24 * time.Hour).Sub(now)
+}
+
+// dailyProfileLimit returns how many profiles the viewer may be shown today,
+// or zero for no limit. Without a free limit there is nothing for premium to
+// raise.
+func (s *userService) dailyProfileLimit(ctx context.Context, viewerID uuid.UUID) int {
+	if s.premium.FreeDailyProfiles == 0 {
+		return 0
+	}
+	if s.entitlements.Has(ctx, viewerID, paymentsDomain.FeatureExtraProfiles) {
+		return s.premium.PremiumDailyProfiles
+	}
+	return s.premium.FreeDailyProfiles
+}
+
+// RecordProfileView remembers that the viewer opened the owner's profile.
+// Failures are only logged: a missed view must not break the profile page.
+func (s *userService) RecordProfileView(ctx context.Context, viewerID, ownerID uuid.UUID) {
+	if viewerID == ownerID {
+		return
+	}
+	if err := s.viewRepo.Record(ctx, ownerID, viewerID); err != nil {
+		s.logger.FromContext(ctx).Warnf("Failed to record profile view %s -> %s: %v", viewerID, ownerID, err)
+	}
+}
+
+func (s *userService) GetProfileViewers(ctx context.Context, ownerID uuid.UUID, limit int) ([]domain.ProfileViewer, error) {
+	if !s.entitlements.Has(ctx, ownerID, paymentsDomain.FeatureProfileViewers) {
+		return nil, domain.ErrPremiumRequired
+	}
+
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+
+	viewers, err := s.viewRepo.ListViewers(ctx, ownerID, limit)
+	if err != nil {
+		return nil, err
+	}
+	if viewers == nil {
+		viewers = []domain.ProfileViewer{}
 	}
 
-	s.logger.Infof("Selected user %s (%s) for viewer %s", user.Username, user.ID, viewerID)
+	return viewers, nil
+}
+
+// Swipe records a like or skip on a profile shown to the viewer today, or
+// changes the action on one swiped before. A like matches when the other
+// user has already liked the viewer back.
+func (s *userService) Swipe(ctx context.Context, viewerID, targetID uuid.UUID, action string) (*domain.SwipeResult, error) {
+	if action != domain.SwipeLike && action != domain.SwipeSkip {
+		return nil, domain.ErrInvalidSwipe
+	}
+	if viewerID == targetID {
+		return nil, domain.ErrSwipeSelf
+	}
+
+	if _, err := s.userRepo.GetByID(ctx, targetID); err != nil {
+		return nil, err
+	}
+
+	shown, err := s.dailyViewRepo.IsUserShownToday(ctx, viewerID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if !shown {
+		swiped, err := s.swipeRepo.HasSwiped(ctx, viewerID, targetID)
+		if err != nil {
+			return nil, err
+		}
+		if !swiped {
+			return nil, domain.ErrSwipeNotShown
+		}
+	}
+
+	swipe := &domain.UserSwipe{ViewerID: viewerID, TargetID: targetID, Action: action}
+	if err := s.swipeRepo.Save(ctx, swipe); err != nil {
+		return nil, err
+	}
+
+	result := &domain.SwipeResult{Action: action}
+	if action == domain.SwipeLike {
+		matched, err := s.swipeRepo.HasLiked(ctx, targetID, viewerID)
+		if err != nil {
+			return nil, err
+		}
+		result.Matched = matched
+	}
+
+	if result.Matched {
+		s.logger.FromContext(ctx).Infof("Users %s and %s matched", viewerID, targetID)
+	}
+	return result, nil
+}
+
+func (s *userService) TouchLastSeen(ctx context.Context, id uuid.UUID) error {
+	return s.userRepo.TouchLastSeen(ctx, id)
+}
+
+// LinkBotChat is called when someone starts the bot, so that users who never
+// opened the mini app exist too and notifications have a destination.
+func (s *userService) LinkBotChat(ctx context.Context, telegramID int64, username string, chatID int64) (*domain.User, error) {
+	user, err := s.EnsureTelegramUser(ctx, telegramID, username)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.SetBotChat(ctx, telegramID, chatID, domain.BotStatusStarted); err != nil {
+		return nil, err
+	}
+	user.BotChatID = &chatID
+	user.BotStatus = domain.BotStatusStarted
+
+	s.logger.FromContext(ctx).Infof("User %s started the bot in chat %d", user.ID, chatID)
 	return user, nil
 }
 
-func (s *userService) GetAllUsers() ([]domain.User, error) {
-	users, err := s.userRepo.GetAllUsers()
+// UpdateBotChat records a change of the bot's membership in the user's
+// private chat. Unknown users are ignored until they send /start.
+func (s *userService) UpdateBotChat(ctx context.Context, telegramID, chatID int64, status string) error {
+	if err := s.userRepo.SetBotChat(ctx, telegramID, chatID, status); err != nil {
+		return err
+	}
+
+	s.logger.FromContext(ctx).Infof("Bot status of telegram_id %d is now %s", telegramID, status)
+	return nil
+}
+
+// MarkBotBlocked flags the user as unreachable after Telegram refused to
+// deliver to them, so that broadcasts skip them.
+func (s *userService) MarkBotBlocked(ctx context.Context, telegramID int64) error {
+	if err := s.userRepo.SetBotStatus(ctx, telegramID, domain.BotStatusBlocked); err != nil {
+		return err
+	}
+
+	s.logger.FromContext(ctx).Infof("User with telegram_id %d has blocked the bot", telegramID)
+	return nil
+}
+
+// maxSearchTerms caps how many words of a query are matched against users.
+const maxSearchTerms = 5
+
+func (s *userService) SearchUsers(ctx context.Context, query string, limit, offset int) ([]domain.User, error) {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) > maxSearchTerms {
+		terms = terms[:maxSearchTerms]
+	}
+
+	users, err := s.userRepo.Search(ctx, terms, limit, offset)
 	if err != nil {
-		s.logger.Warnf("Failed to get users %v", err)
-		return nil, fmt.Errorf("database error %w", err)
+		s.logger.FromContext(ctx).Errorf("Failed to search users for %q: %v", query, err)
+		return nil, fmt.Errorf("failed to search users: %w", err)
 	}
 
 	return users, nil
 }
+
+func (s *userService) ListUsers(ctx context.Context, page *pagination.Request) ([]domain.User, string, error) {
+	createdAfter, err := page.Time(domain.FilterCreatedAfter)
+	if err != nil {
+		return nil, "", err
+	}
+	hasAvatar, err := page.Bool(domain.FilterHasAvatar)
+	if err != nil {
+		return nil, "", err
+	}
+
+	filter := domain.UserFilter{
+		CreatedAfter: createdAfter,
+		HasAvatar:    hasAvatar,
+		HandlePrefix: strings.TrimPrefix(page.String(domain.FilterHandlePrefix), "@"),
+	}
+
+	users, next, err := s.userRepo.List(ctx, filter, page)
+	if err != nil {
+		s.logger.FromContext(ctx).Warnf("Failed to list users: %v", err)
+		return nil, "", err
+	}
+
+	return users, next, nil
+}
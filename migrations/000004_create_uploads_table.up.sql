@@ -0,0 +1,16 @@
+CREATE TABLE uploads (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose TEXT NOT NULL,
+    object_key TEXT NOT NULL UNIQUE,
+    content_type TEXT NOT NULL,
+    declared_size BIGINT NOT NULL,
+    size BIGINT,
+    status TEXT NOT NULL DEFAULT 'pending',
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_uploads_owner ON uploads(owner_id);
+CREATE INDEX idx_uploads_status_expires ON uploads(status, expires_at);
@@ -0,0 +1,7 @@
+ALTER TABLE users RENAME COLUMN avatar_url TO avatar_key;
+
+UPDATE users SET avatar_key = NULL WHERE avatar_key = '';
+
+UPDATE users
+SET avatar_key = substring(avatar_key FROM '(avatars/.*)$')
+WHERE avatar_key ~ '^https?://';
@@ -0,0 +1,17 @@
+ALTER TABLE uploads
+ADD COLUMN file_name TEXT,
+ADD COLUMN checksum TEXT;
+
+CREATE TABLE attachments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    file_name TEXT,
+    object_key TEXT NOT NULL UNIQUE,
+    size BIGINT NOT NULL,
+    content_type TEXT NOT NULL,
+    checksum TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_attachments_owner ON attachments(owner_id, created_at);
@@ -0,0 +1,12 @@
+ALTER TABLE users
+ADD COLUMN role TEXT,
+ADD COLUMN seniority TEXT,
+ADD COLUMN skills TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE TABLE bot_conversations (
+    telegram_id BIGINT PRIMARY KEY,
+    flow TEXT NOT NULL,
+    step TEXT NOT NULL,
+    data JSONB NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
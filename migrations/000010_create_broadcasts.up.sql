@@ -0,0 +1,29 @@
+ALTER TABLE users
+ADD COLUMN last_seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+ADD COLUMN bot_active BOOLEAN NOT NULL DEFAULT false;
+
+-- Existing users were last seen when their profile last changed, not when
+-- this migration ran, so that inactivity segments work right away.
+UPDATE users SET last_seen_at = updated_at;
+
+-- The bot can only reach users who have talked to it: they went through a
+-- bot conversation or were already sent a notification.
+UPDATE users SET bot_active = true
+WHERE telegram_id IN (SELECT telegram_id FROM bot_conversations)
+   OR id IN (SELECT user_id FROM notifications WHERE status = 'sent');
+
+CREATE TABLE broadcasts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    text TEXT NOT NULL,
+    segment JSONB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'draft',
+    audience_size INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    confirmed_at TIMESTAMP WITH TIME ZONE
+);
+
+ALTER TABLE notifications
+ADD COLUMN broadcast_id UUID REFERENCES broadcasts(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_notifications_broadcast ON notifications(broadcast_id) WHERE broadcast_id IS NOT NULL;
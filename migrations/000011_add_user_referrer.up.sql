@@ -0,0 +1,2 @@
+ALTER TABLE users
+ADD COLUMN referred_by UUID REFERENCES users(id) ON DELETE SET NULL;
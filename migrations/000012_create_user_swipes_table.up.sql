@@ -0,0 +1,11 @@
+CREATE TABLE user_swipes (
+    viewer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    target_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    action TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+
+    PRIMARY KEY (viewer_id, target_id)
+);
+
+CREATE INDEX idx_user_swipes_target_action ON user_swipes(target_id, action);
@@ -0,0 +1,17 @@
+ALTER TABLE users
+ADD COLUMN bot_chat_id BIGINT,
+ADD COLUMN bot_status TEXT NOT NULL DEFAULT 'none',
+ADD COLUMN bot_status_at TIMESTAMP WITH TIME ZONE;
+
+-- Private chat IDs equal user IDs, so users who have talked to the bot keep
+-- receiving messages. Everyone else stays 'none' until they start the bot.
+UPDATE users
+SET bot_chat_id = telegram_id,
+    bot_status = CASE WHEN bot_active THEN 'started' ELSE 'blocked' END
+WHERE bot_active
+   OR telegram_id IN (SELECT telegram_id FROM bot_conversations)
+   OR id IN (SELECT user_id FROM notifications WHERE status = 'sent');
+
+ALTER TABLE users DROP COLUMN bot_active;
+
+CREATE INDEX idx_users_bot_status ON users(bot_status);
@@ -0,0 +1,26 @@
+CREATE TABLE channels (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    chat_id BIGINT NOT NULL,
+    type TEXT NOT NULL,
+    title TEXT NOT NULL,
+    username TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+
+    UNIQUE (owner_id, chat_id)
+);
+
+CREATE TABLE channel_posts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    channel_id UUID NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+    source_type TEXT NOT NULL,
+    source_id UUID NOT NULL,
+    message_id BIGINT NOT NULL,
+    closed BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+
+    UNIQUE (channel_id, source_type, source_id)
+);
+
+CREATE INDEX idx_channel_posts_source ON channel_posts(source_type, source_id);
@@ -0,0 +1,37 @@
+CREATE TABLE payments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    product TEXT NOT NULL,
+    currency TEXT NOT NULL,
+    amount INTEGER NOT NULL,
+    telegram_charge_id TEXT NOT NULL UNIQUE,
+    provider_charge_id TEXT,
+    status TEXT NOT NULL DEFAULT 'paid',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    refunded_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_payments_user ON payments(user_id, created_at DESC);
+
+CREATE TABLE entitlements (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    feature TEXT NOT NULL,
+    payment_id UUID NOT NULL REFERENCES payments(id) ON DELETE CASCADE,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_entitlements_user_feature ON entitlements(user_id, feature, expires_at) WHERE revoked_at IS NULL;
+
+CREATE TABLE profile_views (
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    viewer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    viewed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+
+    PRIMARY KEY (owner_id, viewer_id)
+);
+
+CREATE INDEX idx_profile_views_owner ON profile_views(owner_id, viewed_at DESC);
@@ -0,0 +1,9 @@
+-- Counters are disposable, so the table skips the WAL; a crash only resets
+-- the current windows.
+CREATE UNLOGGED TABLE rate_limits (
+    key TEXT PRIMARY KEY,
+    hits INTEGER NOT NULL,
+    reset_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_rate_limits_reset_at ON rate_limits(reset_at);
@@ -0,0 +1 @@
+CREATE INDEX idx_users_created_at_id ON users(created_at, id);
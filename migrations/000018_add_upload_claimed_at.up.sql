@@ -0,0 +1,6 @@
+ALTER TABLE uploads ADD COLUMN claimed_at TIMESTAMP WITH TIME ZONE;
+
+-- Completions interrupted before this column existed count as stale.
+UPDATE uploads SET claimed_at = created_at WHERE status = 'completing';
+
+CREATE INDEX idx_uploads_status_claimed ON uploads(status, claimed_at) WHERE status = 'completing';
@@ -0,0 +1,104 @@
+// Package apperr classifies domain errors so that transports can report them
+// without matching on error strings. Domains declare their errors with the
+// constructors below; callers test for a specific error or for its kind with
+// errors.Is, and read the details with errors.As.
+package apperr
+
+import (
+	"errors"
+	"time"
+)
+
+// Kinds of errors. An *Error matches its kind with errors.Is.
+var (
+	ErrNotFound    = errors.New("not found")
+	ErrConflict    = errors.New("conflict")
+	ErrValidation  = errors.New("validation failed")
+	ErrForbidden   = errors.New("forbidden")
+	ErrRateLimited = errors.New("rate limited")
+	ErrTooLarge    = errors.New("too large")
+	ErrUnsupported = errors.New("unsupported media type")
+	ErrGone        = errors.New("gone")
+	ErrUnavailable = errors.New("unavailable")
+)
+
+// Error is a domain error with a stable, machine-readable code such as
+// "USER_NOT_FOUND".
+type Error struct {
+	Kind    error
+	Code    string
+	Message string
+	// Details says what exactly was wrong, e.g. which part of a request
+	// failed validation.
+	Details string
+	// RetryAfter tells rate limited callers when to try again. Zero means
+	// unknown.
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	if e.Details != "" {
+		return e.Message + ": " + e.Details
+	}
+	return e.Message
+}
+
+// Is reports whether target is the error's kind or an *Error with the same
+// code, so a copy carrying details or a RetryAfter still matches the declared
+// error.
+func (e *Error) Is(target error) bool {
+	if t, ok := target.(*Error); ok {
+		return t.Code == e.Code
+	}
+	return target == e.Kind
+}
+
+// WithRetryAfter returns a copy of e that asks callers to wait d.
+func (e *Error) WithRetryAfter(d time.Duration) *Error {
+	clone := *e
+	clone.RetryAfter = d
+	return &clone
+}
+
+// WithDetails returns a copy of e that explains what was wrong.
+func (e *Error) WithDetails(details string) *Error {
+	clone := *e
+	clone.Details = details
+	return &clone
+}
+
+func NotFound(code, message string) *Error {
+	return &Error{Kind: ErrNotFound, Code: code, Message: message}
+}
+
+func Conflict(code, message string) *Error {
+	return &Error{Kind: ErrConflict, Code: code, Message: message}
+}
+
+func Validation(code, message string) *Error {
+	return &Error{Kind: ErrValidation, Code: code, Message: message}
+}
+
+func Forbidden(code, message string) *Error {
+	return &Error{Kind: ErrForbidden, Code: code, Message: message}
+}
+
+func RateLimited(code, message string, retryAfter time.Duration) *Error {
+	return &Error{Kind: ErrRateLimited, Code: code, Message: message, RetryAfter: retryAfter}
+}
+
+func TooLarge(code, message string) *Error {
+	return &Error{Kind: ErrTooLarge, Code: code, Message: message}
+}
+
+func Unsupported(code, message string) *Error {
+	return &Error{Kind: ErrUnsupported, Code: code, Message: message}
+}
+
+func Gone(code, message string) *Error {
+	return &Error{Kind: ErrGone, Code: code, Message: message}
+}
+
+func Unavailable(code, message string) *Error {
+	return &Error{Kind: ErrUnavailable, Code: code, Message: message}
+}
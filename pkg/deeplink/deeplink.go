@@ -0,0 +1,165 @@
+package deeplink
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+
+	"github.com/google/uuid"
+	"github.com/merdernoty/job-hunter/config"
+	"github.com/merdernoty/job-hunter/pkg/apperr"
+)
+
+// Type is what a deep link points to.
+type Type string
+
+const (
+	Profile       Type = "profile"
+	Vacancy       Type = "vacancy"
+	Referral      Type = "referral"
+	CompanyInvite Type = "company_invite"
+)
+
+// Types lists the supported link types.
+var Types = []Type{Profile, Vacancy, Referral, CompanyInvite}
+
+var prefixes = map[Type]byte{
+	Profile:       'p',
+	Vacancy:       'v',
+	Referral:      'r',
+	CompanyInvite: 'c',
+}
+
+var ErrInvalidLink = apperr.Validation("INVALID_LINK", "invalid deep link")
+
+const (
+	idLength        = 22 // base64url of 16 bytes, unpadded
+	signatureBytes  = 8
+	signatureLength = 11 // base64url of 8 bytes, unpadded
+	encodedLength   = 1 + idLength + signatureLength
+)
+
+var encoding = base64.RawURLEncoding
+
+// Link is a decoded deep link.
+type Link struct {
+	Type Type      `json:"type"`
+	ID   uuid.UUID `json:"id"`
+}
+
+// Target tells the mini app where a link leads.
+type Target struct {
+	Type Type      `json:"type"`
+	ID   uuid.UUID `json:"id"`
+	Path string    `json:"path"`
+}
+
+func (l Link) Target() Target {
+	return Target{Type: l.Type, ID: l.ID, Path: l.Path()}
+}
+
+// Path returns the mini app route the link opens.
+func (l Link) Path() string {
+	switch l.Type {
+	case Profile:
+		return "/users/" + l.ID.String()
+	case Vacancy:
+		return "/vacancies/" + l.ID.String()
+	case CompanyInvite:
+		return "/companies/" + l.ID.String() + "/join"
+	default:
+		return "/"
+	}
+}
+
+// Codec encodes links into start parameters and verifies them back.
+//
+// A start parameter is a one letter type prefix, the base64url encoded ID and
+// a truncated HMAC of both: 34 characters from [A-Za-z0-9_-], which fits the
+// 64 character limit Telegram puts on startapp and /start payloads.
+type Codec struct {
+	key []byte
+}
+
+func NewCodec(cfg *config.Config) *Codec {
+	secret := cfg.DeepLinks.Secret
+	if secret == "" {
+		secret = cfg.Jwt.Secret
+	}
+
+	// Derive a dedicated key so the signatures cannot be reused elsewhere
+	// when the JWT secret is shared.
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("deeplink"))
+
+	return &Codec{key: mac.Sum(nil)}
+}
+
+func ParseType(s string) (Type, error) {
+	t := Type(s)
+	if _, ok := prefixes[t]; !ok {
+		return "", fmt.Errorf("%w: unknown type %q", ErrInvalidLink, s)
+	}
+	return t, nil
+}
+
+func (c *Codec) Encode(link Link) (string, error) {
+	prefix, ok := prefixes[link.Type]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown type %q", ErrInvalidLink, link.Type)
+	}
+
+	payload := string(prefix) + encoding.EncodeToString(link.ID[:])
+	return payload + c.sign(payload), nil
+}
+
+func (c *Codec) Decode(param string) (Link, error) {
+	if len(param) != encodedLength {
+		return Link{}, ErrInvalidLink
+	}
+
+	payload, signature := param[:1+idLength], param[1+idLength:]
+	if !hmac.Equal([]byte(signature), []byte(c.sign(payload))) {
+		return Link{}, ErrInvalidLink
+	}
+
+	var link Link
+	for t, prefix := range prefixes {
+		if payload[0] == prefix {
+			link.Type = t
+		}
+	}
+	if link.Type == "" {
+		return Link{}, ErrInvalidLink
+	}
+
+	raw, err := encoding.DecodeString(payload[1:])
+	if err != nil || len(raw) != len(link.ID) {
+		return Link{}, ErrInvalidLink
+	}
+	copy(link.ID[:], raw)
+
+	return link, nil
+}
+
+func (c *Codec) sign(payload string) string {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte(payload))
+	return encoding.EncodeToString(mac.Sum(nil)[:signatureBytes])
+}
+
+// WebAppURL appends the start parameter to the mini app URL for buttons that
+// open it directly, where Telegram does not pass start_param in initData.
+func WebAppURL(base, startParam string) string {
+	u, err := url.Parse(base)
+	if err != nil || startParam == "" {
+		return base
+	}
+
+	query := u.Query()
+	query.Set("startapp", startParam)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
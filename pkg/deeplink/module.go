@@ -0,0 +1,10 @@
+package deeplink
+
+import "go.uber.org/fx"
+
+var Module = fx.Module(
+	"deeplink",
+	fx.Provide(
+		NewCodec,
+	),
+)
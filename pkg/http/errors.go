@@ -0,0 +1,117 @@
+package http
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/merdernoty/job-hunter/pkg/apperr"
+	"github.com/merdernoty/job-hunter/pkg/i18n"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+)
+
+// ErrorHandler renders every error that reaches Echo in the Response
+// envelope: domain errors from pkg/apperr with their own codes,
+// echo.HTTPError from middleware such as JWTAuth, and anything else as an
+// internal error.
+func ErrorHandler(log logger.Logger) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		if c.Response().Committed {
+			return
+		}
+
+		log := log.FromContext(c.Request().Context())
+
+		var (
+			appErr  *apperr.Error
+			httpErr *echo.HTTPError
+			respErr error
+		)
+		switch {
+		case errors.As(err, &appErr):
+			respErr = DomainErrorResponse(c, appErr)
+		case errors.As(err, &httpErr):
+			if httpErr.Internal != nil {
+				log.Warnf("HTTP error on %s %s: %v", c.Request().Method, c.Path(), httpErr.Internal)
+			}
+			message := http.StatusText(httpErr.Code)
+			if msg, ok := httpErr.Message.(string); ok {
+				message = msg
+			}
+			respErr = ErrorResponse(c, httpErr.Code, statusCode(httpErr.Code), message)
+		default:
+			log.Errorf("Unhandled error on %s %s: %v", c.Request().Method, c.Path(), err)
+			respErr = InternalServerErrorResponse(c, i18n.Message(c, "api.errors.internal"))
+		}
+
+		if respErr != nil {
+			log.Errorf("Failed to write error response: %v", respErr)
+		}
+	}
+}
+
+// DomainErrorResponse writes err with the status of its kind and its code.
+// The message is localized under "api.errors.<code>" when such a key exists.
+func DomainErrorResponse(c echo.Context, err *apperr.Error) error {
+	status := http.StatusInternalServerError
+	switch err.Kind {
+	case apperr.ErrNotFound:
+		status = http.StatusNotFound
+	case apperr.ErrConflict:
+		status = http.StatusConflict
+	case apperr.ErrValidation:
+		status = http.StatusBadRequest
+	case apperr.ErrForbidden:
+		status = http.StatusForbidden
+	case apperr.ErrRateLimited:
+		status = http.StatusTooManyRequests
+	case apperr.ErrTooLarge:
+		status = http.StatusRequestEntityTooLarge
+	case apperr.ErrUnsupported:
+		status = http.StatusUnsupportedMediaType
+	case apperr.ErrGone:
+		status = http.StatusGone
+	case apperr.ErrUnavailable:
+		status = http.StatusServiceUnavailable
+	}
+
+	if err.RetryAfter > 0 {
+		seconds := int(math.Ceil(err.RetryAfter.Seconds()))
+		c.Response().Header().Set("Retry-After", strconv.Itoa(seconds))
+	}
+
+	key := "api.errors." + strings.ToLower(err.Code)
+	message := i18n.Message(c, key)
+	if message == key {
+		message = err.Message
+	}
+
+	return ErrorResponse(c, status, err.Code, message, err.Details)
+}
+
+// ServiceErrorResponse reports a failed service call. Domain errors are
+// handed back to ErrorHandler; anything else is an internal error described
+// by message.
+func ServiceErrorResponse(c echo.Context, err error, message string) error {
+	var appErr *apperr.Error
+	if errors.As(err, &appErr) {
+		return err
+	}
+	return InternalServerErrorResponse(c, message)
+}
+
+// statusCode names a status in the style of the codes used by the helpers
+// in response.go, e.g. 404 is "NOT_FOUND".
+func statusCode(status int) string {
+	if status >= http.StatusInternalServerError {
+		return "INTERNAL_ERROR"
+	}
+	text := http.StatusText(status)
+	if text == "" {
+		return "ERROR"
+	}
+	return strings.ToUpper(strings.NewReplacer(" ", "_", "-", "_", "'", "").Replace(text))
+}
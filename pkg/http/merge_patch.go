@@ -0,0 +1,60 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+
+	"github.com/labstack/echo/v4"
+)
+
+// MIMEMergePatch is the media type of JSON Merge Patch documents (RFC 7396).
+const MIMEMergePatch = "application/merge-patch+json"
+
+var jsonNull = []byte("null")
+
+// BindMergePatch decodes a JSON Merge Patch body into patch, a struct of
+// pointer fields, and validates it with the request's validator. Members
+// left out of the body stay nil; members set to null are nil as well and
+// are returned by name so the caller can clear them. Members that match no
+// field of patch are rejected.
+func BindMergePatch(c echo.Context, patch interface{}) ([]string, error) {
+	mediaType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	if mediaType != MIMEMergePatch {
+		return nil, echo.NewHTTPError(http.StatusUnsupportedMediaType, "Content-Type must be "+MIMEMergePatch)
+	}
+
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid request format").SetInternal(err)
+	}
+
+	// A patch that is not an object would replace the whole resource.
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(body, &members); err != nil || members == nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid request format: the patch must be a JSON object")
+	}
+
+	nulls := []string{}
+	for name, value := range members {
+		if bytes.Equal(bytes.TrimSpace(value), jsonNull) {
+			nulls = append(nulls, name)
+		}
+	}
+	sort.Strings(nulls)
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(patch); err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid request format: "+err.Error())
+	}
+
+	if err := c.Validate(patch); err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Validation failed: "+err.Error())
+	}
+
+	return nulls, nil
+}
@@ -0,0 +1,162 @@
+// Package pagination parses keyset pagination from the query string of list
+// endpoints:
+//
+//	limit    page size, capped by Options.MaxLimit
+//	cursor   opaque position returned as next_cursor by the previous page
+//	sort     an allowlisted field, prefixed with "-" for descending order
+//
+// plus the allowlisted filters of each endpoint, read by name. A cursor
+// records the sort it was issued for and the sort value and ID of the last
+// item, so the next page continues after that item even when rows are
+// inserted meanwhile.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/merdernoty/job-hunter/pkg/apperr"
+)
+
+var (
+	ErrInvalidLimit  = apperr.Validation("INVALID_LIMIT", "limit must be a positive number")
+	ErrInvalidCursor = apperr.Validation("INVALID_CURSOR", "invalid cursor")
+	ErrInvalidSort   = apperr.Validation("INVALID_SORT", "unsupported sort field")
+	ErrInvalidFilter = apperr.Validation("INVALID_FILTER", "invalid filter value")
+)
+
+// Options describes what a list endpoint accepts.
+type Options struct {
+	DefaultLimit int
+	MaxLimit     int
+	// Sorts allowlists the fields the list can be sorted by.
+	Sorts []string
+	// DefaultSort applies when the query has no sort, e.g. "-created_at".
+	DefaultSort string
+	// Filters allowlists the query parameters that filter the list.
+	Filters []string
+}
+
+// Request is a parsed page request.
+type Request struct {
+	Limit int
+	Sort  string
+	Desc  bool
+	// After is the position to continue from, nil on the first page.
+	After   *Cursor
+	Filters map[string]string
+}
+
+// Cursor is the position after the last item of a page.
+type Cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// Parse reads the page request from the query. Unknown sort fields and
+// cursors issued for another sort are rejected; query parameters that are
+// not allowlisted filters are ignored.
+func Parse(c echo.Context, opts Options) (*Request, error) {
+	req := &Request{Limit: opts.DefaultLimit, Filters: make(map[string]string)}
+
+	if raw := c.QueryParam("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			return nil, ErrInvalidLimit
+		}
+		req.Limit = limit
+	}
+	if req.Limit > opts.MaxLimit {
+		req.Limit = opts.MaxLimit
+	}
+
+	sort := c.QueryParam("sort")
+	if sort == "" {
+		sort = opts.DefaultSort
+	}
+	req.Sort = strings.TrimPrefix(sort, "-")
+	req.Desc = req.Sort != sort
+	if !slices.Contains(opts.Sorts, req.Sort) {
+		return nil, ErrInvalidSort
+	}
+
+	if raw := c.QueryParam("cursor"); raw != "" {
+		cursor, err := decode(raw)
+		if err != nil || cursor.Sort != sort {
+			return nil, ErrInvalidCursor
+		}
+		req.After = cursor
+	}
+
+	for _, name := range opts.Filters {
+		if value := strings.TrimSpace(c.QueryParam(name)); value != "" {
+			req.Filters[name] = value
+		}
+	}
+
+	return req, nil
+}
+
+// Next returns the cursor of the page that follows the item with the given
+// sort value and ID.
+func (r *Request) Next(value, id string) string {
+	sort := r.Sort
+	if r.Desc {
+		sort = "-" + sort
+	}
+
+	data, _ := json.Marshal(Cursor{Sort: sort, Value: value, ID: id})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// String returns the filter's value, empty when it is not set.
+func (r *Request) String(name string) string {
+	return r.Filters[name]
+}
+
+// Bool returns the filter as a boolean, nil when it is not set.
+func (r *Request) Bool(name string) (*bool, error) {
+	raw, ok := r.Filters[name]
+	if !ok {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return nil, ErrInvalidFilter
+	}
+	return &value, nil
+}
+
+// Time returns the filter as an RFC 3339 timestamp, nil when it is not set.
+func (r *Request) Time(name string) (*time.Time, error) {
+	raw, ok := r.Filters[name]
+	if !ok {
+		return nil, nil
+	}
+
+	value, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, ErrInvalidFilter
+	}
+	return &value, nil
+}
+
+func decode(raw string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
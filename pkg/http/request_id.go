@@ -0,0 +1,35 @@
+package http
+
+import (
+	"regexp"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+)
+
+// validRequestID limits IDs accepted from clients to something safe to echo
+// back in a header and write to the logs.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// RequestID takes the request's X-Request-ID, or generates one when it is
+// missing or malformed, and returns it in the response header. The ID is
+// attached to the request context so every logger obtained through
+// Logger.FromContext while handling the request includes it.
+func RequestID() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			id := req.Header.Get(echo.HeaderXRequestID)
+			if !validRequestID.MatchString(id) {
+				id = uuid.NewString()
+			}
+			c.Response().Header().Set(echo.HeaderXRequestID, id)
+
+			ctx := logger.ContextWith(req.Context(), "request_id", id)
+			c.SetRequest(req.WithContext(ctx))
+			return next(c)
+		}
+	}
+}
@@ -8,11 +8,12 @@ import (
 )
 
 type Response struct {
-	Success   bool        `json:"success"`
-	Message   string      `json:"message,omitempty"`
-	Data      interface{} `json:"data,omitempty"`
-	Error     *ErrorInfo  `json:"error,omitempty"`
-	Timestamp time.Time   `json:"timestamp"`
+	Success    bool        `json:"success"`
+	Message    string      `json:"message,omitempty"`
+	Data       interface{} `json:"data,omitempty"`
+	Error      *ErrorInfo  `json:"error,omitempty"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+	Timestamp  time.Time   `json:"timestamp"`
 }
 
 type ErrorInfo struct {
@@ -35,6 +36,18 @@ func SuccessResponse(c echo.Context, data interface{}, message ...string) error
 	})
 }
 
+// PageResponse is SuccessResponse for one page of a list. nextCursor is
+// omitted on the last page.
+func PageResponse(c echo.Context, data interface{}, nextCursor string) error {
+	return c.JSON(http.StatusOK, Response{
+		Success:    true,
+		Message:    "Success",
+		Data:       data,
+		NextCursor: nextCursor,
+		Timestamp:  time.Now(),
+	})
+}
+
 func CreatedResponse(c echo.Context, data interface{}, message ...string) error {
 	msg := "Created successfully"
 	if len(message) > 0 {
@@ -0,0 +1,62 @@
+package i18n
+
+import (
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	languageContextKey   = "lang"
+	translatorContextKey = "translator"
+)
+
+// Middleware resolves the request language from Accept-Language so that
+// handlers can localize messages with Message.
+func (t *Translator) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			tags := acceptedLanguages(c.Request().Header.Get("Accept-Language"))
+			if len(tags) == 0 {
+				// API clients that do not ask for a language keep getting
+				// English, as they always have.
+				tags = []string{FallbackLanguage}
+			}
+
+			c.Set(translatorContextKey, t)
+			c.Set(languageContextKey, t.Resolve(tags...))
+			return next(c)
+		}
+	}
+}
+
+// Language returns the language resolved for the request.
+func Language(c echo.Context) string {
+	if lang, ok := c.Get(languageContextKey).(string); ok {
+		return lang
+	}
+	return DefaultLanguage
+}
+
+// Message localizes key for the request. Without the middleware the key is
+// returned as is.
+func Message(c echo.Context, key string, args ...interface{}) string {
+	t, ok := c.Get(translatorContextKey).(*Translator)
+	if !ok {
+		return key
+	}
+	return t.T(Language(c), key, args...)
+}
+
+// acceptedLanguages returns the tags of an Accept-Language header in the order
+// given, ignoring quality values.
+func acceptedLanguages(header string) []string {
+	var tags []string
+	for _, part := range strings.Split(header, ",") {
+		tag := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		if tag != "" && tag != "*" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
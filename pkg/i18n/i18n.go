@@ -0,0 +1,127 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+//go:embed locales/*.json
+var locales embed.FS
+
+const (
+	// DefaultLanguage is used when the client does not say which language it
+	// speaks.
+	DefaultLanguage = "ru"
+	// FallbackLanguage is used when the client asks for a language we have no
+	// catalog for.
+	FallbackLanguage = "en"
+)
+
+// Translator looks up messages in the embedded per-language catalogs.
+type Translator struct {
+	catalogs  map[string]map[string]string
+	languages []string
+}
+
+func NewTranslator() (*Translator, error) {
+	files, err := locales.ReadDir("locales")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read locales: %w", err)
+	}
+
+	t := &Translator{catalogs: make(map[string]map[string]string)}
+	for _, file := range files {
+		if file.IsDir() || path.Ext(file.Name()) != ".json" {
+			continue
+		}
+
+		raw, err := locales.ReadFile("locales/" + file.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read locale %s: %w", file.Name(), err)
+		}
+
+		catalog := map[string]string{}
+		if err := json.Unmarshal(raw, &catalog); err != nil {
+			return nil, fmt.Errorf("failed to parse locale %s: %w", file.Name(), err)
+		}
+
+		lang := strings.TrimSuffix(file.Name(), ".json")
+		t.catalogs[lang] = catalog
+		t.languages = append(t.languages, lang)
+	}
+	sort.Strings(t.languages)
+
+	for _, lang := range []string{DefaultLanguage, FallbackLanguage} {
+		if _, ok := t.catalogs[lang]; !ok {
+			return nil, fmt.Errorf("missing catalog for %q", lang)
+		}
+	}
+
+	return t, nil
+}
+
+// Languages lists the languages that have a catalog.
+func (t *Translator) Languages() []string {
+	return t.languages
+}
+
+func (t *Translator) Supports(lang string) bool {
+	_, ok := t.catalogs[lang]
+	return ok
+}
+
+// Resolve picks the first supported language among the candidates, which are
+// typically a saved preference followed by the client's language code.
+func (t *Translator) Resolve(candidates ...string) string {
+	requested := false
+	for _, candidate := range candidates {
+		lang := Normalize(candidate)
+		if lang == "" {
+			continue
+		}
+		if t.Supports(lang) {
+			return lang
+		}
+		requested = true
+	}
+
+	if requested {
+		return FallbackLanguage
+	}
+	return DefaultLanguage
+}
+
+// T returns the message for key in lang, formatted with args. Missing keys fall
+// back to the default catalog and finally to the key itself.
+func (t *Translator) T(lang, key string, args ...interface{}) string {
+	message, ok := t.catalogs[lang][key]
+	if !ok {
+		message, ok = t.catalogs[DefaultLanguage][key]
+	}
+	if !ok {
+		message = key
+	}
+
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// Name returns the language's own name, e.g. "English".
+func (t *Translator) Name(lang string) string {
+	return t.T(lang, "language.name")
+}
+
+// Normalize reduces a language tag such as "en-US" to its base language.
+func Normalize(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if i := strings.IndexAny(code, "-_"); i >= 0 {
+		code = code[:i]
+	}
+	return code
+}
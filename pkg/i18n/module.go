@@ -0,0 +1,10 @@
+package i18n
+
+import "go.uber.org/fx"
+
+var Module = fx.Module(
+	"i18n",
+	fx.Provide(
+		NewTranslator,
+	),
+)
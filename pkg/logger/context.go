@@ -0,0 +1,30 @@
+package logger
+
+import "context"
+
+type fieldsKey struct{}
+
+// ContextWith returns a copy of ctx carrying the key-value pairs in addition
+// to those already attached. Loggers obtained through FromContext add them
+// to every entry, which is how request and user IDs reach the log lines of
+// services and repositories.
+func ContextWith(ctx context.Context, fields ...interface{}) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+
+	existing := Fields(ctx)
+	merged := make([]interface{}, 0, len(existing)+len(fields))
+	merged = append(merged, existing...)
+	merged = append(merged, fields...)
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// Fields returns the key-value pairs attached to ctx by ContextWith.
+func Fields(ctx context.Context) []interface{} {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsKey{}).([]interface{})
+	return fields
+}
@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"context"
 	"os"
 
 	"github.com/merdernoty/job-hunter/config"
@@ -22,6 +23,11 @@ type Logger interface {
 	DPanicf(template string, args ...interface{})
 	Fatal(args ...interface{})
 	Fatalf(template string, args ...interface{})
+	// With returns a logger that adds the key-value pairs to every entry.
+	With(fields ...interface{}) Logger
+	// FromContext returns a logger that adds the fields attached to ctx by
+	// ContextWith, such as the request and user IDs.
+	FromContext(ctx context.Context) Logger
 }
 
 type apiLogger struct {
@@ -149,4 +155,15 @@ func (l *apiLogger) Fatal(args ...interface{}) {
 
 func (l *apiLogger) Fatalf(template string, args ...interface{}) {
 	l.sugarLogger.Fatalf(template, args...)
-}
\ No newline at end of file
+}
+
+func (l *apiLogger) With(fields ...interface{}) Logger {
+	if len(fields) == 0 {
+		return l
+	}
+	return &apiLogger{cfg: l.cfg, sugarLogger: l.sugarLogger.With(fields...)}
+}
+
+func (l *apiLogger) FromContext(ctx context.Context) Logger {
+	return l.With(Fields(ctx)...)
+}
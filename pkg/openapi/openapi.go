@@ -0,0 +1,252 @@
+// Package openapi builds an OpenAPI 3 document for the REST API. Schemas are
+// derived from the Go types the handlers bind and return, so the document
+// follows the code; see Document.Schema for how struct tags are translated.
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	httpResponse "github.com/merdernoty/job-hunter/pkg/http"
+)
+
+const Version = "3.0.3"
+
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Tags       []Tag                `json:"tags,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+
+	// types maps struct types to their component names.
+	types map[reflect.Type]string
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// PathItem holds the operations of one path by lower-case HTTP method.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// BearerAuth requires the JWT issued by /api/v1/auth/telegram.
+var BearerAuth = []map[string][]string{{"bearerAuth": {}}}
+
+// New returns a document with the response envelope and the bearer scheme
+// already in its components.
+func New(title, version string) *Document {
+	d := &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: title, Version: version},
+		Paths:   make(map[string]*PathItem),
+		Components: Components{
+			Schemas: make(map[string]*Schema),
+			SecuritySchemes: map[string]*SecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+		types: make(map[reflect.Type]string),
+	}
+
+	d.Schema(httpResponse.Response{})
+	d.Components.Schemas["ErrorResponse"] = &Schema{
+		AllOf: []*Schema{
+			Ref("Response"),
+			{
+				Type:       "object",
+				Properties: map[string]*Schema{"error": Ref("ErrorInfo")},
+				Required:   []string{"error"},
+			},
+		},
+	}
+
+	return d
+}
+
+var pathParam = regexp.MustCompile(`:([A-Za-z0-9_]+)`)
+
+// Add documents the operation at an Echo route path such as
+// "/api/v1/users/:id". Path parameters are declared automatically, as UUIDs
+// since those are the only ones the API uses, and every operation gets the
+// error envelope as its default response.
+func (d *Document) Add(method, path string, op Operation) {
+	for _, match := range pathParam.FindAllStringSubmatch(path, -1) {
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:     match[1],
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string", Format: "uuid"},
+		})
+	}
+
+	if op.Responses == nil {
+		op.Responses = make(map[string]*Response)
+	}
+	if _, ok := op.Responses["default"]; !ok {
+		op.Responses["default"] = &Response{
+			Description: "Error in the standard envelope",
+			Content:     jsonContent(Ref("ErrorResponse")),
+		}
+	}
+
+	key := PathKey(path)
+	item, ok := d.Paths[key]
+	if !ok {
+		item = &PathItem{}
+		d.Paths[key] = item
+	}
+	(*item)[strings.ToLower(method)] = &op
+}
+
+// Has reports whether the route is documented.
+func (d *Document) Has(method, path string) bool {
+	item, ok := d.Paths[PathKey(path)]
+	if !ok {
+		return false
+	}
+	_, ok = (*item)[strings.ToLower(method)]
+	return ok
+}
+
+// PathKey converts an Echo path to OpenAPI's template syntax.
+func PathKey(path string) string {
+	return pathParam.ReplaceAllString(path, "{$1}")
+}
+
+// JSON describes a required JSON request body of v's type.
+func (d *Document) JSON(v interface{}) *RequestBody {
+	return &RequestBody{Required: true, Content: jsonContent(d.Schema(v))}
+}
+
+// MergePatch describes a required JSON Merge Patch body of v's type.
+func (d *Document) MergePatch(v interface{}) *RequestBody {
+	return &RequestBody{
+		Required: true,
+		Content:  map[string]*MediaType{httpResponse.MIMEMergePatch: {Schema: d.Schema(v)}},
+	}
+}
+
+// Multipart describes a form upload with one binary file field.
+func Multipart(field string) *RequestBody {
+	return &RequestBody{
+		Required: true,
+		Content: map[string]*MediaType{
+			"multipart/form-data": {Schema: &Schema{
+				Type:       "object",
+				Properties: map[string]*Schema{field: {Type: "string", Format: "binary"}},
+				Required:   []string{field},
+			}},
+		},
+	}
+}
+
+// OK describes a 200 response with data of v's type in the envelope; nil
+// data documents a response with only a message.
+func (d *Document) OK(v interface{}) map[string]*Response {
+	return d.Responds(http.StatusOK, v)
+}
+
+// Created is OK for 201 responses.
+func (d *Document) Created(v interface{}) map[string]*Response {
+	return d.Responds(http.StatusCreated, v)
+}
+
+func (d *Document) Responds(status int, v interface{}) map[string]*Response {
+	envelope := Ref("Response")
+	if v != nil {
+		envelope = &Schema{
+			AllOf: []*Schema{
+				Ref("Response"),
+				{Type: "object", Properties: map[string]*Schema{"data": d.Schema(v)}},
+			},
+		}
+	}
+
+	return map[string]*Response{
+		strconv.Itoa(status): {
+			Description: http.StatusText(status),
+			Content:     jsonContent(envelope),
+		},
+	}
+}
+
+// Redirect describes a 302 response to the Location header.
+func Redirect(description string) map[string]*Response {
+	return map[string]*Response{
+		strconv.Itoa(http.StatusFound): {
+			Description: description,
+			Headers: map[string]*Header{
+				"Location": {Schema: &Schema{Type: "string", Format: "uri"}},
+			},
+		},
+	}
+}
+
+// Query declares an optional query parameter.
+func Query(name, typ, description string) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: &Schema{Type: typ}}
+}
+
+func jsonContent(schema *Schema) map[string]*MediaType {
+	return map[string]*MediaType{"application/json": {Schema: schema}}
+}
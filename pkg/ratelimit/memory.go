@@ -0,0 +1,51 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryGCInterval is how often windows that have ended are dropped.
+const memoryGCInterval = time.Minute
+
+// MemoryStore keeps counters in the process, so every replica limits on its
+// own.
+type MemoryStore struct {
+	mu      sync.Mutex
+	windows map[string]*window
+	lastGC  time.Time
+}
+
+type window struct {
+	hits  int
+	reset time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{windows: make(map[string]*window), lastGC: time.Now()}
+}
+
+func (s *MemoryStore) Hit(ctx context.Context, key string, length time.Duration) (int, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastGC) > memoryGCInterval {
+		for k, w := range s.windows {
+			if !now.Before(w.reset) {
+				delete(s.windows, k)
+			}
+		}
+		s.lastGC = now
+	}
+
+	w, ok := s.windows[key]
+	if !ok || !now.Before(w.reset) {
+		w = &window{reset: now.Add(length)}
+		s.windows[key] = w
+	}
+	w.hits++
+
+	return w.hits, w.reset, nil
+}
@@ -0,0 +1,30 @@
+package ratelimit
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/merdernoty/job-hunter/config"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module(
+	"ratelimit",
+	fx.Provide(
+		NewStore,
+		NewLimiter,
+	),
+)
+
+// NewStore returns the store selected by the ratelimit.store setting.
+func NewStore(cfg *config.Config, db *sqlx.DB, logger logger.Logger) (Store, error) {
+	switch cfg.RateLimit.Store {
+	case "", "memory":
+		return NewMemoryStore(), nil
+	case "postgres":
+		return NewPostgresStore(db, logger), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit store %q", cfg.RateLimit.Store)
+	}
+}
@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+)
+
+// postgresGCInterval is how often each replica deletes windows that have
+// ended.
+const postgresGCInterval = 5 * time.Minute
+
+// PostgresStore keeps counters in the rate_limits table so that all
+// replicas share them. Windows are timed by the database clock.
+type PostgresStore struct {
+	db     *sqlx.DB
+	logger logger.Logger
+
+	mu     sync.Mutex
+	lastGC time.Time
+}
+
+func NewPostgresStore(db *sqlx.DB, logger logger.Logger) *PostgresStore {
+	return &PostgresStore{db: db, logger: logger, lastGC: time.Now()}
+}
+
+func (s *PostgresStore) Hit(ctx context.Context, key string, window time.Duration) (int, time.Time, error) {
+	s.collectGarbage(ctx)
+
+	query := `
+		INSERT INTO rate_limits (key, hits, reset_at)
+		VALUES ($1, 1, NOW() + make_interval(secs => $2))
+		ON CONFLICT (key) DO UPDATE SET
+			hits = CASE WHEN rate_limits.reset_at <= NOW() THEN 1 ELSE rate_limits.hits + 1 END,
+			reset_at = CASE WHEN rate_limits.reset_at <= NOW() THEN EXCLUDED.reset_at ELSE rate_limits.reset_at END
+		RETURNING hits, reset_at`
+
+	var (
+		hits  int
+		reset time.Time
+	)
+	if err := s.db.QueryRowxContext(ctx, query, key, window.Seconds()).Scan(&hits, &reset); err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to count hit: %w", err)
+	}
+
+	return hits, reset, nil
+}
+
+func (s *PostgresStore) collectGarbage(ctx context.Context) {
+	s.mu.Lock()
+	if time.Since(s.lastGC) < postgresGCInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastGC = time.Now()
+	s.mu.Unlock()
+
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM rate_limits WHERE reset_at <= NOW()`); err != nil {
+		s.logger.FromContext(ctx).Warnf("Failed to delete expired rate limit windows: %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+// Package ratelimit limits API requests per client in fixed windows. Each
+// policy counts separately, so a route with its own policy does not use up
+// the default allowance. Counters live in a Store, in memory for a single
+// replica or in Postgres to share them between replicas.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/merdernoty/job-hunter/config"
+	"github.com/merdernoty/job-hunter/pkg/apperr"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+)
+
+const (
+	HeaderLimit     = "X-RateLimit-Limit"
+	HeaderRemaining = "X-RateLimit-Remaining"
+	// HeaderReset is the number of seconds until the window resets.
+	HeaderReset = "X-RateLimit-Reset"
+)
+
+type Store interface {
+	// Hit counts a request against key and returns the number of requests
+	// in the current window, including this one, and when the window ends.
+	// A window of the given length starts with the first request after the
+	// previous one ended.
+	Hit(ctx context.Context, key string, window time.Duration) (int, time.Time, error)
+}
+
+// KeyFunc identifies the client a request is counted against.
+type KeyFunc func(c echo.Context) string
+
+// Policy allows Requests per Window. Policies with the same name share
+// their counters.
+type Policy struct {
+	Name     string
+	Requests int
+	Window   time.Duration
+}
+
+func NewPolicy(name string, cfg config.RateLimitPolicy) Policy {
+	return Policy{Name: name, Requests: cfg.Requests, Window: cfg.Window}
+}
+
+type Limiter struct {
+	enabled bool
+	store   Store
+	logger  logger.Logger
+
+	mu     sync.RWMutex
+	policy Policy
+	routes map[string]Policy
+}
+
+func NewLimiter(cfg *config.Config, store Store, logger logger.Logger) *Limiter {
+	return &Limiter{
+		enabled: cfg.RateLimit.Enabled,
+		store:   store,
+		logger:  logger,
+		policy:  NewPolicy("default", cfg.RateLimit.Default),
+		routes:  make(map[string]Policy),
+	}
+}
+
+// Route overrides the default policy for a route, given as registered with
+// Echo, e.g. "/api/v1/uploads/:id/complete".
+func (l *Limiter) Route(method, path string, policy Policy) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.routes[method+" "+path] = policy
+}
+
+func (l *Limiter) policyFor(method, path string) Policy {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if policy, ok := l.routes[method+" "+path]; ok {
+		return policy
+	}
+	return l.policy
+}
+
+// Middleware counts every request against its route's policy and sets the
+// X-RateLimit-* headers. Requests over the limit fail with a rate limited
+// error carrying Retry-After. If the store fails the request is let through,
+// so that an outage of the counters does not take the API down with it.
+func (l *Limiter) Middleware(key KeyFunc) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !l.enabled {
+				return next(c)
+			}
+
+			policy := l.policyFor(c.Request().Method, c.Path())
+			if policy.Requests <= 0 || policy.Window <= 0 {
+				return next(c)
+			}
+
+			ctx := c.Request().Context()
+			client := key(c)
+			hits, reset, err := l.store.Hit(ctx, policy.Name+":"+client, policy.Window)
+			if err != nil {
+				l.logger.FromContext(ctx).Errorf("Failed to count request for rate limit %s: %v", policy.Name, err)
+				return next(c)
+			}
+
+			retryAfter := time.Until(reset)
+			if retryAfter < 0 {
+				retryAfter = 0
+			}
+
+			header := c.Response().Header()
+			header.Set(HeaderLimit, strconv.Itoa(policy.Requests))
+			header.Set(HeaderRemaining, strconv.Itoa(max(policy.Requests-hits, 0)))
+			header.Set(HeaderReset, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+
+			if hits > policy.Requests {
+				l.logger.FromContext(ctx).Warnf("Rate limit %s exceeded by %s", policy.Name, client)
+				return apperr.RateLimited("RATE_LIMITED", "Too many requests", retryAfter)
+			}
+
+			return next(c)
+		}
+	}
+}
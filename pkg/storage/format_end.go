@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// The functions below return the offset where a file's own data ends, so
+// that anything appended after it can be inspected, or -1 when the
+// structure cannot be followed to its end.
+
+// jpegEnd walks the marker segments up to the EOI marker. Entropy-coded
+// data after SOS runs until the next marker that is neither a stuffed zero
+// nor a restart marker; EXIF thumbnails sit inside APP segments and are
+// skipped with them.
+func jpegEnd(data []byte) int {
+	i := 2
+	for i+2 <= len(data) {
+		if data[i] != 0xFF {
+			return -1
+		}
+
+		marker := data[i+1]
+		switch {
+		case marker == 0xFF:
+			i++
+			continue
+		case marker == 0xD9:
+			return i + 2
+		case marker == 0x01 || marker >= 0xD0 && marker <= 0xD7:
+			i += 2
+			continue
+		}
+
+		if i+4 > len(data) {
+			return -1
+		}
+		i += 2 + int(binary.BigEndian.Uint16(data[i+2:]))
+
+		if marker == 0xDA {
+			for i+1 < len(data) && !isJPEGMarker(data[i], data[i+1]) {
+				i++
+			}
+		}
+	}
+	return -1
+}
+
+func isJPEGMarker(b, next byte) bool {
+	return b == 0xFF && next != 0x00 && next != 0xFF && (next < 0xD0 || next > 0xD7)
+}
+
+// pngEnd walks the chunks up to IEND.
+func pngEnd(data []byte) int {
+	i := 8
+	for i+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		chunkType := data[i+4 : i+8]
+		i += 12 + length
+		if i > len(data) || i < 0 {
+			return -1
+		}
+		if string(chunkType) == "IEND" {
+			return i
+		}
+	}
+	return -1
+}
+
+// gifEnd walks the blocks up to the trailer byte.
+func gifEnd(data []byte) int {
+	if len(data) < 13 {
+		return -1
+	}
+
+	i := 13
+	if data[10]&0x80 != 0 {
+		i += 3 << (data[10]&0x07 + 1)
+	}
+
+	for i < len(data) {
+		switch data[i] {
+		case 0x3B:
+			return i + 1
+		case 0x21:
+			// Introducer and label, then data sub-blocks.
+			i += 2
+		case 0x2C:
+			if i+10 > len(data) {
+				return -1
+			}
+			flags := data[i+9]
+			i += 10
+			if flags&0x80 != 0 {
+				i += 3 << (flags&0x07 + 1)
+			}
+			// LZW minimum code size, then data sub-blocks.
+			i++
+		default:
+			return -1
+		}
+
+		for i < len(data) && data[i] != 0 {
+			i += int(data[i]) + 1
+		}
+		i++
+	}
+	return -1
+}
+
+// webpEnd reads the RIFF container size.
+func webpEnd(data []byte) int {
+	end := 8 + int(binary.LittleEndian.Uint32(data[4:8]))
+	if end%2 == 1 {
+		end++
+	}
+	if end > len(data) {
+		return -1
+	}
+	return end
+}
+
+// pdfEnd is the last end-of-file marker, after any incremental updates.
+func pdfEnd(data []byte) int {
+	i := bytes.LastIndex(data, []byte("%%EOF"))
+	if i < 0 {
+		return -1
+	}
+	return i + len("%%EOF")
+}
+
+// maxZIPComment bounds how far from the end ZIP readers search for the
+// end-of-central-directory record.
+const maxZIPComment = 0xFFFF
+
+// hasZIPDirectory reports whether data ends with a ZIP end-of-central-
+// directory record, which is what makes ZIP readers open a file whatever
+// comes before it.
+func hasZIPDirectory(data []byte) bool {
+	const recordLen = 22
+
+	from := len(data) - recordLen - maxZIPComment
+	if from < 0 {
+		from = 0
+	}
+
+	tail := data[from:]
+	for i := len(tail) - recordLen; i >= 0; i-- {
+		if !bytes.HasPrefix(tail[i:], []byte("PK\x05\x06")) {
+			continue
+		}
+		commentLen := int(binary.LittleEndian.Uint16(tail[i+20:]))
+		if i+recordLen+commentLen == len(tail) {
+			return true
+		}
+	}
+	return false
+}
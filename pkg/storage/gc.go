@@ -0,0 +1,203 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/merdernoty/job-hunter/pkg/logger"
+	"github.com/minio/minio-go/v7"
+)
+
+const gcBatchSize = 500
+
+// ReferenceSource tells the garbage collector which object keys are still in
+// use. Modules that store object keys register a source into the
+// "storage_references" fx group.
+type ReferenceSource interface {
+	Name() string
+	// Prefixes lists the key prefixes this source owns. The collector only
+	// scans prefixes that at least one source owns.
+	Prefixes() []string
+	// ReferencedKeys returns the subset of keys that are still referenced.
+	ReferencedKeys(ctx context.Context, keys []string) (map[string]bool, error)
+}
+
+type GCOptions struct {
+	DryRun      bool
+	GracePeriod time.Duration
+}
+
+type GCObject struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"last_modified"`
+	Error        string    `json:"error,omitempty"`
+}
+
+type GCReport struct {
+	DryRun       bool       `json:"dry_run"`
+	GracePeriod  string     `json:"grace_period"`
+	Prefixes     []string   `json:"prefixes"`
+	StartedAt    time.Time  `json:"started_at"`
+	FinishedAt   time.Time  `json:"finished_at"`
+	Scanned      int        `json:"scanned"`
+	Referenced   int        `json:"referenced"`
+	TooRecent    int        `json:"too_recent"`
+	Deleted      []GCObject `json:"deleted"`
+	Failed       []GCObject `json:"failed"`
+	DeletedBytes int64      `json:"deleted_bytes"`
+}
+
+type GarbageCollector struct {
+	minioClient *MinIOClient
+	sources     []ReferenceSource
+	logger      logger.Logger
+}
+
+func NewGarbageCollector(minioClient *MinIOClient, logger logger.Logger, sources []ReferenceSource) *GarbageCollector {
+	return &GarbageCollector{
+		minioClient: minioClient,
+		sources:     sources,
+		logger:      logger,
+	}
+}
+
+// Run lists every object under the owned prefixes and removes those that no
+// source references and that are older than the grace period. In dry-run mode
+// the report lists what would have been deleted without touching the bucket.
+func (gc *GarbageCollector) Run(ctx context.Context, opts GCOptions) (*GCReport, error) {
+	report := &GCReport{
+		DryRun:      opts.DryRun,
+		GracePeriod: opts.GracePeriod.String(),
+		Prefixes:    gc.prefixes(),
+		StartedAt:   time.Now(),
+		Deleted:     []GCObject{},
+		Failed:      []GCObject{},
+	}
+	cutoff := report.StartedAt.Add(-opts.GracePeriod)
+
+	gc.logger.Infof("Starting storage garbage collection (dry run: %t, grace period: %v, prefixes: %v)",
+		opts.DryRun, opts.GracePeriod, report.Prefixes)
+
+	for _, prefix := range report.Prefixes {
+		if err := gc.collectPrefix(ctx, prefix, cutoff, opts.DryRun, report); err != nil {
+			report.FinishedAt = time.Now()
+			return report, err
+		}
+	}
+
+	report.FinishedAt = time.Now()
+	gc.logger.Infof("Storage garbage collection finished: scanned=%d referenced=%d too_recent=%d deleted=%d failed=%d bytes=%d",
+		report.Scanned, report.Referenced, report.TooRecent, len(report.Deleted), len(report.Failed), report.DeletedBytes)
+
+	return report, nil
+}
+
+func (gc *GarbageCollector) collectPrefix(ctx context.Context, prefix string, cutoff time.Time, dryRun bool, report *GCReport) error {
+	batch := make([]minio.ObjectInfo, 0, gcBatchSize)
+
+	objects := gc.minioClient.GetClient().ListObjects(ctx, gc.minioClient.GetBucketName(), minio.ListObjectsOptions{
+		Prefix:    prefix,
+		Recursive: true,
+	})
+
+	for object := range objects {
+		if object.Err != nil {
+			return fmt.Errorf("failed to list objects under %s: %w", prefix, object.Err)
+		}
+
+		report.Scanned++
+		if object.LastModified.After(cutoff) {
+			report.TooRecent++
+			continue
+		}
+
+		batch = append(batch, object)
+		if len(batch) == gcBatchSize {
+			if err := gc.collectBatch(ctx, batch, dryRun, report); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+
+	if len(batch) > 0 {
+		return gc.collectBatch(ctx, batch, dryRun, report)
+	}
+
+	return nil
+}
+
+func (gc *GarbageCollector) collectBatch(ctx context.Context, batch []minio.ObjectInfo, dryRun bool, report *GCReport) error {
+	keys := make([]string, len(batch))
+	for i, object := range batch {
+		keys[i] = object.Key
+	}
+
+	referenced := make(map[string]bool, len(keys))
+	for _, source := range gc.sources {
+		found, err := source.ReferencedKeys(ctx, keys)
+		if err != nil {
+			return fmt.Errorf("failed to load references from %s: %w", source.Name(), err)
+		}
+		for key := range found {
+			referenced[key] = true
+		}
+	}
+
+	for _, object := range batch {
+		if referenced[object.Key] {
+			report.Referenced++
+			continue
+		}
+
+		orphan := GCObject{
+			Key:          object.Key,
+			Size:         object.Size,
+			LastModified: object.LastModified,
+		}
+
+		if !dryRun {
+			if err := gc.minioClient.RemoveObject(object.Key); err != nil && !IsNotFound(err) {
+				gc.logger.Warnf("Failed to delete orphaned object %s: %v", object.Key, err)
+				orphan.Error = err.Error()
+				report.Failed = append(report.Failed, orphan)
+				continue
+			}
+			gc.logger.Infof("Deleted orphaned object %s (%d bytes)", object.Key, object.Size)
+		}
+
+		report.Deleted = append(report.Deleted, orphan)
+		report.DeletedBytes += object.Size
+	}
+
+	return nil
+}
+
+// prefixes returns the distinct prefixes owned by sources, dropping any that
+// are nested inside another so no object is scanned twice.
+func (gc *GarbageCollector) prefixes() []string {
+	var all []string
+	for _, source := range gc.sources {
+		for _, prefix := range source.Prefixes() {
+			if !strings.HasSuffix(prefix, "/") {
+				prefix += "/"
+			}
+			all = append(all, prefix)
+		}
+	}
+	sort.Strings(all)
+
+	var prefixes []string
+	for _, prefix := range all {
+		if len(prefixes) > 0 && strings.HasPrefix(prefix, prefixes[len(prefixes)-1]) {
+			continue
+		}
+		prefixes = append(prefixes, prefix)
+	}
+
+	return prefixes
+}
@@ -3,6 +3,9 @@ package storage
 import (
 	"context"
 	"fmt"
+	"io"
+	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/minio/minio-go/v7"
@@ -11,11 +14,18 @@ import (
 	"github.com/merdernoty/job-hunter/pkg/logger"
 )
 
+// URLResolver turns stored object keys into URLs that clients can fetch.
+type URLResolver interface {
+	ResolveURL(objectKey string) (string, error)
+}
+
 type MinIOClient struct {
-	client     *minio.Client
-	bucketName string
-	endpoint   string
-	logger     logger.Logger
+	client        *minio.Client
+	presignClient *minio.Client
+	bucketName    string
+	endpoint      string
+	signedURLTTL  time.Duration
+	logger        logger.Logger
 }
 
 func NewMinIOClient(cfg *config.Config, logger logger.Logger) (*MinIOClient, error) {
@@ -30,11 +40,36 @@ func NewMinIOClient(cfg *config.Config, logger logger.Logger) (*MinIOClient, err
 		return nil, fmt.Errorf("failed to create MinIO client: %w", err)
 	}
 
+	// Presigned URLs are signed for the host the client will call, so they are
+	// generated by a separate client pointed at the public endpoint (CDN or
+	// reverse proxy in front of MinIO).
+	presignClient := client
+	publicSecure := cfg.MiniO.UseSSL
+	if cfg.MiniO.PublicScheme != "" {
+		publicSecure = cfg.MiniO.PublicScheme == "https"
+	}
+	if cfg.MiniO.PublicEndpoint != "" || publicSecure != cfg.MiniO.UseSSL {
+		publicEndpoint := cfg.MiniO.PublicEndpoint
+		if publicEndpoint == "" {
+			publicEndpoint = cfg.MiniO.Endpoint
+		}
+		presignClient, err = minio.New(publicEndpoint, &minio.Options{
+			Creds:  credentials.NewStaticV4(cfg.MiniO.AccessKeyID, cfg.MiniO.SecretAccessKey, ""),
+			Secure: publicSecure,
+			Region: cfg.MiniO.Region,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create MinIO presign client: %w", err)
+		}
+	}
+
 	minioClient := &MinIOClient{
-		client:     client,
-		bucketName: cfg.MiniO.BucketName,
-		endpoint:   cfg.MiniO.Endpoint,
-		logger:     logger,
+		client:        client,
+		presignClient: presignClient,
+		bucketName:    cfg.MiniO.BucketName,
+		endpoint:      cfg.MiniO.Endpoint,
+		signedURLTTL:  cfg.MiniO.SignedURLTTL,
+		logger:        logger,
 	}
 
 	if err := minioClient.testConnection(); err != nil {
@@ -45,8 +80,8 @@ func NewMinIOClient(cfg *config.Config, logger logger.Logger) (*MinIOClient, err
 		return nil, fmt.Errorf("failed to ensure bucket exists: %w", err)
 	}
 
-	if err := minioClient.setBucketPolicy(); err != nil {
-		logger.Warnf("Failed to set bucket policy: %v", err)
+	if err := minioClient.ensurePrivateBucket(); err != nil {
+		logger.Warnf("Failed to remove public bucket policy: %v", err)
 	}
 
 	logger.Infof("MinIO client initialized successfully with bucket: %s", cfg.MiniO.BucketName)
@@ -89,27 +124,17 @@ func (m *MinIOClient) ensureBucketExists() error {
 	return nil
 }
 
-func (m *MinIOClient) setBucketPolicy() error {
+// ensurePrivateBucket drops any bucket policy so that objects are only
+// reachable through signed URLs.
+func (m *MinIOClient) ensurePrivateBucket() error {
 	ctx := context.Background()
-	
-	policy := fmt.Sprintf(`{
-		"Version": "2012-10-17",
-		"Statement": [
-			{
-				"Effect": "Allow",
-				"Principal": "*",
-				"Action": "s3:GetObject",
-				"Resource": "arn:aws:s3:::%s/avatars/*"
-			}
-		]
-	}`, m.bucketName)
-
-	err := m.client.SetBucketPolicy(ctx, m.bucketName, policy)
+
+	err := m.client.SetBucketPolicy(ctx, m.bucketName, "")
 	if err != nil {
-		return fmt.Errorf("failed to set bucket policy: %w", err)
+		return fmt.Errorf("failed to reset bucket policy: %w", err)
 	}
 
-	m.logger.Infof("Set public read policy for bucket: %s", m.bucketName)
+	m.logger.Infof("Bucket %s is private, objects are served via signed URLs", m.bucketName)
 	return nil
 }
 
@@ -123,4 +148,48 @@ func (m *MinIOClient) GetBucketName() string {
 
 func (m *MinIOClient) GetEndpoint() string {
 	return m.endpoint
-}
\ No newline at end of file
+}
+
+func (m *MinIOClient) PresignPut(objectName, contentType string, size int64, expiry time.Duration) (string, http.Header, error) {
+	headers := http.Header{}
+	headers.Set("Content-Type", contentType)
+	headers.Set("Content-Length", strconv.FormatInt(size, 10))
+
+	u, err := m.presignClient.PresignHeader(context.Background(), http.MethodPut, m.bucketName, objectName, expiry, nil, headers)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to presign upload: %w", err)
+	}
+
+	return u.String(), headers, nil
+}
+
+// ResolveURL returns a short-lived signed GET URL for an object key.
+func (m *MinIOClient) ResolveURL(objectKey string) (string, error) {
+	ttl := m.signedURLTTL
+	if ttl <= 0 {
+		ttl = time.Hour
+	}
+
+	u, err := m.presignClient.PresignedGetObject(context.Background(), m.bucketName, objectKey, ttl, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign object URL: %w", err)
+	}
+
+	return u.String(), nil
+}
+
+func (m *MinIOClient) StatObject(objectName string) (minio.ObjectInfo, error) {
+	return m.client.StatObject(context.Background(), m.bucketName, objectName, minio.StatObjectOptions{})
+}
+
+func (m *MinIOClient) GetObject(objectName string) (io.ReadCloser, error) {
+	return m.client.GetObject(context.Background(), m.bucketName, objectName, minio.GetObjectOptions{})
+}
+
+func (m *MinIOClient) RemoveObject(objectName string) error {
+	return m.client.RemoveObject(context.Background(), m.bucketName, objectName, minio.RemoveObjectOptions{})
+}
+
+func IsNotFound(err error) bool {
+	return minio.ToErrorResponse(err).Code == "NoSuchKey"
+}
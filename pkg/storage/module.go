@@ -1,7 +1,78 @@
 package storage
 
-import "go.uber.org/fx"
+import (
+	"context"
+	"time"
+
+	"github.com/merdernoty/job-hunter/config"
+	"github.com/merdernoty/job-hunter/pkg/logger"
+	"go.uber.org/fx"
+)
 
 var Module = fx.Options(
-	fx.Provide(NewMinIOClient),
+	fx.Provide(
+		fx.Annotate(
+			NewMinIOClient,
+			fx.As(fx.Self()),
+			fx.As(new(URLResolver)),
+		),
+	),
+	fx.Provide(
+		fx.Annotate(
+			NewGarbageCollector,
+			fx.ParamTags(``, ``, `group:"storage_references"`),
+		),
+	),
+	fx.Invoke(RunGarbageCollectorJob),
 )
+
+// RunGarbageCollectorJob periodically removes orphaned objects when enabled in config.
+func RunGarbageCollectorJob(lc fx.Lifecycle, gc *GarbageCollector, cfg *config.Config, log logger.Logger) {
+	if !cfg.StorageGC.Enabled {
+		log.Info("Storage garbage collector is disabled")
+		return
+	}
+
+	interval := cfg.StorageGC.Interval
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+	opts := GCOptions{
+		DryRun:      cfg.StorageGC.DryRun,
+		GracePeriod: cfg.StorageGC.GracePeriod,
+	}
+
+	var cancel context.CancelFunc
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			jobCtx, jobCancel := context.WithCancel(context.Background())
+			cancel = jobCancel
+
+			go func() {
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-jobCtx.Done():
+						return
+					case <-ticker.C:
+						if _, err := gc.Run(jobCtx, opts); err != nil {
+							log.Errorf("Storage garbage collection failed: %v", err)
+						}
+					}
+				}
+			}()
+
+			log.Infof("Storage garbage collector started (interval: %v, dry run: %t)", interval, opts.DryRun)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			if cancel != nil {
+				cancel()
+			}
+			return nil
+		},
+	})
+}
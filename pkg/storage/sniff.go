@@ -0,0 +1,291 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"path/filepath"
+	"strings"
+
+	"github.com/merdernoty/job-hunter/pkg/apperr"
+)
+
+var (
+	ErrEmptyFile           = apperr.Validation("EMPTY_FILE", "file is empty")
+	ErrFileTooLarge        = apperr.TooLarge("FILE_TOO_LARGE", "file too large")
+	ErrUnsupportedFileType = apperr.Unsupported("UNSUPPORTED_FILE_TYPE", "unsupported file type")
+	ErrContentTypeMismatch = apperr.Validation("CONTENT_TYPE_MISMATCH", "declared content type does not match file content")
+	ErrPolyglotFile        = apperr.Validation("POLYGLOT_FILE", "file contains embedded content of another type")
+)
+
+// FileSignature describes how a file type is recognised from its leading bytes.
+type FileSignature struct {
+	ContentType string
+	Extensions  []string
+	match       func(head []byte) bool
+	// end returns where the file's own data ends, -1 when unknown.
+	end func(data []byte) int
+}
+
+var signatures = []FileSignature{
+	{
+		ContentType: "image/jpeg",
+		Extensions:  []string{".jpg", ".jpeg"},
+		match: func(head []byte) bool {
+			return bytes.HasPrefix(head, []byte{0xFF, 0xD8, 0xFF})
+		},
+		end: jpegEnd,
+	},
+	{
+		ContentType: "image/png",
+		Extensions:  []string{".png"},
+		match: func(head []byte) bool {
+			return bytes.HasPrefix(head, []byte("\x89PNG\r\n\x1a\n"))
+		},
+		end: pngEnd,
+	},
+	{
+		ContentType: "image/gif",
+		Extensions:  []string{".gif"},
+		match: func(head []byte) bool {
+			return bytes.HasPrefix(head, []byte("GIF87a")) || bytes.HasPrefix(head, []byte("GIF89a"))
+		},
+		end: gifEnd,
+	},
+	{
+		ContentType: "image/webp",
+		Extensions:  []string{".webp"},
+		match: func(head []byte) bool {
+			return len(head) >= 12 && bytes.Equal(head[:4], []byte("RIFF")) && bytes.Equal(head[8:12], []byte("WEBP"))
+		},
+		end: webpEnd,
+	},
+	{
+		ContentType: "application/pdf",
+		Extensions:  []string{".pdf"},
+		match: func(head []byte) bool {
+			return bytes.HasPrefix(head, []byte("%PDF-"))
+		},
+		end: pdfEnd,
+	},
+}
+
+// Markers that must not lead or trail a binary upload: there they make the
+// file open as something else (HTML, script, archive).
+var polyglotMarkers = [][]byte{
+	[]byte("<script"),
+	[]byte("<html"),
+	[]byte("<!doctype html"),
+	[]byte("<svg"),
+	[]byte("<?php"),
+	[]byte("javascript:"),
+}
+
+var archiveMarkers = [][]byte{
+	[]byte("PK\x03\x04"),
+	[]byte("Rar!\x1a\x07"),
+}
+
+const sniffLen = 512
+
+// SniffedFile is an upload that has been fully read, size-checked and
+// identified by its content.
+type SniffedFile struct {
+	ContentType string
+	Extension   string
+	Size        int64
+	data        []byte
+}
+
+func (f *SniffedFile) Reader() io.Reader {
+	return bytes.NewReader(f.data)
+}
+
+// Checksum returns the hex-encoded SHA-256 of the file contents.
+func (f *SniffedFile) Checksum() string {
+	sum := sha256.Sum256(f.data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ReadAndSniff streams r into memory, failing as soon as more than maxSize
+// bytes have been read, then detects the real type from the leading bytes.
+// declaredType and fileName come from the client and are only used to reject
+// mismatches; they never decide the stored content type.
+func ReadAndSniff(r io.Reader, maxSize int64, declaredType, fileName string, allowedTypes []string) (*SniffedFile, error) {
+	var buf bytes.Buffer
+	n, err := io.Copy(&buf, io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if n == 0 {
+		return nil, ErrEmptyFile
+	}
+	if n > maxSize {
+		return nil, ErrFileTooLarge.WithDetails(fmt.Sprintf("maximum size is %d bytes", maxSize))
+	}
+
+	data := buf.Bytes()
+	sig, err := DetectSignature(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if !containsType(allowedTypes, sig.ContentType) {
+		return nil, ErrUnsupportedFileType.WithDetails("allowed types are " + strings.Join(allowedTypes, ", "))
+	}
+
+	if err := checkDeclared(sig, declaredType, fileName); err != nil {
+		return nil, err
+	}
+
+	if err := CheckPolyglot(sig, data); err != nil {
+		return nil, err
+	}
+
+	return &SniffedFile{
+		ContentType: sig.ContentType,
+		Extension:   sig.Extensions[0],
+		Size:        n,
+		data:        data,
+	}, nil
+}
+
+// DetectSignature identifies a file from its leading bytes.
+func DetectSignature(data []byte) (*FileSignature, error) {
+	head := data
+	if len(head) > sniffLen {
+		head = head[:sniffLen]
+	}
+
+	for i := range signatures {
+		if signatures[i].match(head) {
+			return &signatures[i], nil
+		}
+	}
+
+	return nil, ErrUnsupportedFileType
+}
+
+// CheckPolyglot rejects files that carry markup, scripts or archives in
+// addition to their detected type. Only the places other parsers look are
+// searched: the leading bytes, whatever follows the end of the file's own
+// data and, for ZIP readers, the end-of-central-directory record at the
+// tail. Compressed data in between matches short markers by chance.
+func CheckPolyglot(sig *FileSignature, data []byte) error {
+	head := data
+	if len(head) > sniffLen {
+		head = head[:sniffLen]
+	}
+
+	var trailer []byte
+	if end := sig.end(data); end >= 0 {
+		trailer = bytes.TrimSpace(data[end:])
+	} else if len(data) > sniffLen {
+		trailer = data[len(data)-sniffLen:]
+	}
+
+	for _, part := range [][]byte{head, trailer} {
+		if hasMarker(part) {
+			return ErrPolyglotFile
+		}
+	}
+
+	// A PDF appended to an image, e.g. a JPEG that also opens as a document.
+	if sig.ContentType != "application/pdf" && bytes.Contains(trailer, []byte("%PDF-")) {
+		return ErrPolyglotFile
+	}
+
+	if hasZIPDirectory(data) {
+		return ErrPolyglotFile
+	}
+
+	return nil
+}
+
+func hasMarker(data []byte) bool {
+	if len(data) == 0 {
+		return false
+	}
+
+	lower := bytes.ToLower(data)
+	for _, marker := range polyglotMarkers {
+		if bytes.Contains(lower, marker) {
+			return true
+		}
+	}
+	for _, marker := range archiveMarkers {
+		if bytes.Contains(data, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func checkDeclared(sig *FileSignature, declaredType, fileName string) error {
+	if declaredType != "" {
+		mediaType, _, err := mime.ParseMediaType(declaredType)
+		if err != nil {
+			return ErrContentTypeMismatch
+		}
+		mediaType = normalizeContentType(mediaType)
+		if mediaType != "application/octet-stream" && mediaType != sig.ContentType {
+			return ErrContentTypeMismatch
+		}
+	}
+
+	if ext := strings.ToLower(filepath.Ext(fileName)); ext != "" {
+		found := false
+		for _, allowed := range sig.Extensions {
+			if ext == allowed {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return ErrContentTypeMismatch
+		}
+	}
+
+	return nil
+}
+
+// MediaType returns the normalized media type of a Content-Type value,
+// without parameters such as charset.
+func MediaType(contentType string) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		contentType = mediaType
+	}
+	return normalizeContentType(contentType)
+}
+
+func normalizeContentType(contentType string) string {
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	if contentType == "image/jpg" {
+		return "image/jpeg"
+	}
+	return contentType
+}
+
+func containsType(types []string, contentType string) bool {
+	for _, t := range types {
+		if normalizeContentType(t) == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+// ExtensionForType returns the canonical file extension for a supported
+// content type, or an empty string when the type is unknown.
+func ExtensionForType(contentType string) string {
+	contentType = MediaType(contentType)
+	for _, sig := range signatures {
+		if sig.ContentType == contentType {
+			return sig.Extensions[0]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,169 @@
+package fakeapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ControlPrefix is where ControlHandler is expected to be mounted.
+const ControlPrefix = "/_fake/"
+
+type injectMessageRequest struct {
+	UserID int64  `json:"user_id"`
+	Text   string `json:"text"`
+}
+
+type injectCallbackRequest struct {
+	UserID    int64  `json:"user_id"`
+	MessageID int    `json:"message_id"`
+	Data      string `json:"data"`
+}
+
+type injectPaymentRequest struct {
+	UserID   int64  `json:"user_id"`
+	Payload  string `json:"payload"`
+	Currency string `json:"currency"`
+	Amount   int    `json:"amount"`
+	// ChargeID is the refunded charge, for refunds only.
+	ChargeID string `json:"charge_id,omitempty"`
+}
+
+type chatMemberRequest struct {
+	ChatID int64               `json:"chat_id"`
+	Member tgbotapi.ChatMember `json:"member"`
+}
+
+// ControlHandler exposes the server to humans and scripts during local
+// development:
+//
+//	POST /_fake/message   {"user_id": 42, "text": "/start"}
+//	POST /_fake/callback  {"user_id": 42, "message_id": 7, "data": "random:next"}
+//	POST /_fake/update    a raw Update object
+//	POST /_fake/pre_checkout {"user_id": 42, "payload": "extra_profiles_30d", "currency": "XTR", "amount": 100}
+//	POST /_fake/payment   the same body, completing the payment
+//	POST /_fake/refund    the same body with "charge_id", refunding the payment
+//	POST /_fake/chat      a raw Chat object, e.g. a channel to link
+//	POST /_fake/member    {"chat_id": -100, "member": {"user": {"id": 1}, "status": "administrator"}}
+//	GET  /_fake/calls     recorded calls, optionally ?method=sendMessage
+//	POST /_fake/reset
+func (s *Server) ControlHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST "+ControlPrefix+"message", func(w http.ResponseWriter, r *http.Request) {
+		var req injectMessageRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.UserID == 0 {
+			http.Error(w, "user_id and text are required", http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, map[string]int{"update_id": s.InjectMessage(req.UserID, req.Text)})
+	})
+
+	mux.HandleFunc("POST "+ControlPrefix+"callback", func(w http.ResponseWriter, r *http.Request) {
+		var req injectCallbackRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.UserID == 0 {
+			http.Error(w, "user_id, message_id and data are required", http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, map[string]int{"update_id": s.InjectCallback(req.UserID, req.MessageID, req.Data)})
+	})
+
+	mux.HandleFunc("POST "+ControlPrefix+"update", func(w http.ResponseWriter, r *http.Request) {
+		var raw json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+			http.Error(w, "invalid update", http.StatusBadRequest)
+			return
+		}
+		id, err := s.InjectRawUpdate(raw)
+		if err != nil {
+			http.Error(w, "invalid update", http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, map[string]int{"update_id": id})
+	})
+
+	mux.HandleFunc("POST "+ControlPrefix+"pre_checkout", func(w http.ResponseWriter, r *http.Request) {
+		req, ok := decodePayment(w, r)
+		if !ok {
+			return
+		}
+		writeJSON(w, map[string]int{"update_id": s.InjectPreCheckout(req.UserID, req.Payload, req.Currency, req.Amount)})
+	})
+
+	mux.HandleFunc("POST "+ControlPrefix+"payment", func(w http.ResponseWriter, r *http.Request) {
+		req, ok := decodePayment(w, r)
+		if !ok {
+			return
+		}
+		writeJSON(w, map[string]int{"update_id": s.InjectSuccessfulPayment(req.UserID, req.Payload, req.Currency, req.Amount)})
+	})
+
+	mux.HandleFunc("POST "+ControlPrefix+"refund", func(w http.ResponseWriter, r *http.Request) {
+		req, ok := decodePayment(w, r)
+		if !ok {
+			return
+		}
+		if req.ChargeID == "" {
+			http.Error(w, "charge_id is required", http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, map[string]int{"update_id": s.InjectRefundedPayment(req.UserID, req.Payload, req.Currency, req.Amount, req.ChargeID)})
+	})
+
+	mux.HandleFunc("POST "+ControlPrefix+"chat", func(w http.ResponseWriter, r *http.Request) {
+		var chat tgbotapi.Chat
+		if err := json.NewDecoder(r.Body).Decode(&chat); err != nil || chat.ID == 0 {
+			http.Error(w, "invalid chat", http.StatusBadRequest)
+			return
+		}
+		s.AddChat(chat)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("POST "+ControlPrefix+"member", func(w http.ResponseWriter, r *http.Request) {
+		var req chatMemberRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ChatID == 0 || req.Member.User == nil {
+			http.Error(w, "chat_id and member.user are required", http.StatusBadRequest)
+			return
+		}
+		s.SetChatMember(req.ChatID, req.Member)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("GET "+ControlPrefix+"calls", func(w http.ResponseWriter, r *http.Request) {
+		calls := s.Calls()
+		if method := r.URL.Query().Get("method"); method != "" {
+			calls = s.CallsTo(method)
+		}
+		if calls == nil {
+			calls = []Call{}
+		}
+		writeJSON(w, calls)
+	})
+
+	mux.HandleFunc("POST "+ControlPrefix+"reset", func(w http.ResponseWriter, r *http.Request) {
+		s.Reset()
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	return mux
+}
+
+// decodePayment reads an injectPaymentRequest, defaulting to Telegram Stars.
+func decodePayment(w http.ResponseWriter, r *http.Request) (injectPaymentRequest, bool) {
+	var req injectPaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.UserID == 0 || req.Payload == "" {
+		http.Error(w, "user_id and payload are required", http.StatusBadRequest)
+		return req, false
+	}
+	if req.Currency == "" {
+		req.Currency = "XTR"
+	}
+	return req, true
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,600 @@
+// Package fakeapi is an in-memory stand-in for the Telegram Bot API. It
+// answers the methods the bot uses, records every call and lets updates be
+// injected, so the bot can run without reaching api.telegram.org.
+package fakeapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxPollTimeout bounds how long getUpdates waits for injected updates.
+const maxPollTimeout = 30 * time.Second
+
+// Call is one Bot API request received by the server.
+type Call struct {
+	Method string            `json:"method"`
+	Params map[string]string `json:"params"`
+	At     time.Time         `json:"at"`
+}
+
+// Failure is an error response returned instead of the next successful one.
+type Failure struct {
+	Code        int
+	Description string
+	// RetryAfter, when set, is returned as parameters.retry_after.
+	RetryAfter int
+}
+
+// queuedUpdate is an update waiting for getUpdates, kept as JSON so that
+// fields tgbotapi does not model reach the bot too.
+type queuedUpdate struct {
+	id  int
+	raw json.RawMessage
+}
+
+type Server struct {
+	token string
+	me    tgbotapi.User
+
+	mu            sync.Mutex
+	calls         []Call
+	updates       []queuedUpdate
+	nextUpdateID  int
+	nextMessageID int
+	webhookURL    string
+	failures      map[string][]Failure
+	chats         map[int64]tgbotapi.Chat
+	members       map[int64]map[int64]tgbotapi.ChatMember
+	// injected is closed and replaced whenever an update is injected, waking
+	// up pending getUpdates calls.
+	injected chan struct{}
+}
+
+// NewServer returns a server that accepts requests for token only.
+func NewServer(token string) *Server {
+	return &Server{
+		token: token,
+		me: tgbotapi.User{
+			ID:                    1,
+			IsBot:                 true,
+			FirstName:             "Fake Bot",
+			UserName:              "fake_bot",
+			CanJoinGroups:         true,
+			SupportsInlineQueries: true,
+		},
+		nextUpdateID:  1,
+		nextMessageID: 1,
+		failures:      make(map[string][]Failure),
+		chats:         make(map[int64]tgbotapi.Chat),
+		members:       make(map[int64]map[int64]tgbotapi.ChatMember),
+		injected:      make(chan struct{}),
+	}
+}
+
+// Endpoint returns the tgbotapi endpoint format for a server listening on
+// baseURL, e.g. "http://127.0.0.1:8081".
+func Endpoint(baseURL string) string {
+	return strings.TrimSuffix(baseURL, "/") + "/bot%s/%s"
+}
+
+// Me is the bot account getMe reports.
+func (s *Server) Me() tgbotapi.User {
+	return s.me
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	botToken, method, ok := strings.Cut(path, "/")
+	if !ok || !strings.HasPrefix(botToken, "bot") {
+		writeError(w, Failure{Code: http.StatusNotFound, Description: "Not Found"})
+		return
+	}
+	if strings.TrimPrefix(botToken, "bot") != s.token {
+		writeError(w, Failure{Code: http.StatusUnauthorized, Description: "Unauthorized"})
+		return
+	}
+
+	params, err := readParams(r)
+	if err != nil {
+		writeError(w, Failure{Code: http.StatusBadRequest, Description: "Bad Request: " + err.Error()})
+		return
+	}
+
+	s.record(method, params)
+
+	if failure, ok := s.popFailure(method); ok {
+		writeError(w, failure)
+		return
+	}
+
+	result, failure := s.handle(r, method, params)
+	if failure != nil {
+		writeError(w, *failure)
+		return
+	}
+	writeResult(w, result)
+}
+
+func (s *Server) handle(r *http.Request, method string, params map[string]string) (interface{}, *Failure) {
+	switch method {
+	case "getMe":
+		return s.me, nil
+	case "getUpdates":
+		return s.pollUpdates(r, params), nil
+	case "sendMessage":
+		msg := s.newMessage(params)
+		msg.Text = params["text"]
+		return msg, nil
+	case "sendPhoto":
+		msg := s.newMessage(params)
+		msg.Caption = params["caption"]
+		msg.Photo = []tgbotapi.PhotoSize{{
+			FileID:       fmt.Sprintf("fake-photo-%d", msg.MessageID),
+			FileUniqueID: fmt.Sprintf("fake-photo-%d", msg.MessageID),
+			Width:        640,
+			Height:       640,
+		}}
+		return msg, nil
+	case "editMessageText", "editMessageCaption", "editMessageMedia", "editMessageReplyMarkup":
+		// Inline messages are edited without a message to return.
+		if params["inline_message_id"] != "" {
+			return true, nil
+		}
+		msg := s.editedMessage(params)
+		if method == "editMessageText" {
+			msg.Text = params["text"]
+		}
+		return msg, nil
+	case "setWebhook":
+		s.mu.Lock()
+		s.webhookURL = params["url"]
+		s.mu.Unlock()
+		return true, nil
+	case "deleteWebhook":
+		s.mu.Lock()
+		s.webhookURL = ""
+		s.mu.Unlock()
+		return true, nil
+	case "getChat":
+		chat, ok := s.chat(params["chat_id"])
+		if !ok {
+			return nil, &Failure{Code: http.StatusBadRequest, Description: "Bad Request: chat not found"}
+		}
+		return chat, nil
+	case "getChatMember":
+		chat, ok := s.chat(params["chat_id"])
+		if !ok {
+			return nil, &Failure{Code: http.StatusBadRequest, Description: "Bad Request: chat not found"}
+		}
+		userID, _ := strconv.ParseInt(params["user_id"], 10, 64)
+		return s.member(chat.ID, userID), nil
+	case "sendInvoice":
+		msg := s.newMessage(params)
+		msg.Invoice = &tgbotapi.Invoice{
+			Title:       params["title"],
+			Description: params["description"],
+			Currency:    params["currency"],
+			TotalAmount: invoiceTotal(params["prices"]),
+		}
+		return msg, nil
+	case "createInvoiceLink":
+		return fmt.Sprintf("https://t.me/$fake-invoice-%d", s.messageID()), nil
+	case "getWebhookInfo":
+		return tgbotapi.WebhookInfo{URL: s.WebhookURL()}, nil
+	case "answerCallbackQuery", "answerInlineQuery", "answerPreCheckoutQuery", "refundStarPayment",
+		"deleteMessage", "sendChatAction", "setMyCommands", "deleteMyCommands", "setChatMenuButton":
+		return true, nil
+	default:
+		return nil, &Failure{Code: http.StatusNotFound, Description: "Not Found: method not found"}
+	}
+}
+
+// InjectUpdate queues an update for getUpdates and returns its update_id.
+func (s *Server) InjectUpdate(update tgbotapi.Update) int {
+	raw, _ := json.Marshal(update)
+	id, _ := s.InjectRawUpdate(raw)
+	return id
+}
+
+// InjectRawUpdate queues an update given as a JSON object, for updates with
+// fields tgbotapi does not model. Its update_id is assigned by the server.
+func (s *Server) InjectRawUpdate(raw json.RawMessage) (int, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil || fields == nil {
+		return 0, fmt.Errorf("update must be a JSON object")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.nextUpdateID
+	s.nextUpdateID++
+	fields["update_id"] = json.RawMessage(strconv.Itoa(id))
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return 0, err
+	}
+	s.updates = append(s.updates, queuedUpdate{id: id, raw: data})
+
+	close(s.injected)
+	s.injected = make(chan struct{})
+
+	return id, nil
+}
+
+// InjectMessage queues a private text message from userID. Texts starting
+// with "/" are marked as commands, as Telegram does.
+func (s *Server) InjectMessage(userID int64, text string) int {
+	msg := &tgbotapi.Message{
+		MessageID: s.messageID(),
+		From:      &tgbotapi.User{ID: userID, FirstName: "User", UserName: fmt.Sprintf("user%d", userID)},
+		Date:      int(time.Now().Unix()),
+		Chat:      &tgbotapi.Chat{ID: userID, Type: "private"},
+		Text:      text,
+	}
+	if strings.HasPrefix(text, "/") {
+		command, _, _ := strings.Cut(text, " ")
+		msg.Entities = []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(command)}}
+	}
+
+	return s.InjectUpdate(tgbotapi.Update{Message: msg})
+}
+
+// InjectCallback queues a button press by userID on a message the bot sent
+// to the user's private chat.
+func (s *Server) InjectCallback(userID int64, messageID int, data string) int {
+	from := &tgbotapi.User{ID: userID, FirstName: "User", UserName: fmt.Sprintf("user%d", userID)}
+
+	return s.InjectUpdate(tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+		ID:   strconv.Itoa(s.messageID()),
+		From: from,
+		Message: &tgbotapi.Message{
+			MessageID: messageID,
+			From:      &s.me,
+			Date:      int(time.Now().Unix()),
+			Chat:      &tgbotapi.Chat{ID: userID, Type: "private"},
+		},
+		ChatInstance: strconv.FormatInt(userID, 10),
+		Data:         data,
+	}})
+}
+
+// InjectPreCheckout queues the pre-checkout query Telegram sends when userID
+// confirms paying an invoice with the given payload.
+func (s *Server) InjectPreCheckout(userID int64, payload, currency string, amount int) int {
+	return s.InjectUpdate(tgbotapi.Update{PreCheckoutQuery: &tgbotapi.PreCheckoutQuery{
+		ID:             strconv.Itoa(s.messageID()),
+		From:           &tgbotapi.User{ID: userID, FirstName: "User", UserName: fmt.Sprintf("user%d", userID)},
+		Currency:       currency,
+		TotalAmount:    amount,
+		InvoicePayload: payload,
+	}})
+}
+
+// InjectSuccessfulPayment queues the service message that follows an
+// approved pre-checkout query and returns its update_id.
+func (s *Server) InjectSuccessfulPayment(userID int64, payload, currency string, amount int) int {
+	id := s.messageID()
+
+	return s.InjectUpdate(tgbotapi.Update{Message: &tgbotapi.Message{
+		MessageID: id,
+		From:      &tgbotapi.User{ID: userID, FirstName: "User", UserName: fmt.Sprintf("user%d", userID)},
+		Date:      int(time.Now().Unix()),
+		Chat:      &tgbotapi.Chat{ID: userID, Type: "private"},
+		SuccessfulPayment: &tgbotapi.SuccessfulPayment{
+			Currency:                currency,
+			TotalAmount:             amount,
+			InvoicePayload:          payload,
+			TelegramPaymentChargeID: fmt.Sprintf("fake-charge-%d", id),
+		},
+	}})
+}
+
+// InjectRefundedPayment queues the service message Telegram sends to userID
+// when the charge with chargeID is refunded, e.g. by Telegram support.
+func (s *Server) InjectRefundedPayment(userID int64, payload, currency string, amount int, chargeID string) int {
+	raw, _ := json.Marshal(map[string]interface{}{
+		"message": map[string]interface{}{
+			"message_id": s.messageID(),
+			"from":       tgbotapi.User{ID: userID, FirstName: "User", UserName: fmt.Sprintf("user%d", userID)},
+			"date":       time.Now().Unix(),
+			"chat":       tgbotapi.Chat{ID: userID, Type: "private"},
+			"refunded_payment": map[string]interface{}{
+				"currency":                   currency,
+				"total_amount":               amount,
+				"invoice_payload":            payload,
+				"telegram_payment_charge_id": chargeID,
+			},
+		},
+	})
+
+	id, _ := s.InjectRawUpdate(raw)
+	return id
+}
+
+// AddChat makes a group or channel known to getChat and getChatMember, by ID
+// and by @username.
+func (s *Server) AddChat(chat tgbotapi.Chat) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chats[chat.ID] = chat
+}
+
+// SetChatMember sets the membership getChatMember reports for member.User in
+// chatID. Users without one have left the chat.
+func (s *Server) SetChatMember(chatID int64, member tgbotapi.ChatMember) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.members[chatID] == nil {
+		s.members[chatID] = make(map[int64]tgbotapi.ChatMember)
+	}
+	s.members[chatID][member.User.ID] = member
+}
+
+// FailNext makes the next call to method return failure instead of its
+// normal result. Failures queue up in the order they were added.
+func (s *Server) FailNext(method string, failure Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[method] = append(s.failures[method], failure)
+}
+
+// Calls returns every call received so far, oldest first.
+func (s *Server) Calls() []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Call(nil), s.calls...)
+}
+
+// CallsTo returns the calls made to one method, oldest first.
+func (s *Server) CallsTo(method string) []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var calls []Call
+	for _, call := range s.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Reset forgets recorded calls, pending updates, queued failures and the
+// webhook.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = nil
+	s.updates = nil
+	s.failures = make(map[string][]Failure)
+	s.webhookURL = ""
+}
+
+func (s *Server) WebhookURL() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.webhookURL
+}
+
+// chat looks up a chat by the chat_id parameter, a numeric ID or @username.
+func (s *Server) chat(ref string) (tgbotapi.Chat, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if username, ok := strings.CutPrefix(ref, "@"); ok {
+		for _, chat := range s.chats {
+			if strings.EqualFold(chat.UserName, username) {
+				return chat, true
+			}
+		}
+		return tgbotapi.Chat{}, false
+	}
+
+	id, err := strconv.ParseInt(ref, 10, 64)
+	if err != nil {
+		return tgbotapi.Chat{}, false
+	}
+	chat, ok := s.chats[id]
+	return chat, ok
+}
+
+func (s *Server) member(chatID, userID int64) tgbotapi.ChatMember {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if member, ok := s.members[chatID][userID]; ok {
+		return member
+	}
+	return tgbotapi.ChatMember{User: &tgbotapi.User{ID: userID}, Status: "left"}
+}
+
+func (s *Server) record(method string, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, Call{Method: method, Params: params, At: time.Now()})
+}
+
+func (s *Server) popFailure(method string) (Failure, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queued := s.failures[method]
+	if len(queued) == 0 {
+		return Failure{}, false
+	}
+	s.failures[method] = queued[1:]
+	return queued[0], true
+}
+
+// pollUpdates confirms updates below offset and long-polls for new ones like
+// the real getUpdates.
+func (s *Server) pollUpdates(r *http.Request, params map[string]string) []json.RawMessage {
+	offset, _ := strconv.Atoi(params["offset"])
+	timeout, _ := strconv.Atoi(params["timeout"])
+	wait := time.Duration(timeout) * time.Second
+	if wait > maxPollTimeout {
+		wait = maxPollTimeout
+	}
+	deadline := time.NewTimer(wait)
+	defer deadline.Stop()
+
+	for {
+		s.mu.Lock()
+		pending := s.updates[:0]
+		result := []json.RawMessage{}
+		for _, update := range s.updates {
+			if update.id >= offset {
+				pending = append(pending, update)
+				result = append(result, update.raw)
+			}
+		}
+		s.updates = pending
+		injected := s.injected
+		s.mu.Unlock()
+
+		if len(result) > 0 {
+			return result
+		}
+
+		select {
+		case <-injected:
+		case <-deadline.C:
+			return result
+		case <-r.Context().Done():
+			return result
+		}
+	}
+}
+
+func (s *Server) newMessage(params map[string]string) tgbotapi.Message {
+	chatID, _ := strconv.ParseInt(params["chat_id"], 10, 64)
+
+	return tgbotapi.Message{
+		MessageID: s.messageID(),
+		From:      &s.me,
+		Date:      int(time.Now().Unix()),
+		Chat:      s.messageChat(chatID),
+	}
+}
+
+func (s *Server) editedMessage(params map[string]string) tgbotapi.Message {
+	chatID, _ := strconv.ParseInt(params["chat_id"], 10, 64)
+	messageID, _ := strconv.Atoi(params["message_id"])
+
+	return tgbotapi.Message{
+		MessageID: messageID,
+		From:      &s.me,
+		Date:      int(time.Now().Unix()),
+		EditDate:  int(time.Now().Unix()),
+		Chat:      s.messageChat(chatID),
+	}
+}
+
+// invoiceTotal sums the JSON-encoded LabeledPrice list of an invoice.
+func invoiceTotal(prices string) int {
+	var labeled []tgbotapi.LabeledPrice
+	_ = json.Unmarshal([]byte(prices), &labeled)
+
+	total := 0
+	for _, price := range labeled {
+		total += price.Amount
+	}
+	return total
+}
+
+func (s *Server) messageID() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := s.nextMessageID
+	s.nextMessageID++
+	return id
+}
+
+// messageChat returns the chat a sent message belongs to. Chats that were
+// not added follow Telegram's ID ranges: users are positive, groups negative.
+func (s *Server) messageChat(chatID int64) *tgbotapi.Chat {
+	s.mu.Lock()
+	chat, ok := s.chats[chatID]
+	s.mu.Unlock()
+	if ok {
+		return &chat
+	}
+
+	chatType := "private"
+	if chatID < 0 {
+		chatType = "supergroup"
+	}
+	return &tgbotapi.Chat{ID: chatID, Type: chatType}
+}
+
+func readParams(r *http.Request) (map[string]string, error) {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			return nil, err
+		}
+	} else if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+
+	params := make(map[string]string, len(r.Form))
+	for key, values := range r.Form {
+		if len(values) > 0 {
+			params[key] = values[0]
+		}
+	}
+	if r.MultipartForm != nil {
+		names := make([]string, 0, len(r.MultipartForm.File))
+		for name := range r.MultipartForm.File {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			params[name] = "attach://" + r.MultipartForm.File[name][0].Filename
+		}
+	}
+	return params, nil
+}
+
+func writeResult(w http.ResponseWriter, result interface{}) {
+	raw, err := json.Marshal(result)
+	if err != nil {
+		writeError(w, Failure{Code: http.StatusInternalServerError, Description: err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(tgbotapi.APIResponse{Ok: true, Result: raw})
+}
+
+// writeError answers with failure, using its code as the HTTP status like
+// Telegram does.
+func writeError(w http.ResponseWriter, failure Failure) {
+	status := failure.Code
+	if status < 400 || status > 599 {
+		status = http.StatusBadRequest
+	}
+
+	response := tgbotapi.APIResponse{
+		Ok:          false,
+		ErrorCode:   failure.Code,
+		Description: failure.Description,
+	}
+	if failure.RetryAfter > 0 {
+		response.Parameters = &tgbotapi.ResponseParameters{RetryAfter: failure.RetryAfter}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,27 @@
+package fakeapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestResetForgetsWebhook(t *testing.T) {
+	s := NewServer("123:test")
+
+	form := url.Values{"url": {"https://example.com/hook"}}
+	req := httptest.NewRequest(http.MethodPost, "/bot123:test/setWebhook", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	s.ServeHTTP(httptest.NewRecorder(), req)
+
+	if s.WebhookURL() == "" {
+		t.Fatal("setWebhook was not applied")
+	}
+
+	s.Reset()
+	if url := s.WebhookURL(); url != "" {
+		t.Fatalf("webhook %q survived Reset", url)
+	}
+}